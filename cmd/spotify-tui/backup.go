@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/thomassbooth/spotify-tui/internal/backup"
+	"github.com/thomassbooth/spotify-tui/internal/client/auth"
+	"github.com/thomassbooth/spotify-tui/internal/client/spotify"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/repository"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+func runBackup(configPath string, args []string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := flags.String("o", "", "write the archive here instead of backup.dir")
+	token := flags.String("token", "", "back up the account logged in with this token file, logging in when it has none")
+	flags.Parse(args)
+
+	library, err := backupLibrary(cfg, *token)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := backup.Take(library, printProgress)
+	clearProgress()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	path := *output
+	if path == "" {
+		path = filepath.Join(cfg.Backup.Dir, backup.Filename(s))
+	}
+	if err := backup.Save(path, s); err != nil {
+		log.Fatalf("Failed to save the backup: %v", err)
+	}
+
+	playlists, tracks, liked, albums, shows, artists := s.Counts()
+	fmt.Printf("✓ Backed up %s to %s\n", s.User.Name, path)
+	fmt.Printf("  playlists    %d (%d tracks)\n", playlists, tracks)
+	fmt.Printf("  liked songs  %d\n", liked)
+	fmt.Printf("  albums       %d\n", albums)
+	fmt.Printf("  shows        %d\n", shows)
+	fmt.Printf("  artists      %d\n", artists)
+	for _, p := range s.Playlists {
+		if p.Unavailable {
+			fmt.Fprintf(os.Stderr, "Spotify would not list the tracks of %s; it is saved without them\n", p.Name)
+		}
+	}
+}
+
+func runRestore(configPath string, args []string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "print what would be added without changing anything")
+	only := flags.String("only", "", "restore only these sections: playlists, liked, albums, shows, artists")
+	restart := flags.Bool("restart", false, "ignore the progress of an earlier run that stopped")
+	token := flags.String("token", "", "restore into the account logged in with this token file, logging in when it has none")
+	flags.Parse(args)
+	// The archive may come before or after the flags.
+	path := flags.Arg(0)
+	if flags.NArg() > 0 {
+		flags.Parse(flags.Args()[1:])
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Usage: spotify-tui restore <file> [-dry-run] [-only sections] [-restart] [-token file]")
+		os.Exit(2)
+	}
+
+	sections := backup.Sections
+	if *only != "" {
+		if sections, err = backup.ParseSections(*only); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	s, err := backup.Load(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	journal, err := backup.OpenJournal(backup.JournalPath(path))
+	if err != nil {
+		log.Fatalf("Failed to read the progress of the last run: %v", err)
+	}
+	if *restart {
+		journal.Reset()
+	}
+
+	library, err := backupLibrary(cfg, *token)
+	if err != nil {
+		log.Fatal(err)
+	}
+	plan, err := backup.Diff(library, s, sections, journal, printProgress)
+	clearProgress()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printPlan(plan)
+	if plan.Empty() {
+		fmt.Println("\nNothing to restore.")
+		if !*dryRun {
+			journal.Remove()
+		}
+		return
+	}
+	if *dryRun {
+		return
+	}
+
+	fmt.Println()
+	err = backup.Apply(library, plan, journal, printProgress)
+	clearProgress()
+	if err != nil {
+		log.Fatalf("%v\nRun the same command again to carry on from here.", err)
+	}
+	if err := journal.Remove(); err != nil {
+		log.Printf("Failed to remove %s: %v", backup.JournalPath(path), err)
+	}
+	fmt.Println("Restored.")
+}
+
+// backupLibrary reads and writes the library directly with the saved
+// token, or with the one at tokenPath when given: a backup has no use for
+// the daemon.
+func backupLibrary(cfg *config.Config, tokenPath string) (*service.PlaylistService, error) {
+	var (
+		spotifyClient *spotify.Client
+		err           error
+	)
+	if tokenPath == "" {
+		spotifyClient, err = savedClient(cfg)
+	} else {
+		spotifyClient, err = accountClient(cfg, tokenPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	playlists := service.NewPlaylistService(spotifyClient, nil, cfg.Polling.RequestTimeout.Duration)
+	return &playlists, nil
+}
+
+// accountClient signs in to another account than the one spotify-tui uses,
+// with the token kept at tokenPath. When there is none yet it logs in in
+// the browser, which must be signed in to that account.
+func accountClient(cfg *config.Config, tokenPath string) (*spotify.Client, error) {
+	authClient := auth.NewClient(auth.Config{
+		ClientID:     cfg.Auth.ClientID,
+		ClientSecret: cfg.Auth.ClientSecret,
+		TokenRepo:    repository.NewTokenRepository(tokenPath),
+		ServerAddr:   cfg.Auth.CallbackAddr,
+		Timeout:      cfg.Auth.Timeout.Duration,
+	})
+	ctx := context.Background()
+	token, err := authClient.GetValidToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log in with %s: %w", tokenPath, err)
+	}
+	return spotify.NewClientFromSource(authClient.TokenSource(ctx, token)), nil
+}
+
+// printPlan lists what a restore adds: each playlist it touches and the
+// number of saved items per section.
+func printPlan(plan backup.Plan) {
+	fmt.Printf("Backup of %s taken %s, restoring into %s\n",
+		plan.From.Name, plan.Taken.Local().Format("2006-01-02 15:04"), plan.Into.Name)
+	if plan.Resumed > 0 {
+		fmt.Printf("Carrying on from the last run (%d steps already done)\n", plan.Resumed)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, section := range plan.Sections {
+		switch section {
+		case backup.Playlists:
+			fmt.Fprintln(w, "\nPlaylists")
+			complete := 0
+			for _, pp := range plan.Playlists {
+				switch pp.Action {
+				case backup.Keep:
+					complete++
+				case backup.Follow:
+					fmt.Fprintf(w, "  %s\t%s\tby %s\n", pp.Action, pp.Playlist.Name, pp.Playlist.Owner.Name)
+				default:
+					fmt.Fprintf(w, "  %s\t%s\t+%d\n", pp.Action, pp.Playlist.Name, len(pp.Tracks))
+				}
+			}
+			if complete > 0 {
+				fmt.Fprintf(w, "  already complete: %d\n", complete)
+			}
+		case backup.LikedSongs:
+			fmt.Fprintf(w, "Liked songs\t+%d\n", len(plan.LikedSongs))
+		case backup.Albums:
+			fmt.Fprintf(w, "Albums\t+%d\n", len(plan.Albums))
+		case backup.Shows:
+			fmt.Fprintf(w, "Shows\t+%d\n", len(plan.Shows))
+		case backup.Artists:
+			fmt.Fprintf(w, "Artists\t+%d\n", len(plan.Artists))
+		}
+	}
+	w.Flush()
+	if plan.Skipped > 0 {
+		fmt.Printf("Leaving out local files, which Spotify does not hold: %d\n", plan.Skipped)
+	}
+}
+
+// printProgress overwrites one line of stderr with the stage at hand.
+func printProgress(stage string, done, total int) {
+	if total > 0 {
+		fmt.Fprintf(os.Stderr, "\r\033[K%s %d/%d", stage, done, total)
+	} else {
+		fmt.Fprintf(os.Stderr, "\r\033[K%s…", stage)
+	}
+}
+
+func clearProgress() {
+	fmt.Fprint(os.Stderr, "\r\033[K")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/thomassbooth/spotify-tui/internal/art"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/repository"
+)
+
+func newCacheRepository(cfg config.CacheConfig) *repository.CacheRepository {
+	return repository.NewCacheRepository(cfg.Dir, int64(cfg.MaxSizeMB)*1024*1024)
+}
+
+func runCache(configPath string, args []string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	cmd := "info"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	library := newCacheRepository(cfg.Cache)
+	covers := art.NewStore(cfg.Art.CacheDir, cfg.Polling.RequestTimeout.Duration)
+
+	switch cmd {
+	case "info":
+		entries, size, err := library.Stats()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("library  %s  %d entries, %.1f MB (limit %d MB)\n", library.Dir(), entries, float64(size)/(1024*1024), cfg.Cache.MaxSizeMB)
+		fmt.Printf("art      %s\n", cfg.Art.CacheDir)
+
+	case "clear":
+		if err := library.Clear(); err != nil {
+			log.Fatal(err)
+		}
+		if err := covers.Clear(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("✓ Cache cleared")
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
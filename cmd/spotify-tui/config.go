@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/theme"
+)
+
+func runConfig(configPath string, args []string) {
+	cmd := "show"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "show":
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			log.Fatalf("Invalid config: %v", err)
+		}
+		// Keep secrets out of terminal scrollback.
+		for _, secret := range []*string{
+			&cfg.Auth.ClientSecret,
+			&cfg.Scrobble.LastFM.APISecret,
+			&cfg.Scrobble.LastFM.SessionKey,
+			&cfg.Scrobble.ListenBrainz.Token,
+		} {
+			if *secret != "" {
+				*secret = "********"
+			}
+		}
+		data, err := cfg.Encode()
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(data)
+
+	case "validate":
+		path := configPath
+		if len(args) > 1 {
+			path = args[1]
+		}
+		if _, err := os.Stat(path); err != nil {
+			log.Fatalf("Cannot read %s: %v", path, err)
+		}
+		cfg, err := config.Load(path)
+		if err == nil {
+			_, _, err = theme.Load(cfg.Theme)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ %s is valid\n", path)
+
+	case "path":
+		fmt.Println(configPath)
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func runThemes(configPath string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	registry, _, err := theme.Load(cfg.Theme)
+	if err != nil {
+		log.Fatalf("Invalid theme: %v", err)
+	}
+	for _, name := range registry.Names() {
+		marker := " "
+		if name == cfg.Theme.Name {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+}
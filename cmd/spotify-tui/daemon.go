@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/daemon"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+func runDaemon(configPath string, args []string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	cmd := "run"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "run":
+		if err := serveDaemon(cfg); err != nil {
+			log.Fatal(err)
+		}
+
+	case "status":
+		client, err := daemon.Dial(cfg.Daemon.Socket, cfg.Polling.RequestTimeout.Duration)
+		if err != nil {
+			fmt.Printf("No daemon on %s\n", cfg.Daemon.Socket)
+			os.Exit(1)
+		}
+		defer client.Close()
+		fmt.Printf("daemon   pid %d on %s\n", client.PID(), cfg.Daemon.Socket)
+		state, err := client.State(false)
+		switch {
+		case err != nil:
+			fmt.Printf("playback %v\n", err)
+		case state == nil || state.Track.ID == "":
+			fmt.Println("playback nothing playing")
+		default:
+			status := "paused"
+			if state.IsPlaying {
+				status = "playing"
+			}
+			artist := ""
+			if len(state.Track.Artists) > 0 {
+				artist = state.Track.Artists[0].Name + " - "
+			}
+			fmt.Printf("playback %s %s%s on %s\n", status, artist, state.Track.Name, state.Device.Name)
+		}
+
+	case "stop":
+		client, err := daemon.Dial(cfg.Daemon.Socket, cfg.Polling.RequestTimeout.Duration)
+		if err != nil {
+			log.Fatalf("No daemon on %s", cfg.Daemon.Socket)
+		}
+		defer client.Close()
+		if err := client.Shutdown(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("✓ Daemon stopped")
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// serveDaemon runs the daemon until it is stopped. Errors are returned
+// rather than fatal so the deferred shutdown still runs: hooks finish, the
+// history is written and the play in progress is queued for scrobbling.
+func serveDaemon(cfg *config.Config) error {
+	ln, err := daemon.Listen(cfg.Daemon.Socket)
+	if err != nil {
+		return err
+	}
+	playbackService, playlistService := connect(cfg)
+	store := service.NewPlaybackStore(playbackService, cfg.Polling.PlaybackInterval.Duration)
+	server := daemon.NewServer(playbackService, playlistService, store)
+	defer startMPRIS(cfg, store.Playback())()
+	runner := startHooks(cfg)
+	defer runner.Close()
+	runner.Watch(store.Playback().WatchPlayback())
+	defer recordHistory(historyStore(cfg), store.Playback(), log.Default())()
+	defer startScrobbler(cfg, store.Playback(), log.Default())()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("✓ Listening on %s\n", cfg.Daemon.Socket)
+	return server.Serve(ctx, ln)
+}
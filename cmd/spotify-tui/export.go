@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+func runExport(configPath string, args []string) {
+	if len(args) == 0 || args[0] != "playlist" {
+		fmt.Fprintln(os.Stderr, "Usage: spotify-tui export playlist <id|uri|name> [-format csv|json|m3u8|xspf] [-o file]")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	flags := flag.NewFlagSet("export playlist", flag.ExitOnError)
+	formatName := flags.String("format", "", "csv, json, m3u8 or xspf; by default taken from -o, else export.format")
+	output := flags.String("o", "", "write to this file instead of stdout")
+	flags.Parse(args[1:])
+	// The playlist may come before or after the flags.
+	ref := flags.Arg(0)
+	if flags.NArg() > 0 {
+		flags.Parse(flags.Args()[1:])
+	}
+	if ref == "" {
+		log.Fatal("Give the playlist to export by ID, URI, link or name")
+	}
+
+	var format playlistfile.Format
+	var ok bool
+	switch {
+	case *formatName != "":
+		if format, ok = playlistfile.ParseFormat(*formatName); !ok {
+			log.Fatalf("Unknown format %q: use csv, json, m3u8 or xspf", *formatName)
+		}
+	case *output != "":
+		if format, ok = playlistfile.FormatOf(*output); !ok {
+			format, _ = playlistfile.ParseFormat(cfg.Export.Format)
+		}
+	default:
+		format, _ = playlistfile.ParseFormat(cfg.Export.Format)
+	}
+
+	playlists, closeFn, err := libraryPlaylists(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeFn()
+
+	playlist, err := findPlaylist(playlists, ref)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := playlists.ExportPlaylist(playlist, format, w); err != nil {
+		if *output != "" {
+			os.Remove(*output)
+		}
+		log.Fatal(err)
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "Exported %s to %s\n", playlist.Name, *output)
+	}
+}
+
+// libraryPlaylists returns the library through the daemon when one is
+// running. Without one it uses the saved token rather than logging in, so
+// nothing but the output of the command is written to stdout.
+func libraryPlaylists(cfg *config.Config) (service.Playlists, func(), error) {
+	if client := attach(cfg); client != nil {
+		return client.Playlists(), func() { client.Close() }, nil
+	}
+	spotifyClient, err := savedClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	playlists := service.NewPlaylistService(spotifyClient, nil, cfg.Polling.RequestTimeout.Duration)
+	return &playlists, func() {}, nil
+}
+
+// findPlaylist looks ref up in the library by ID or name, then on Spotify
+// by ID. ref may also be a spotify:playlist: URI or an open.spotify.com
+// link.
+func findPlaylist(playlists service.Playlists, ref string) (entities.Playlist, error) {
+	id := playlistID(ref)
+	library, err := playlists.GetPlaylists()
+	if err != nil {
+		return entities.Playlist{}, err
+	}
+	for _, p := range library {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	for _, p := range library {
+		if strings.EqualFold(p.Name, ref) {
+			return p, nil
+		}
+	}
+	p, err := playlists.Playlist(id)
+	if err != nil {
+		return entities.Playlist{}, fmt.Errorf("no playlist %q in the library or on Spotify: %w", ref, err)
+	}
+	return p, nil
+}
+
+// playlistID takes the ID out of a playlist URI or link.
+func playlistID(ref string) string {
+	if id, ok := strings.CutPrefix(ref, "spotify:playlist:"); ok {
+		return id
+	}
+	if _, rest, ok := strings.Cut(ref, "open.spotify.com/playlist/"); ok {
+		id, _, _ := strings.Cut(rest, "?")
+		return strings.TrimSuffix(id, "/")
+	}
+	return ref
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/history"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+// historyStore returns the listening history, or nil when it is turned off.
+func historyStore(cfg *config.Config) *history.Store {
+	if !cfg.History.Enabled {
+		return nil
+	}
+	return history.NewStore(cfg.History.File)
+}
+
+// recordHistory records plays into store as playback changes, and
+// backfills what Spotify remembers from while nothing was recording. The
+// returned function writes the play in progress.
+func recordHistory(store *history.Store, playback *service.StoredPlayback, logger *log.Logger) func() {
+	if store == nil {
+		return func() {}
+	}
+	recorder := history.NewRecorder(store, logger)
+	recorder.Watch(playback.WatchPlayback())
+	go func() {
+		plays, err := playback.RecentlyPlayed()
+		if err == nil {
+			_, err = store.Import(plays)
+		}
+		if err != nil {
+			logger.Printf("history: backfill failed: %v", err)
+		}
+	}()
+	return recorder.Close
+}
+
+func runHistory(configPath string, args []string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	store := history.NewStore(cfg.History.File)
+
+	if len(args) > 0 && args[0] == "import" {
+		playbackService, _ := connect(cfg)
+		plays, err := playbackService.RecentlyPlayed()
+		if err != nil {
+			log.Fatalf("Failed to fetch recently played tracks: %v", err)
+		}
+		added, err := store.Import(plays)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✓ Imported %d of %d recently played tracks\n", added, len(plays))
+		return
+	}
+
+	if len(args) > 0 && args[0] == "list" {
+		args = args[1:]
+	}
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	limit := flags.Int("n", 20, "how many plays to show")
+	flags.Parse(args)
+	query := strings.Join(flags.Args(), " ")
+
+	entries, err := store.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	shown := 0
+	for i := len(entries) - 1; i >= 0 && shown < *limit; i-- {
+		e := entries[i]
+		if !e.Matches(query) {
+			continue
+		}
+		shown++
+		artists := make([]string, len(e.Track.Artists))
+		for j, a := range e.Track.Artists {
+			artists[j] = a.Name
+		}
+		note := ""
+		switch {
+		case e.Imported:
+			note = "  (from Spotify)"
+		case e.Skipped:
+			note = "  (skipped)"
+		}
+		fmt.Printf("%s  %5s  %s - %s%s\n",
+			e.StartedAt.Local().Format("2006-01-02 15:04"),
+			formatMs(e.ListenedMs), strings.Join(artists, ", "), e.Track.Name, note)
+	}
+	switch {
+	case shown > 0:
+	case query != "":
+		fmt.Fprintf(os.Stderr, "No plays match %q\n", query)
+	default:
+		fmt.Fprintf(os.Stderr, "No plays recorded in %s\n", store.Path())
+	}
+}
+
+func formatMs(ms int) string {
+	d := time.Duration(ms) * time.Millisecond
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
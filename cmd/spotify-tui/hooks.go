@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/hooks"
+	"github.com/thomassbooth/spotify-tui/internal/view"
+)
+
+// startHooks returns the hook runner, or nil when there are no hooks or
+// their log cannot be opened.
+func startHooks(cfg *config.Config) *hooks.Runner {
+	runner, err := hooks.NewRunner(cfg.Hooks)
+	if err != nil {
+		log.Printf("Hooks disabled: %v", err)
+	}
+	return runner
+}
+
+// playlistHook runs the playlist_selected hooks for playlists opened in the
+// TUI.
+type playlistHook struct {
+	runner *hooks.Runner
+}
+
+func (h playlistHook) OnMessage(_ view.MsgType, msg tea.Msg) tea.Cmd {
+	if m, ok := msg.(view.PlaylistSelectedMsg); ok {
+		h.runner.Fire(hooks.Payload{
+			Event: hooks.PlaylistSelected,
+			Playlist: &hooks.Playlist{
+				ID:         m.ID,
+				Name:       m.Name,
+				URI:        m.URI,
+				Owner:      m.Owner,
+				TrackCount: m.TrackCount,
+			},
+		})
+	}
+	return nil
+}
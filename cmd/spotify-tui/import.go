@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/importer"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+	"github.com/thomassbooth/spotify-tui/internal/theme"
+	"github.com/thomassbooth/spotify-tui/internal/view"
+)
+
+func runImport(configPath string, args []string) {
+	if len(args) == 0 || args[0] != "playlist" {
+		fmt.Fprintln(os.Stderr, "Usage: spotify-tui import playlist <file> [-to playlist] [-name name] [-yes] [-dry-run]")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	flags := flag.NewFlagSet("import playlist", flag.ExitOnError)
+	to := flags.String("to", "", "add to this playlist, by ID, URI, link or name, instead of creating one")
+	name := flags.String("name", "", "name of the playlist to create; by default the file's")
+	yes := flags.Bool("yes", false, fmt.Sprintf("add the best match of every track without reviewing, leaving out tracks whose best scores under %.0f%%", importer.Plausible*100))
+	dryRun := flags.Bool("dry-run", false, "print the matches without adding anything")
+	flags.Parse(args[1:])
+	// The file may come before or after the flags.
+	path := flags.Arg(0)
+	if flags.NArg() > 0 {
+		flags.Parse(flags.Args()[1:])
+	}
+	if path == "" {
+		log.Fatal("Give the playlist file to import")
+	}
+
+	playlists, closeFn, err := libraryPlaylists(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeFn()
+
+	var target *entities.Playlist
+	if *to != "" {
+		p, err := findPlaylist(playlists, *to)
+		if err != nil {
+			log.Fatal(err)
+		}
+		target = &p
+	}
+
+	if !*yes && !*dryRun {
+		registry, _, err := theme.Load(cfg.Theme)
+		if err != nil {
+			log.Fatalf("Invalid theme: %v", err)
+		}
+		result, err := view.RunImport(cfg, registry, playlists, path, target, *name)
+		if result != "" {
+			fmt.Println(result)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	file, err := playlistfile.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(file.Entries) == 0 {
+		log.Fatalf("%s has no tracks", path)
+	}
+	matches, err := importer.Find(context.Background(), playlists, file.Entries, func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\rMatching %d of %d…", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *dryRun {
+		printMatches(matches)
+		return
+	}
+
+	if *name == "" {
+		*name = file.Name
+	}
+	p, added, err := importer.Into(playlists, target, *name, matches, func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\rAdding %d of %d…", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		log.Fatalf("Added %d tracks to %s before failing: %v", added, p.Name, err)
+	}
+	fmt.Printf("Added %d of %d tracks to %s\n", added, len(matches), p.Name)
+	if out := len(matches) - added; out > 0 {
+		fmt.Printf("Left out %d with no match of %.0f%% or more; -dry-run lists them\n", out, importer.Plausible*100)
+	}
+}
+
+// printMatches lists every entry with the track it would become, and how
+// sure the match is.
+func printMatches(matches []importer.Match) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCORE\tENTRY\tMATCH")
+	var sure, review, out int
+	for _, m := range matches {
+		c, ok := m.Chosen()
+		switch {
+		case !ok:
+			out++
+			fmt.Fprintf(w, "-\t%s\t(left out)\n", importer.Describe(m.Entry))
+			continue
+		case m.NeedsReview():
+			review++
+		default:
+			sure++
+		}
+		artists := make([]string, len(c.Track.Artists))
+		for i, a := range c.Track.Artists {
+			artists[i] = a.Name
+		}
+		track := playlistfile.Entry{Title: c.Track.Name, Artists: artists}
+		fmt.Fprintf(w, "%.0f%%\t%s\t%s\n", c.Score*100, importer.Describe(m.Entry), importer.Describe(track))
+	}
+	w.Flush()
+	fmt.Printf("\n%d matched, %d uncertain, %d left out\n", sure, review, out)
+}
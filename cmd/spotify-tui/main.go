@@ -2,18 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/joho/godotenv"
 	"github.com/thomassbooth/spotify-tui/internal/client/auth"
 	"github.com/thomassbooth/spotify-tui/internal/client/spotify"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/daemon"
+	"github.com/thomassbooth/spotify-tui/internal/hooks"
 	"github.com/thomassbooth/spotify-tui/internal/repository"
 	"github.com/thomassbooth/spotify-tui/internal/service"
+	"github.com/thomassbooth/spotify-tui/internal/theme"
 	"github.com/thomassbooth/spotify-tui/internal/view"
 )
 
@@ -22,20 +27,181 @@ func main() {
 		log.Println("")
 	}
 
-	homeDir, err := os.UserHomeDir()
+	configPath := flag.String("config", config.DefaultPath(), "path to the config file")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) > 0 {
+		switch args[0] {
+		case "config":
+			runConfig(*configPath, args[1:])
+			return
+		case "cache":
+			runCache(*configPath, args[1:])
+			return
+		case "themes":
+			runThemes(*configPath)
+			return
+		case "daemon":
+			runDaemon(*configPath, args[1:])
+			return
+		case "status":
+			runStatus(*configPath, args[1:])
+			return
+		case "history":
+			runHistory(*configPath, args[1:])
+			return
+		case "stats":
+			runStats(*configPath, args[1:])
+			return
+		case "scrobble":
+			runScrobble(*configPath, args[1:])
+			return
+		case "export":
+			runExport(*configPath, args[1:])
+			return
+		case "import":
+			runImport(*configPath, args[1:])
+			return
+		case "backup":
+			runBackup(*configPath, args[1:])
+			return
+		case "restore":
+			runRestore(*configPath, args[1:])
+			return
+		default:
+			usage()
+			os.Exit(2)
+		}
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	registry, _, err := theme.Load(cfg.Theme)
 	if err != nil {
-		log.Fatalf("Failed to get home directory: %v", err)
+		log.Fatalf("Invalid theme: %v", err)
+	}
+
+	runTUI(cfg, registry)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: spotify-tui [-config path] [command]
+
+Commands:
+  config [show]             print the effective configuration
+  config validate [file]    check a config file for errors
+  config path               print the config file location
+  themes                    list available themes
+  cache info                show cache locations and sizes
+  cache clear               delete cached library data and cover art
+  daemon [run]              serve playback and the library to other instances
+  daemon status             show whether a daemon is running and what it plays
+  daemon stop               ask the running daemon to exit
+  status [-follow] [-format template] [-width n]
+                            print the current track as one line for status bars
+  history [-n count] [query]
+                            list recent plays, newest first
+  history import            add Spotify's recently played tracks to the history
+  stats [-days n] [-range short|medium|long|all] [-format text|json|csv] [-o file]
+                            sum up listening from the history and Spotify's top items
+  scrobble [status]         show scrobbles waiting to be sent and the last error
+  scrobble auth             log in to Last.fm and print the session key
+  export playlist <id|uri|name> [-format csv|json|m3u8|xspf] [-o file]
+                            write every track of a playlist to a file or stdout
+  import playlist <file> [-to playlist] [-name name] [-yes] [-dry-run]
+                            match a CSV, JSON, M3U or XSPF file against Spotify
+                            and add the tracks to a new or existing playlist
+  backup [-o file] [-token file]
+                            save playlists, liked songs, albums, shows and
+                            followed artists to an archive in backup.dir
+  restore <file> [-dry-run] [-only sections] [-restart] [-token file]
+                            add what the account lacks from a backup, carrying
+                            on where an interrupted run stopped
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func runTUI(cfg *config.Config, themes *theme.Registry) {
+	var (
+		playback  service.Playback
+		playlists service.Playlists
+	)
+	// Playback hooks run wherever playback is followed: here, or in the
+	// daemon when attached to one.
+	runner := startHooks(cfg)
+	defer runner.Close()
+	listens := historyStore(cfg)
+	if client := attach(cfg); client != nil {
+		defer client.Close()
+		fmt.Printf("✓ Attached to the daemon (pid %d)\n", client.PID())
+		playback, playlists = client.Playback(), client.Playlists()
+	} else {
+		playbackService, playlistService := connect(cfg)
+		store := service.NewPlaybackStore(playbackService, cfg.Polling.PlaybackInterval.Duration)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go store.Run(ctx)
+		defer startMPRIS(cfg, store.Playback())()
+		runner.Watch(store.Playback().WatchPlayback())
+		// The TUI owns the terminal, so history and scrobble failures go
+		// unreported; `spotify-tui history` and `spotify-tui scrobble` show
+		// how they went.
+		quiet := log.New(io.Discard, "", 0)
+		defer recordHistory(listens, store.Playback(), quiet)()
+		defer startScrobbler(cfg, store.Playback(), quiet)()
+		playback, playlists = store.Playback(), playlistService
 	}
 
-	tokenPath := filepath.Join(homeDir, ".spotify-tui", "token.json")
-	tokenRepo := repository.NewTokenRepository(tokenPath)
+	var opts []tea.ProgramOption
+	if cfg.Layout.Mouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+	page := view.NewPage(cfg, themes, playlists, playback, listens)
+	if runner.Handles(hooks.PlaylistSelected) {
+		page.Subscribe(view.MsgPlaylistSelected, playlistHook{runner})
+	}
+	p := tea.NewProgram(page, opts...)
+
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+}
+
+// attach connects to a running daemon when the config allows it. It returns
+// nil when there is none, so the caller talks to Spotify itself.
+func attach(cfg *config.Config) *daemon.Client {
+	if !cfg.Daemon.Attach {
+		return nil
+	}
+	client, err := daemon.Dial(cfg.Daemon.Socket, cfg.Polling.RequestTimeout.Duration)
+	if err != nil {
+		var rpcErr *daemon.Error
+		if errors.As(err, &rpcErr) {
+			log.Printf("Not attaching to the daemon: %v", err)
+		}
+		return nil
+	}
+	return client
+}
+
+// connect authenticates with Spotify and builds the services on top of the
+// library cache.
+func connect(cfg *config.Config) (*service.PlaybackService, *service.PlaylistService) {
+	tokenRepo := repository.NewTokenRepository(cfg.Auth.TokenPath)
 
 	authClient := auth.NewClient(auth.Config{
-		ClientID:     os.Getenv("SPOTIFY_CLIENT_ID"),
-		ClientSecret: os.Getenv("SPOTIFY_CLIENT_SECRET"),
+		ClientID:     cfg.Auth.ClientID,
+		ClientSecret: cfg.Auth.ClientSecret,
 		TokenRepo:    tokenRepo,
-		ServerAddr:   "localhost:8889",
-		Timeout:      2 * time.Minute,
+		ServerAddr:   cfg.Auth.CallbackAddr,
+		Timeout:      cfg.Auth.Timeout.Duration,
 	})
 
 	ctx := context.Background()
@@ -46,12 +212,13 @@ func main() {
 
 	fmt.Println("✓ Successfully authenticated!")
 
-	spotifyClient := spotify.NewClient(token)
-	playlistService := service.NewPlaylistService(spotifyClient)
-	playbackService := service.NewPlaybackService(spotifyClient)
-	p := tea.NewProgram(view.NewPage(&playlistService, &playbackService))
-
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v\n", err)
+	var library *repository.LibraryRepository
+	if cfg.Cache.Enabled {
+		library = repository.NewLibraryRepository(newCacheRepository(cfg.Cache))
 	}
+
+	spotifyClient := spotify.NewClientFromSource(authClient.TokenSource(ctx, token))
+	playlistService := service.NewPlaylistService(spotifyClient, library, cfg.Polling.RequestTimeout.Duration)
+	playbackService := service.NewPlaybackService(spotifyClient, cfg.Polling.RequestTimeout.Duration)
+	return &playbackService, &playlistService
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"log"
+	"runtime"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/mpris"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+// startMPRIS registers the MPRIS player when enabled and returns a function
+// that removes it. Without a session bus, e.g. over SSH, it logs why and
+// carries on.
+func startMPRIS(cfg *config.Config, playback *service.StoredPlayback) func() {
+	if !cfg.MPRIS.Enabled || runtime.GOOS != "linux" {
+		return func() {}
+	}
+	player, err := mpris.Register(playback)
+	if err != nil {
+		log.Printf("MPRIS disabled: %v", err)
+		return func() {}
+	}
+	player.Follow(playback.WatchPlayback())
+	return func() { player.Close() }
+}
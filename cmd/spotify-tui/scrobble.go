@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/scrobble"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+// scrobbleBackends returns the services scrobbling is turned on for.
+func scrobbleBackends(cfg *config.Config) []scrobble.Backend {
+	var backends []scrobble.Backend
+	if c := cfg.Scrobble.LastFM; c.Enabled {
+		backends = append(backends, scrobble.NewLastFM(c.APIURL, c.APIKey, c.APISecret, c.SessionKey))
+	}
+	if c := cfg.Scrobble.ListenBrainz; c.Enabled {
+		backends = append(backends, scrobble.NewListenBrainz(c.APIURL, c.Token))
+	}
+	return backends
+}
+
+// startScrobbler scrobbles plays as playback changes. The returned function
+// scrobbles the play in progress and stops.
+func startScrobbler(cfg *config.Config, playback *service.StoredPlayback, logger *log.Logger) func() {
+	backends := scrobbleBackends(cfg)
+	if len(backends) == 0 {
+		return func() {}
+	}
+	scrobbler, err := scrobble.New(backends, cfg.Scrobble.QueueDir, cfg.Polling.RequestTimeout.Duration, logger)
+	if err != nil {
+		logger.Printf("scrobble: %v", err)
+		return func() {}
+	}
+	scrobbler.Watch(playback.WatchPlayback())
+	return scrobbler.Close
+}
+
+func runScrobble(configPath string, args []string) {
+	cmd := "status"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	switch cmd {
+	case "auth":
+		lastfmAuth(cfg.Scrobble.LastFM)
+	case "status":
+		backends := scrobbleBackends(cfg)
+		if len(backends) == 0 {
+			fmt.Println("Scrobbling is off: enable [scrobble.lastfm] or [scrobble.listenbrainz]")
+			return
+		}
+		queues, err := scrobble.Pending(backends, cfg.Scrobble.QueueDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, q := range queues {
+			fmt.Printf("%-13s %d waiting\n", q.Backend, q.Pending)
+			if q.LastError != "" {
+				fmt.Printf("%-13s last error %s: %s\n", "", q.LastErrorAt.Local().Format("2006-01-02 15:04"), q.LastError)
+			}
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// lastfmAuth walks through Last.fm's desktop login and prints the session
+// key to put in the config.
+func lastfmAuth(cfg config.LastFMConfig) {
+	if cfg.APIKey == "" || cfg.APISecret == "" {
+		log.Fatal("Set scrobble.lastfm.api_key and api_secret first; create an API account at https://www.last.fm/api/account/create")
+	}
+	lastfm := scrobble.NewLastFM(cfg.APIURL, cfg.APIKey, cfg.APISecret, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	token, err := lastfm.Token(ctx)
+	if err != nil {
+		log.Fatalf("Failed to start Last.fm login: %v", err)
+	}
+	fmt.Printf("Allow access at:\n\n  %s\n\nthen press Enter.", lastfm.AuthURL(token))
+	bufio.NewReader(os.Stdin).ReadString('\n')
+
+	key, user, err := lastfm.Session(ctx, token)
+	if err != nil {
+		log.Fatalf("Failed to get a Last.fm session: %v", err)
+	}
+	fmt.Printf("✓ Logged in to Last.fm as %s. Add this to [scrobble.lastfm]:\n\n  enabled = true\n  session_key = %q\n", user, key)
+}
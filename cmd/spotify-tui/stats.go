@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/history"
+	"github.com/thomassbooth/spotify-tui/internal/repository"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+	"github.com/thomassbooth/spotify-tui/internal/stats"
+)
+
+// statsTop is how many entries each ranking shows in the text report.
+const statsTop = 10
+
+func runStats(configPath string, args []string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	days := flags.Int("days", 0, "sum up the last n days of history; by default as long as -range, or all of it for -range all")
+	rangeName := flags.String("range", "short", "Spotify's top items over short, medium or long term, or all for every range")
+	format := flags.String("format", "", "text, json or csv; by default taken from -o, else text")
+	output := flags.String("o", "", "write to this file instead of stdout")
+	offline := flags.Bool("offline", false, "only use the local history, without asking Spotify")
+	flags.Parse(args)
+
+	ranges := stats.Ranges
+	if *rangeName != "all" {
+		rng, ok := stats.ParseRange(*rangeName)
+		if !ok {
+			log.Fatalf("Unknown range %q: use short, medium, long or all", *rangeName)
+		}
+		ranges = []stats.Range{rng}
+		if *days == 0 {
+			*days = rng.Days()
+		}
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*output), ".")
+	}
+	if *format != "json" && *format != "csv" {
+		*format = "text"
+	}
+
+	entries, err := history.NewStore(cfg.History.File).Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	report := stats.Compute(entries, *days, time.Now())
+	if !*offline {
+		if err := statsSpotify(cfg, report, ranges); err != nil {
+			fmt.Fprintf(os.Stderr, "Showing the local history only: %v\n", err)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch *format {
+	case "json":
+		err = report.WriteJSON(w)
+	case "csv":
+		err = report.WriteCSV(w)
+	default:
+		printStats(w, report, ranges)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// statsSpotify adds genres and Spotify's top items, through the daemon when
+// one is running. Tokens saved before stats existed lack user-top-read.
+func statsSpotify(cfg *config.Config, report *stats.Report, ranges []stats.Range) error {
+	var src stats.Source
+	if client := attach(cfg); client != nil {
+		defer client.Close()
+		src = client.Playlists()
+	} else {
+		spotifyClient, err := savedClient(cfg)
+		if err != nil {
+			return err
+		}
+		var library *repository.LibraryRepository
+		if cfg.Cache.Enabled {
+			library = repository.NewLibraryRepository(newCacheRepository(cfg.Cache))
+		}
+		playlists := service.NewPlaylistService(spotifyClient, library, cfg.Polling.RequestTimeout.Duration)
+		src = &playlists
+	}
+	return report.FetchSpotify(src, ranges...)
+}
+
+func printStats(w io.Writer, r *stats.Report, ranges []stats.Range) {
+	since := "all time"
+	if !r.From.IsZero() {
+		since = "since " + r.From.Format("Mon 2 Jan 2006")
+	}
+	fmt.Fprintf(w, "Listening %s: %s over %d plays, %.0f%% skipped\n\n",
+		since, stats.Duration(r.ListenedMs), r.Plays, r.SkipRate*100)
+
+	if len(r.Days) > 0 {
+		fmt.Fprintln(w, "Per day")
+		for _, line := range stats.Columns(r.Days, 90, 6, func(t time.Time) string { return t.Format("2 Jan") }) {
+			fmt.Fprintln(w, "  "+line)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Per week")
+		for _, wk := range r.Weeks[max(len(r.Weeks)-8, 0):] {
+			fmt.Fprintf(w, "  %-10s %8s  %d plays\n", wk.Start.Format("2 Jan"), stats.Duration(wk.ListenedMs), wk.Plays)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "By hour")
+		for _, line := range stats.Heatmap(r.Hours) {
+			fmt.Fprintln(w, "  "+line)
+		}
+		fmt.Fprintln(w)
+	}
+
+	printCounts(w, "Top artists", r.TopArtists)
+	printCounts(w, "Top albums", r.TopAlbums)
+	printCounts(w, "Top genres", r.TopGenres)
+
+	for _, rng := range ranges {
+		top := r.Spotify[rng]
+		if top == nil {
+			continue
+		}
+		fmt.Fprintf(w, "Spotify's top artists, last %s\n", rng.Label())
+		for _, a := range top.Artists[:min(len(top.Artists), statsTop)] {
+			fmt.Fprintf(w, "  %2d. %s\n", a.Rank, a.Name)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Spotify's top tracks, last %s\n", rng.Label())
+		for _, t := range top.Tracks[:min(len(top.Tracks), statsTop)] {
+			fmt.Fprintf(w, "  %2d. %s - %s\n", t.Rank, t.By, t.Name)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func printCounts(w io.Writer, title string, counts []stats.Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintln(w, title)
+	for i, c := range counts[:min(len(counts), statsTop)] {
+		name := c.Name
+		if c.By != "" {
+			name += " (" + c.By + ")"
+		}
+		fmt.Fprintf(w, "  %2d. %-50s %8s  %d plays\n", i+1, name, stats.Duration(c.ListenedMs), c.Plays)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/client/auth"
+	"github.com/thomassbooth/spotify-tui/internal/client/spotify"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/daemon"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/repository"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+	"github.com/thomassbooth/spotify-tui/internal/status"
+)
+
+// The status cache entry, shared by every bar invocation within cache_ttl.
+const (
+	statusCacheKind = "status"
+	statusCacheID   = "playback"
+)
+
+// cachedPlayback is stored in the status cache. State is nil when nothing
+// was playing.
+type cachedPlayback struct {
+	State *entities.PlaybackState `json:"state"`
+}
+
+func runStatus(configPath string, args []string) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	flags := flag.NewFlagSet("status", flag.ExitOnError)
+	format := flags.String("format", cfg.Status.Format, "template for the line")
+	follow := flags.Bool("follow", false, "keep running, printing a new line whenever it changes")
+	width := flags.Int("width", cfg.Status.MaxWidth, "cut lines to this many cells, 0 for no limit")
+	flags.Parse(args)
+
+	formatter, err := status.New(*format, cfg.Status.Idle, *width)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *follow {
+		err = followStatus(cfg, formatter)
+	} else {
+		err = printStatus(cfg, formatter)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func printStatus(cfg *config.Config, formatter *status.Formatter) error {
+	state, fetched, err := statusState(cfg)
+	if err != nil {
+		return err
+	}
+	line, err := formatter.Line(state, fetched, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Println(line)
+	return nil
+}
+
+// statusState returns the state the daemon last polled when one is
+// running. Otherwise it asks Spotify, reusing what an earlier invocation
+// fetched within cache_ttl so a bar refreshing every second stays well
+// inside the rate limit.
+func statusState(cfg *config.Config) (*entities.PlaybackState, time.Time, error) {
+	if client := attach(cfg); client != nil {
+		defer client.Close()
+		state, err := client.State(false)
+		return state, time.Now(), err
+	}
+
+	var cache *repository.CacheRepository
+	if cfg.Cache.Enabled && cfg.Status.CacheTTL.Duration > 0 {
+		cache = newCacheRepository(cfg.Cache)
+		var cached cachedPlayback
+		entry, ok, _ := cache.Get(statusCacheKind, statusCacheID, &cached)
+		if ok && time.Since(entry.StoredAt) < cfg.Status.CacheTTL.Duration {
+			return cached.State, entry.StoredAt, nil
+		}
+	}
+
+	playback, err := statusPlayback(cfg)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	state, err := playback.GetCurrentPlaybackState()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if cache != nil {
+		// Another invocation may be writing the same entry; either copy
+		// will do. Status bars run this every second or two, so the cache
+		// is not scanned for eviction each time.
+		_ = cache.Overwrite(statusCacheKind, statusCacheID, "", cachedPlayback{State: state})
+	}
+	return state, time.Now(), nil
+}
+
+// followStatus prints a line, then another whenever the line changes: on
+// playback changes, and every second as the position moves on or text
+// scrolls. Changes come from the daemon when one is running, otherwise
+// from polling Spotify.
+func followStatus(cfg *config.Config, formatter *status.Formatter) error {
+	var (
+		state   *entities.PlaybackState
+		updates <-chan service.PlaybackUpdate
+	)
+	if client := attach(cfg); client != nil {
+		defer client.Close()
+		state, _ = client.State(false)
+		updates = client.WatchPlayback()
+	} else {
+		playback, err := statusPlayback(cfg)
+		if err != nil {
+			return err
+		}
+		// Fetched up front so the first line is not idle until the first poll.
+		state, _ = playback.GetCurrentPlaybackState()
+		store := service.NewPlaybackStore(playback, cfg.Polling.PlaybackInterval.Duration)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		updates, _ = store.Subscribe()
+		go store.Run(ctx)
+	}
+	fetched := time.Now()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	last, printed := "", false
+	for {
+		line, err := formatter.Line(state, fetched, time.Now())
+		if err != nil {
+			return err
+		}
+		if line != last || !printed {
+			fmt.Println(line)
+			last, printed = line, true
+		}
+
+		select {
+		case u, ok := <-updates:
+			if !ok {
+				return daemon.ErrClosed
+			}
+			// A failed fetch shows as idle rather than a stale track.
+			state, fetched = u.State, time.Now()
+		case <-ticker.C:
+		}
+	}
+}
+
+// statusPlayback builds a playback service from the saved token. Unlike
+// connect it never starts the browser login, which a status bar has no way
+// to complete.
+func statusPlayback(cfg *config.Config) (*service.PlaybackService, error) {
+	spotifyClient, err := savedClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	playback := service.NewPlaybackService(spotifyClient, cfg.Polling.RequestTimeout.Duration)
+	return &playback, nil
+}
+
+// savedClient builds a Spotify client from the saved token, failing rather
+// than logging in when there is none.
+func savedClient(cfg *config.Config) (*spotify.Client, error) {
+	tokenRepo := repository.NewTokenRepository(cfg.Auth.TokenPath)
+	token, err := tokenRepo.Load()
+	if err != nil {
+		return nil, fmt.Errorf("not logged in, run spotify-tui to log in first: %w", err)
+	}
+	if missing := auth.MissingScopes(token); len(missing) > 0 {
+		return nil, fmt.Errorf("the saved login lacks %s, run spotify-tui to log in again", strings.Join(missing, ", "))
+	}
+
+	authClient := auth.NewClient(auth.Config{
+		ClientID:     cfg.Auth.ClientID,
+		ClientSecret: cfg.Auth.ClientSecret,
+		TokenRepo:    tokenRepo,
+		ServerAddr:   cfg.Auth.CallbackAddr,
+		Timeout:      cfg.Auth.Timeout.Duration,
+	})
+	return spotify.NewClientFromSource(authClient.TokenSource(context.Background(), token)), nil
+}
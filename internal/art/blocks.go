@@ -0,0 +1,78 @@
+package art
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// halfBlocks draws two pixels per cell using "▀" with the top pixel as the
+// foreground and the bottom pixel as the background colour.
+func halfBlocks(img image.Image, cols, rows int) string {
+	px := scale(img, cols, rows*2)
+
+	var sb strings.Builder
+	for y := 0; y < rows; y++ {
+		if y > 0 {
+			sb.WriteByte('\n')
+		}
+		for x := 0; x < cols; x++ {
+			top := px.RGBAAt(x, y*2)
+			bottom := px.RGBAAt(x, y*2+1)
+			sb.WriteString(fg(top) + bg(bottom) + "▀")
+		}
+		sb.WriteString(reset)
+	}
+	return sb.String()
+}
+
+// braille draws 2×4 dots per cell. Dots brighter than the cell's average are
+// lit and the whole cell takes the average colour of its lit dots, which
+// keeps more shape than half blocks at the cost of colour detail.
+func braille(img image.Image, cols, rows int) string {
+	px := scale(img, cols*2, rows*4)
+
+	// Bit for each dot position, indexed [y][x] within the cell.
+	dots := [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+	var sb strings.Builder
+	for row := 0; row < rows; row++ {
+		if row > 0 {
+			sb.WriteByte('\n')
+		}
+		for col := 0; col < cols; col++ {
+			var lum [4][2]int
+			total := 0
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					c := px.RGBAAt(col*2+dx, row*4+dy)
+					lum[dy][dx] = luminance(c)
+					total += lum[dy][dx]
+				}
+			}
+			avg := total / 8
+
+			var r, g, b, n int
+			ch := rune(0x2800)
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					if lum[dy][dx] >= avg {
+						c := px.RGBAAt(col*2+dx, row*4+dy)
+						ch |= dots[dy][dx]
+						r, g, b, n = r+int(c.R), g+int(c.G), b+int(c.B), n+1
+					}
+				}
+			}
+			if n == 0 {
+				n = 1
+			}
+			sb.WriteString(fg(color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), 255}) + string(ch))
+		}
+		sb.WriteString(reset)
+	}
+	return sb.String()
+}
+
+func luminance(c color.RGBA) int {
+	return (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000
+}
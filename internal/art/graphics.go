@@ -0,0 +1,113 @@
+package art
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/png"
+	"strings"
+)
+
+// Approximate cell size in pixels, used to pick how many pixels to send to
+// graphics-capable terminals. The terminal scales the image to the cell box.
+const (
+	cellWidthPx  = 10
+	cellHeightPx = 20
+)
+
+// kitty uses the kitty graphics protocol. The pixels are transmitted once
+// (a=t) under a stable id derived from them and then placed (a=p); a redraw
+// only repeats the placement, which replaces the last one instead of stacking
+// copies. The cursor is not moved (C=1) so the block can be padded with
+// spaces.
+func kitty(img image.Image, cols, rows int) Drawing {
+	data := encodePNG(scale(img, cols*cellWidthPx, rows*cellHeightPx))
+	id := imageID(data)
+
+	var sb strings.Builder
+	const chunk = 4096
+	for i := 0; i < len(data); i += chunk {
+		end := min(i+chunk, len(data))
+		more := 0
+		if end < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&sb, "\x1b_Ga=t,f=100,q=2,i=%d,m=%d;%s\x1b\\", id, more, data[i:end])
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, data[i:end])
+		}
+	}
+
+	place := fmt.Sprintf("\x1b_Ga=p,q=2,C=1,i=%d,c=%d,r=%d\x1b\\", id, cols, rows) + Placeholder(cols, rows)
+	return Drawing{First: sb.String() + place, Again: place}
+}
+
+// iterm2 uses the iTerm2 inline image protocol, also understood by WezTerm.
+func iterm2(img image.Image, cols, rows int) string {
+	data := encodePNG(scale(img, cols*cellWidthPx, rows*cellHeightPx))
+	seq := fmt.Sprintf("\x1b]1337;File=inline=1;width=%d;height=%d;preserveAspectRatio=0;doNotMoveCursor=1:%s\a", cols, rows, data)
+	return seq + Placeholder(cols, rows)
+}
+
+// sixel encodes img as DEC sixel graphics using a 6×6×6 colour cube.
+func sixel(img image.Image, cols, rows int) string {
+	w, h := cols*cellWidthPx, rows*cellHeightPx
+	px := scale(img, w, h)
+
+	index := func(x, y int) int {
+		c := px.RGBAAt(x, y)
+		return int(c.R)*5/255*36 + int(c.G)*5/255*6 + int(c.B)*5/255
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\x1bP0;1;0q")
+	fmt.Fprintf(&sb, "\"1;1;%d;%d", w, h)
+	for i := 0; i < 216; i++ {
+		fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+	}
+
+	for band := 0; band < h; band += 6 {
+		used := map[int]bool{}
+		for y := band; y < min(band+6, h); y++ {
+			for x := 0; x < w; x++ {
+				used[index(x, y)] = true
+			}
+		}
+		for c := 0; c < 216; c++ {
+			if !used[c] {
+				continue
+			}
+			fmt.Fprintf(&sb, "#%d", c)
+			for x := 0; x < w; x++ {
+				bits := 0
+				for dy := 0; dy < 6 && band+dy < h; dy++ {
+					if index(x, band+dy) == c {
+						bits |= 1 << dy
+					}
+				}
+				sb.WriteByte(byte(63 + bits))
+			}
+			sb.WriteByte('$')
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString("\x1b\\")
+
+	return sb.String() + Placeholder(cols, rows)
+}
+
+func encodePNG(img image.Image) string {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func imageID(data string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(data))
+	// Keep the id within 24 bits so it is safe for every kitty version.
+	return h.Sum32()&0xffffff | 1
+}
@@ -0,0 +1,150 @@
+package art
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strings"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// Protocol is the way an image is drawn into the terminal.
+type Protocol string
+
+const (
+	ProtocolAuto       Protocol = "auto"
+	ProtocolKitty      Protocol = "kitty"
+	ProtocolITerm2     Protocol = "iterm2"
+	ProtocolSixel      Protocol = "sixel"
+	ProtocolHalfBlocks Protocol = "halfblocks"
+	ProtocolBraille    Protocol = "braille"
+)
+
+// Protocols lists every accepted protocol name.
+var Protocols = []Protocol{ProtocolAuto, ProtocolKitty, ProtocolITerm2, ProtocolSixel, ProtocolHalfBlocks, ProtocolBraille}
+
+// Detect picks the best protocol the current terminal advertises through its
+// environment, falling back to Unicode half blocks.
+func Detect() Protocol {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", program == "ghostty":
+		return ProtocolKitty
+	case program == "iTerm.app", program == "WezTerm":
+		return ProtocolITerm2
+	case strings.Contains(term, "sixel"), program == "foot", strings.HasPrefix(term, "foot"), term == "mlterm":
+		return ProtocolSixel
+	}
+	return ProtocolHalfBlocks
+}
+
+// Drawing is an image rendered for the terminal. First is written the first
+// time it is shown and Again on every redraw after. They differ only for
+// kitty, which keeps an image it was sent, so a redraw places it again by id
+// rather than sending its pixels every frame.
+type Drawing struct {
+	First string
+	Again string
+}
+
+func redrawn(s string) Drawing {
+	return Drawing{First: s, Again: s}
+}
+
+// Render draws img into a block of cols×rows terminal cells. The result is
+// always exactly rows lines of cols cells so it can be laid out like text.
+func Render(img image.Image, cols, rows int, protocol Protocol) Drawing {
+	if cols <= 0 || rows <= 0 {
+		return Drawing{}
+	}
+	if protocol == ProtocolAuto {
+		protocol = Detect()
+	}
+
+	switch protocol {
+	case ProtocolKitty:
+		return kitty(img, cols, rows)
+	case ProtocolITerm2:
+		return redrawn(iterm2(img, cols, rows))
+	case ProtocolSixel:
+		return redrawn(sixel(img, cols, rows))
+	case ProtocolBraille:
+		return redrawn(braille(img, cols, rows))
+	}
+	return redrawn(halfBlocks(img, cols, rows))
+}
+
+// Placeholder returns an empty cols×rows block, shown while art loads.
+func Placeholder(cols, rows int) string {
+	if cols <= 0 || rows <= 0 {
+		return ""
+	}
+	line := strings.Repeat(" ", cols)
+	lines := make([]string, rows)
+	for i := range lines {
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PickImage returns the URL of the smallest image at least minSize pixels
+// wide, or the largest one when none is big enough.
+func PickImage(images []entities.Image, minSize int) string {
+	best := -1
+	for i, img := range images {
+		if img.Width < minSize && img.Width != 0 {
+			continue
+		}
+		if best == -1 || img.Width < images[best].Width {
+			best = i
+		}
+	}
+	if best == -1 && len(images) > 0 {
+		best = 0
+	}
+	if best == -1 {
+		return ""
+	}
+	return images[best].URL
+}
+
+// scale resamples img to w×h pixels by averaging the source pixels that fall
+// into each destination pixel.
+func scale(img image.Image, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*sh/h
+		y1 := b.Min.Y + max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*sw/w
+			x1 := b.Min.X + max((x+1)*sw/w, x*sw/w+1)
+
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, _ := img.At(sx, sy).RGBA()
+					r, g, bl, n = r+cr>>8, g+cg>>8, bl+cb>>8, n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(bl / n), 255})
+		}
+	}
+	return dst
+}
+
+func fg(c color.RGBA) string {
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+func bg(c color.RGBA) string {
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+const reset = "\x1b[0m"
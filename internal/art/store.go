@@ -0,0 +1,127 @@
+package art
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store downloads cover images and keeps them in memory and on disk, so each
+// URL is fetched from the network at most once.
+type Store struct {
+	dir        string
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	images map[string]image.Image
+}
+
+// NewStore caches images under dir. Downloads give up after timeout.
+func NewStore(dir string, timeout time.Duration) *Store {
+	return &Store{
+		dir:        dir,
+		httpClient: &http.Client{Timeout: timeout},
+		images:     make(map[string]image.Image),
+	}
+}
+
+// Cached returns the decoded image for url if it has already been loaded.
+func (s *Store) Cached(url string) (image.Image, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	img, ok := s.images[url]
+	return img, ok
+}
+
+// Get returns the image for url, reading it from the disk cache or
+// downloading it as needed.
+func (s *Store) Get(ctx context.Context, url string) (image.Image, error) {
+	if img, ok := s.Cached(url); ok {
+		return img, nil
+	}
+
+	data, err := s.read(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cover image: %w", err)
+	}
+
+	s.mu.Lock()
+	s.images[url] = img
+	s.mu.Unlock()
+	return img, nil
+}
+
+func (s *Store) read(ctx context.Context, url string) ([]byte, error) {
+	path := s.path(url)
+	if data, err := os.ReadFile(path); err == nil {
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download cover image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download cover image: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cover image: %w", err)
+	}
+
+	// The cache is best-effort; a failed write just means a refetch later.
+	if err := os.MkdirAll(s.dir, 0700); err == nil {
+		_ = os.WriteFile(path, data, 0600)
+	}
+	return data, nil
+}
+
+// Clear deletes the images cached on disk. Only files named the way the
+// store names them are removed, so nothing else kept in the directory is lost.
+func (s *Store) Clear() error {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clear cover art: %w", err)
+	}
+	for _, e := range entries {
+		if _, err := hex.DecodeString(e.Name()); err != nil || len(e.Name()) != 2*sha1.Size || !e.Type().IsRegular() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, e.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to clear cover art: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) path(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Ext is the extension of archives: gzipped JSON.
+const Ext = ".json.gz"
+
+// Filename names the archive of a snapshot after its account and day.
+func Filename(s Snapshot) string {
+	return fmt.Sprintf("spotify-%s-%s%s", s.User.ID, s.CreatedAt.Local().Format("2006-01-02"), Ext)
+}
+
+// Write writes s to w as gzipped JSON.
+func Write(w io.Writer, s Snapshot) error {
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read reads an archive, gzipped or, for one unpacked to edit, plain JSON.
+func Read(r io.Reader) (Snapshot, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return Snapshot{}, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return Snapshot{}, fmt.Errorf("not a backup: %w", err)
+	}
+	switch {
+	case s.Version == 0:
+		return Snapshot{}, errors.New("not a backup: no version")
+	case s.Version > Version:
+		return Snapshot{}, fmt.Errorf("backup version %d is newer than this spotify-tui reads (%d)", s.Version, Version)
+	}
+	return s, nil
+}
+
+// Save writes s to path. The archive appears whole or not at all.
+func Save(path string, s Snapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFile(path, func(w io.Writer) error { return Write(w, s) })
+}
+
+// Load reads the archive at path.
+func Load(path string) (Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer f.Close()
+	s, err := Read(f)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// writeFile writes path through a temporary file renamed into place.
+func writeFile(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,251 @@
+// Package backup snapshots a Spotify library into a versioned archive and
+// restores it, into the same account or another one. A restore only adds:
+// it recreates playlists, appends the tracks they lack, and saves and
+// follows what the account is missing, so running it twice does nothing
+// the second time.
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/client/spotify"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// Version is the archive format written. Archives of a later version are
+// refused rather than restored in part.
+const Version = 1
+
+// Snapshot is everything a backup holds. Lists are in the order Spotify
+// returns them: playlists and their tracks as arranged, saved items newest
+// first.
+type Snapshot struct {
+	Version    int        `json:"version"`
+	CreatedAt  time.Time  `json:"created_at"`
+	User       User       `json:"user"`
+	Playlists  []Playlist `json:"playlists"`
+	LikedSongs []Item     `json:"liked_songs"`
+	Albums     []Item     `json:"albums"`
+	Shows      []Item     `json:"shows"`
+	Artists    []Item     `json:"artists"`
+}
+
+// User is a Spotify account.
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Playlist is a playlist of the library, the user's own or followed.
+// Unavailable is set when Spotify would not list its tracks, as it does for
+// some of its own playlists.
+type Playlist struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Owner       User   `json:"owner"`
+	Public      bool   `json:"public"`
+	URI         string `json:"uri"`
+	Tracks      []Item `json:"tracks"`
+	Unavailable bool   `json:"unavailable,omitempty"`
+}
+
+// Item is a track, episode, album, show or artist: its URI, which is all a
+// restore needs, and enough to recognise it by. Artists holds a show's
+// publisher.
+type Item struct {
+	URI     string   `json:"uri"`
+	Name    string   `json:"name"`
+	Artists []string `json:"artists,omitempty"`
+	Album   string   `json:"album,omitempty"`
+	ISRC    string   `json:"isrc,omitempty"`
+	AddedAt string   `json:"added_at,omitempty"`
+}
+
+// Library is the account a backup is taken from, such as
+// *service.PlaylistService.
+type Library interface {
+	CurrentUser() (entities.User, error)
+	GetPlaylists() ([]entities.Playlist, error)
+	PlaylistTracks(p entities.Playlist) ([]entities.Track, error)
+	LikedSongs() ([]entities.Track, error)
+	SavedAlbums() ([]entities.Album, error)
+	SavedShows() ([]entities.Show, error)
+	FollowedArtists() ([]entities.Artist, error)
+}
+
+// Progress is told what is being read or written and, where it is known,
+// how far along that is.
+type Progress func(stage string, done, total int)
+
+// Take reads the whole library of the account signed in.
+func Take(lib Library, progress Progress) (Snapshot, error) {
+	if progress == nil {
+		progress = func(string, int, int) {}
+	}
+	var user entities.User
+	if err := retry(func() (err error) { user, err = lib.CurrentUser(); return err }); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to get the account: %w", err)
+	}
+	s := Snapshot{Version: Version, CreatedAt: time.Now().UTC(), User: User{ID: user.ID, Name: user.Name}}
+
+	progress("Playlists", 0, 0)
+	var playlists []entities.Playlist
+	if err := retry(func() (err error) { playlists, err = lib.GetPlaylists(); return err }); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to list playlists: %w", err)
+	}
+	for i, p := range playlists {
+		progress("Playlists", i, len(playlists))
+		bp := Playlist{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			Owner:       User{ID: p.OwnerID, Name: p.OwnerName},
+			Public:      p.Public,
+			URI:         p.URI,
+			Tracks:      []Item{},
+		}
+		var tracks []entities.Track
+		err := retry(func() (err error) { tracks, err = lib.PlaylistTracks(p); return err })
+		switch {
+		case unavailable(err):
+			bp.Unavailable = true
+		case err != nil:
+			return Snapshot{}, fmt.Errorf("failed to read %s: %w", p.Name, err)
+		}
+		for _, t := range tracks {
+			// Tracks removed from Spotify come back empty.
+			if t.URI != "" {
+				bp.Tracks = append(bp.Tracks, trackItem(t))
+			}
+		}
+		s.Playlists = append(s.Playlists, bp)
+	}
+	progress("Playlists", len(playlists), len(playlists))
+
+	progress("Liked songs", 0, 0)
+	var liked []entities.Track
+	if err := retry(func() (err error) { liked, err = lib.LikedSongs(); return err }); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read liked songs: %w", err)
+	}
+	s.LikedSongs = make([]Item, 0, len(liked))
+	for _, t := range liked {
+		s.LikedSongs = append(s.LikedSongs, trackItem(t))
+	}
+
+	progress("Albums", 0, 0)
+	var albums []entities.Album
+	if err := retry(func() (err error) { albums, err = lib.SavedAlbums(); return err }); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read saved albums: %w", err)
+	}
+	s.Albums = make([]Item, 0, len(albums))
+	for _, a := range albums {
+		s.Albums = append(s.Albums, Item{URI: a.URI, Name: a.Name, Artists: artistNames(a.Artists), AddedAt: a.AddedAt})
+	}
+
+	progress("Shows", 0, 0)
+	var shows []entities.Show
+	if err := retry(func() (err error) { shows, err = lib.SavedShows(); return err }); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read saved shows: %w", err)
+	}
+	s.Shows = make([]Item, 0, len(shows))
+	for _, sh := range shows {
+		item := Item{URI: sh.URI, Name: sh.Name, AddedAt: sh.AddedAt}
+		if sh.Publisher != "" {
+			item.Artists = []string{sh.Publisher}
+		}
+		s.Shows = append(s.Shows, item)
+	}
+
+	progress("Artists", 0, 0)
+	var artists []entities.Artist
+	if err := retry(func() (err error) { artists, err = lib.FollowedArtists(); return err }); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read followed artists: %w", err)
+	}
+	s.Artists = make([]Item, 0, len(artists))
+	for _, a := range artists {
+		s.Artists = append(s.Artists, Item{URI: a.URI, Name: a.Name})
+	}
+	return s, nil
+}
+
+func trackItem(t entities.Track) Item {
+	uri := t.URI
+	if uri == "" {
+		uri = "spotify:track:" + t.ID
+	}
+	return Item{URI: uri, Name: t.Name, Artists: artistNames(t.Artists), Album: t.Album.Name, ISRC: t.ISRC, AddedAt: t.AddedAt}
+}
+
+func artistNames(artists []entities.Artist) []string {
+	names := make([]string, len(artists))
+	for i, a := range artists {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// Describe is how an item is shown: "artist - name".
+func (i Item) Describe() string {
+	if len(i.Artists) == 0 {
+		return i.Name
+	}
+	return strings.Join(i.Artists, ", ") + " - " + i.Name
+}
+
+// Counts sums up a snapshot: playlists, their tracks, liked songs, albums,
+// shows and artists.
+func (s Snapshot) Counts() (playlists, tracks, liked, albums, shows, artists int) {
+	for _, p := range s.Playlists {
+		tracks += len(p.Tracks)
+	}
+	return len(s.Playlists), tracks, len(s.LikedSongs), len(s.Albums), len(s.Shows), len(s.Artists)
+}
+
+// Retries wait what Spotify asks with Retry-After, or a little longer each
+// time when it does not say, but never more than maxWait.
+const (
+	maxAttempts = 5
+	maxWait     = 2 * time.Minute
+)
+
+// retry calls f until it succeeds or fails for a reason waiting will not
+// fix: anything but a rate limit or a server error.
+func retry(f func() error) error {
+	return retrying(f, true)
+}
+
+// retryWrite is retry for requests that change the account. A server error
+// may come after the change was made, and making it again would create a
+// second playlist or add the tracks twice, so only rate limits, which
+// Spotify refuses before doing anything, are tried again.
+func retryWrite(f func() error) error {
+	return retrying(f, false)
+}
+
+func retrying(f func() error, serverErrors bool) error {
+	for attempt := 1; ; attempt++ {
+		err := f()
+		var apiErr *spotify.APIError
+		if err == nil || attempt == maxAttempts || !errors.As(err, &apiErr) ||
+			(apiErr.Status != http.StatusTooManyRequests && (!serverErrors || apiErr.Status < 500)) {
+			return err
+		}
+		wait := apiErr.RetryAfter
+		if wait == 0 {
+			wait = time.Duration(attempt) * 2 * time.Second
+		}
+		time.Sleep(min(wait, maxWait))
+	}
+}
+
+// unavailable reports whether Spotify refused to list something, rather
+// than failed to.
+func unavailable(err error) bool {
+	var apiErr *spotify.APIError
+	return errors.As(err, &apiErr) && (apiErr.Status == http.StatusNotFound || apiErr.Status == http.StatusForbidden)
+}
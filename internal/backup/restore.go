@@ -0,0 +1,532 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// Section is a part of the library restored on its own.
+type Section string
+
+const (
+	Playlists  Section = "playlists"
+	LikedSongs Section = "liked"
+	Albums     Section = "albums"
+	Shows      Section = "shows"
+	Artists    Section = "artists"
+)
+
+// Sections lists every section, in the order they are restored.
+var Sections = []Section{Playlists, LikedSongs, Albums, Shows, Artists}
+
+// ParseSections reads a comma-separated list of sections.
+func ParseSections(s string) ([]Section, error) {
+	var out []Section
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		i := slices.Index(Sections, Section(name))
+		if i < 0 {
+			return nil, fmt.Errorf("unknown section %q: use playlists, liked, albums, shows or artists", name)
+		}
+		out = append(out, Sections[i])
+	}
+	return out, nil
+}
+
+// Restorer is the account a backup is restored into, such as
+// *service.PlaylistService.
+type Restorer interface {
+	Library
+	CreatePlaylist(name, description string, public bool) (entities.Playlist, error)
+	AddTracks(playlistID string, uris []string) error
+	FollowPlaylist(id string) error
+	SaveTracksAt(tracks []entities.Track) error
+	SaveAlbums(ids []string) error
+	SaveShows(ids []string) error
+	FollowArtists(ids []string) error
+}
+
+// Action is what a restore does with a playlist.
+type Action string
+
+const (
+	// Create makes a copy of a playlist the account does not have.
+	Create Action = "create"
+	// Append adds the tracks a playlist of the account lacks.
+	Append Action = "add to"
+	// Follow follows another user's playlist.
+	Follow Action = "follow"
+	// Keep leaves a playlist that has everything as it is.
+	Keep Action = "keep"
+)
+
+// PlaylistPlan is what a restore does with one playlist of the backup:
+// the tracks to add, in order, to the playlist TargetID, which Create
+// leaves empty until it has made it.
+type PlaylistPlan struct {
+	Playlist Playlist
+	Action   Action
+	TargetID string
+	Tracks   []Item
+}
+
+// Plan is the difference between a backup and the account it is restored
+// into: what the account lacks. Saved items are oldest first, the order
+// they are added in.
+type Plan struct {
+	From, Into User
+	Taken      time.Time
+	Sections   []Section
+	Playlists  []PlaylistPlan
+	LikedSongs []Item
+	Albums     []Item
+	Shows      []Item
+	Artists    []Item
+	// Skipped counts what cannot be restored: local files, which Spotify
+	// does not hold.
+	Skipped int
+	// Resumed counts the sections and playlists an earlier run finished.
+	Resumed int
+}
+
+// Empty reports whether the account already has everything.
+func (p Plan) Empty() bool {
+	for _, pp := range p.Playlists {
+		if pp.Action != Keep {
+			return false
+		}
+	}
+	return len(p.LikedSongs)+len(p.Albums)+len(p.Shows)+len(p.Artists) == 0
+}
+
+// Diff works out what restoring s into the account signed in would add,
+// for the sections given. Playlists the backup's account owned are
+// matched to the copy an earlier run created, then by ID when restoring
+// into the same account, then by name; those without a match are
+// recreated. Other users' playlists are followed.
+func Diff(lib Restorer, s Snapshot, sections []Section, j *Journal, progress Progress) (Plan, error) {
+	if progress == nil {
+		progress = func(string, int, int) {}
+	}
+	var user entities.User
+	if err := retry(func() (err error) { user, err = lib.CurrentUser(); return err }); err != nil {
+		return Plan{}, fmt.Errorf("failed to get the account: %w", err)
+	}
+	into := User{ID: user.ID, Name: user.Name}
+	j.start(s, into)
+	plan := Plan{From: s.User, Into: into, Taken: s.CreatedAt, Sections: sections}
+
+	for _, section := range sections {
+		if j.Done[string(section)] {
+			plan.Resumed++
+			continue
+		}
+		var err error
+		switch section {
+		case Playlists:
+			err = plan.diffPlaylists(lib, s, j, progress)
+		case LikedSongs:
+			progress("Liked songs", 0, 0)
+			var have []entities.Track
+			if err = retry(func() (err error) { have, err = lib.LikedSongs(); return err }); err == nil {
+				uris := make([]string, len(have))
+				for i, t := range have {
+					uris[i] = t.URI
+					if uris[i] == "" {
+						uris[i] = "spotify:track:" + t.ID
+					}
+				}
+				plan.LikedSongs = missing(s.LikedSongs, uris, "spotify:track:", &plan.Skipped)
+			}
+		case Albums:
+			progress("Albums", 0, 0)
+			var have []entities.Album
+			if err = retry(func() (err error) { have, err = lib.SavedAlbums(); return err }); err == nil {
+				uris := make([]string, len(have))
+				for i, a := range have {
+					uris[i] = a.URI
+				}
+				plan.Albums = missing(s.Albums, uris, "spotify:album:", &plan.Skipped)
+			}
+		case Shows:
+			progress("Shows", 0, 0)
+			var have []entities.Show
+			if err = retry(func() (err error) { have, err = lib.SavedShows(); return err }); err == nil {
+				uris := make([]string, len(have))
+				for i, sh := range have {
+					uris[i] = sh.URI
+				}
+				plan.Shows = missing(s.Shows, uris, "spotify:show:", &plan.Skipped)
+			}
+		case Artists:
+			progress("Artists", 0, 0)
+			var have []entities.Artist
+			if err = retry(func() (err error) { have, err = lib.FollowedArtists(); return err }); err == nil {
+				uris := make([]string, len(have))
+				for i, a := range have {
+					uris[i] = a.URI
+				}
+				plan.Artists = missing(s.Artists, uris, "spotify:artist:", &plan.Skipped)
+			}
+		}
+		if err != nil {
+			return Plan{}, fmt.Errorf("failed to read %s: %w", section, err)
+		}
+	}
+
+	// Saved items go back oldest first so the newest end up on top. Artists
+	// have no date and keep the backup's order.
+	slices.Reverse(plan.LikedSongs)
+	slices.Reverse(plan.Albums)
+	slices.Reverse(plan.Shows)
+	return plan, nil
+}
+
+func (plan *Plan) diffPlaylists(lib Restorer, s Snapshot, j *Journal, progress Progress) error {
+	progress("Playlists", 0, 0)
+	var library []entities.Playlist
+	if err := retry(func() (err error) { library, err = lib.GetPlaylists(); return err }); err != nil {
+		return err
+	}
+	byID := map[string]entities.Playlist{}
+	for _, p := range library {
+		byID[p.ID] = p
+	}
+	claimed := map[string]bool{}
+	for _, id := range j.Playlists {
+		claimed[id] = true
+	}
+	sameAccount := plan.From.ID == plan.Into.ID
+
+	for i, bp := range s.Playlists {
+		progress("Playlists", i, len(s.Playlists))
+		if j.Done["playlist:"+bp.ID] {
+			plan.Resumed++
+			continue
+		}
+		pp := PlaylistPlan{Playlist: bp}
+
+		if bp.Owner.ID != s.User.ID {
+			pp.Action = Follow
+			if _, ok := byID[bp.ID]; ok {
+				pp.Action = Keep
+			}
+			plan.Playlists = append(plan.Playlists, pp)
+			continue
+		}
+
+		target, found := byID[j.Playlists[bp.ID]]
+		if !found && sameAccount {
+			target, found = byID[bp.ID]
+		}
+		if !found {
+			for _, p := range library {
+				if p.OwnerID == plan.Into.ID && p.Name == bp.Name && !claimed[p.ID] {
+					target, found = p, true
+					break
+				}
+			}
+		}
+
+		// A recreated playlist is a copy of the backup's, duplicates and
+		// all. One of the account's only gets the tracks it does not have.
+		tracks := restorable(bp.Tracks, &plan.Skipped)
+		if found {
+			claimed[target.ID] = true
+			pp.Action, pp.TargetID = Append, target.ID
+			var have []entities.Track
+			if err := retry(func() (err error) { have, err = lib.PlaylistTracks(target); return err }); err != nil {
+				return fmt.Errorf("%s: %w", target.Name, err)
+			}
+			if j.Playlists[bp.ID] == target.ID {
+				// An earlier run created it and added the first tracks in
+				// order before it stopped.
+				tracks = tracks[min(len(have), len(tracks)):]
+			} else {
+				tracks = lacking(tracks, have)
+			}
+		} else {
+			pp.Action = Create
+		}
+		pp.Tracks = tracks
+		if found && len(pp.Tracks) == 0 {
+			pp.Action = Keep
+		}
+		plan.Playlists = append(plan.Playlists, pp)
+	}
+	progress("Playlists", len(s.Playlists), len(s.Playlists))
+	return nil
+}
+
+// missing returns the items whose URIs are not in have, leaving out and
+// counting in skipped those that cannot be restored: local files, or any
+// URI not of kind when kind is given.
+func missing(items []Item, have []string, kind string, skipped *int) []Item {
+	present := make(map[string]bool, len(have))
+	for _, uri := range have {
+		present[uri] = true
+	}
+	var out []Item
+	for _, item := range items {
+		if strings.HasPrefix(item.URI, "spotify:local:") || !strings.HasPrefix(item.URI, kind) {
+			*skipped++
+			continue
+		}
+		if !present[item.URI] {
+			present[item.URI] = true
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// restorable leaves out of a playlist's tracks the local files, which
+// Spotify does not hold, counting them in skipped.
+func restorable(items []Item, skipped *int) []Item {
+	var out []Item
+	for _, item := range items {
+		if strings.HasPrefix(item.URI, "spotify:local:") {
+			*skipped++
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// lacking returns the items whose URIs none of have's tracks share,
+// keeping any the backup holds more than once.
+func lacking(items []Item, have []entities.Track) []Item {
+	present := make(map[string]bool, len(have))
+	for _, t := range have {
+		present[t.URI] = true
+	}
+	var out []Item
+	for _, item := range items {
+		if !present[item.URI] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// Batch sizes for restoring: small enough to show progress and to lose
+// little when a run stops.
+const (
+	trackBatch = 100
+	savedBatch = 50
+)
+
+// Apply carries out a plan, recording in the journal what it finishes so
+// a run that stops can be resumed. It stops at the first failure.
+func Apply(lib Restorer, plan Plan, j *Journal, progress Progress) error {
+	if progress == nil {
+		progress = func(string, int, int) {}
+	}
+	for _, section := range plan.Sections {
+		if j.Done[string(section)] {
+			continue
+		}
+		var err error
+		switch section {
+		case Playlists:
+			err = applyPlaylists(lib, plan.Playlists, j, progress)
+		case LikedSongs:
+			err = inBatches("Liked songs", plan.LikedSongs, savedBatch, progress, func(items []Item) error {
+				tracks := make([]entities.Track, len(items))
+				for i, item := range items {
+					tracks[i] = entities.Track{ID: id(item.URI), AddedAt: item.AddedAt}
+				}
+				return lib.SaveTracksAt(tracks)
+			})
+		case Albums:
+			err = inBatches("Albums", plan.Albums, savedBatch, progress, func(items []Item) error {
+				return lib.SaveAlbums(ids(items))
+			})
+		case Shows:
+			err = inBatches("Shows", plan.Shows, savedBatch, progress, func(items []Item) error {
+				return lib.SaveShows(ids(items))
+			})
+		case Artists:
+			err = inBatches("Artists", plan.Artists, savedBatch, progress, func(items []Item) error {
+				return lib.FollowArtists(ids(items))
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore %s: %w", section, err)
+		}
+		if err := j.done(string(section)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyPlaylists(lib Restorer, plans []PlaylistPlan, j *Journal, progress Progress) error {
+	for i, pp := range plans {
+		progress("Playlists", i, len(plans))
+		bp := pp.Playlist
+		switch pp.Action {
+		case Follow:
+			if err := retryWrite(func() error { return lib.FollowPlaylist(bp.ID) }); err != nil {
+				return fmt.Errorf("failed to follow %s: %w", bp.Name, err)
+			}
+		case Create, Append:
+			if pp.TargetID == "" {
+				var created entities.Playlist
+				if err := retryWrite(func() (err error) {
+					created, err = lib.CreatePlaylist(bp.Name, bp.Description, bp.Public)
+					return err
+				}); err != nil {
+					return fmt.Errorf("failed to create %s: %w", bp.Name, err)
+				}
+				pp.TargetID = created.ID
+				if err := j.created(bp.ID, created.ID); err != nil {
+					return err
+				}
+			}
+			err := inBatches(bp.Name, pp.Tracks, trackBatch, progress, func(items []Item) error {
+				uris := make([]string, len(items))
+				for i, item := range items {
+					uris[i] = item.URI
+				}
+				return lib.AddTracks(pp.TargetID, uris)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to add tracks to %s: %w", bp.Name, err)
+			}
+		}
+		if err := j.done("playlist:" + bp.ID); err != nil {
+			return err
+		}
+	}
+	progress("Playlists", len(plans), len(plans))
+	return nil
+}
+
+// inBatches passes items to add size at a time, retrying each batch while
+// Spotify asks to slow down.
+func inBatches(stage string, items []Item, size int, progress Progress, add func([]Item) error) error {
+	for start := 0; start < len(items); start += size {
+		progress(stage, start, len(items))
+		batch := items[start:min(start+size, len(items))]
+		if err := retryWrite(func() error { return add(batch) }); err != nil {
+			return err
+		}
+	}
+	if len(items) > 0 {
+		progress(stage, len(items), len(items))
+	}
+	return nil
+}
+
+// id takes the ID out of a spotify: URI.
+func id(uri string) string {
+	return uri[strings.LastIndex(uri, ":")+1:]
+}
+
+func ids(items []Item) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = id(item.URI)
+	}
+	return out
+}
+
+// Journal records how far a restore got, so running it again picks up
+// where it stopped: the sections and playlists it finished, and the
+// playlists it created, which are added to rather than created twice.
+type Journal struct {
+	path      string
+	Backup    time.Time         `json:"backup"`
+	From      string            `json:"from"`
+	Into      string            `json:"into"`
+	Playlists map[string]string `json:"playlists"`
+	Done      map[string]bool   `json:"done"`
+}
+
+// JournalPath is where the journal of restoring the archive at path is
+// kept: next to it.
+func JournalPath(path string) string {
+	return path + ".progress"
+}
+
+// OpenJournal reads the journal at path, or starts one when there is none.
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, j); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if j.Playlists == nil {
+		j.Playlists = map[string]string{}
+	}
+	if j.Done == nil {
+		j.Done = map[string]bool{}
+	}
+	return j, nil
+}
+
+// Reset forgets the progress of earlier runs. The journal on disk keeps it
+// until the next step is recorded.
+func (j *Journal) Reset() {
+	j.Playlists, j.Done = map[string]string{}, map[string]bool{}
+}
+
+// start ties the journal to a backup and an account, forgetting progress
+// made restoring anything else.
+func (j *Journal) start(s Snapshot, into User) {
+	if !j.Backup.Equal(s.CreatedAt) || j.From != s.User.ID || j.Into != into.ID {
+		j.Reset()
+	}
+	j.Backup, j.From, j.Into = s.CreatedAt, s.User.ID, into.ID
+}
+
+func (j *Journal) created(backupID, id string) error {
+	j.Playlists[backupID] = id
+	return j.save()
+}
+
+func (j *Journal) done(key string) error {
+	j.Done[key] = true
+	return j.save()
+}
+
+func (j *Journal) save() error {
+	if j.path == "" {
+		return nil
+	}
+	return writeFile(j.path, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(j)
+	})
+}
+
+// Remove deletes the journal once the restore is complete.
+func (j *Journal) Remove() error {
+	if j.path == "" {
+		return nil
+	}
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
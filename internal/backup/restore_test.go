@@ -0,0 +1,241 @@
+package backup
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/client/spotify"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// fakeAccount is an account held in memory. Writes fail with the errors
+// queued in failAdds, one per call to AddTracks.
+type fakeAccount struct {
+	user      entities.User
+	playlists []entities.Playlist
+	tracks    map[string][]string // playlist ID to track URIs
+	followed  []string
+	created   int
+
+	addCalls int
+	failAdds []error
+}
+
+func newFakeAccount(id string, playlists ...entities.Playlist) *fakeAccount {
+	return &fakeAccount{user: entities.User{ID: id, Name: id}, playlists: playlists, tracks: map[string][]string{}}
+}
+
+func (f *fakeAccount) CurrentUser() (entities.User, error)         { return f.user, nil }
+func (f *fakeAccount) GetPlaylists() ([]entities.Playlist, error)  { return f.playlists, nil }
+func (f *fakeAccount) LikedSongs() ([]entities.Track, error)       { return nil, nil }
+func (f *fakeAccount) SavedAlbums() ([]entities.Album, error)      { return nil, nil }
+func (f *fakeAccount) SavedShows() ([]entities.Show, error)        { return nil, nil }
+func (f *fakeAccount) FollowedArtists() ([]entities.Artist, error) { return nil, nil }
+func (f *fakeAccount) SaveTracksAt(tracks []entities.Track) error  { return nil }
+func (f *fakeAccount) SaveAlbums(ids []string) error               { return nil }
+func (f *fakeAccount) SaveShows(ids []string) error                { return nil }
+func (f *fakeAccount) FollowArtists(ids []string) error            { return nil }
+func (f *fakeAccount) FollowPlaylist(id string) error {
+	f.followed = append(f.followed, id)
+	return nil
+}
+func (f *fakeAccount) PlaylistTracks(p entities.Playlist) ([]entities.Track, error) {
+	var out []entities.Track
+	for _, uri := range f.tracks[p.ID] {
+		out = append(out, entities.Track{URI: uri})
+	}
+	return out, nil
+}
+
+func (f *fakeAccount) CreatePlaylist(name, description string, public bool) (entities.Playlist, error) {
+	f.created++
+	p := entities.Playlist{ID: fmt.Sprint("new", f.created), Name: name, OwnerID: f.user.ID}
+	f.playlists = append(f.playlists, p)
+	return p, nil
+}
+
+func (f *fakeAccount) AddTracks(playlistID string, uris []string) error {
+	f.addCalls++
+	if len(f.failAdds) > 0 {
+		err := f.failAdds[0]
+		f.failAdds = f.failAdds[1:]
+		if err != nil {
+			return err
+		}
+	}
+	f.tracks[playlistID] = append(f.tracks[playlistID], uris...)
+	return nil
+}
+
+func items(uris ...string) []Item {
+	out := make([]Item, len(uris))
+	for i, uri := range uris {
+		out[i] = Item{URI: uri}
+	}
+	return out
+}
+
+func uris(items []Item) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = item.URI
+	}
+	return out
+}
+
+var alice = User{ID: "alice", Name: "Alice"}
+
+func snapshot(playlists ...Playlist) Snapshot {
+	return Snapshot{Version: Version, CreatedAt: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), User: alice, Playlists: playlists}
+}
+
+func TestDiffPlaylists(t *testing.T) {
+	mix := Playlist{ID: "mix", Name: "Mix", Owner: alice,
+		Tracks: items("spotify:track:a", "spotify:track:b", "spotify:local:x", "spotify:track:b", "spotify:track:c")}
+	theirs := Playlist{ID: "theirs", Name: "Theirs", Owner: User{ID: "bob"}}
+
+	tests := []struct {
+		name    string
+		account *fakeAccount
+		backup  Snapshot
+		want    []PlaylistPlan // Playlist left out
+		skipped int
+	}{
+		{
+			name: "same account adds what the playlist lacks, repeats and all",
+			account: func() *fakeAccount {
+				f := newFakeAccount("alice", entities.Playlist{ID: "mix", Name: "Renamed", OwnerID: "alice"})
+				f.tracks["mix"] = []string{"spotify:track:a"}
+				return f
+			}(),
+			backup:  snapshot(mix),
+			want:    []PlaylistPlan{{Action: Append, TargetID: "mix", Tracks: items("spotify:track:b", "spotify:track:b", "spotify:track:c")}},
+			skipped: 1,
+		},
+		{
+			name: "same account keeps a complete playlist",
+			account: func() *fakeAccount {
+				f := newFakeAccount("alice", entities.Playlist{ID: "mix", Name: "Mix", OwnerID: "alice"})
+				f.tracks["mix"] = []string{"spotify:track:c", "spotify:track:b", "spotify:track:a"}
+				return f
+			}(),
+			backup:  snapshot(mix),
+			want:    []PlaylistPlan{{Action: Keep, TargetID: "mix"}},
+			skipped: 1,
+		},
+		{
+			name: "another account matches by name, not by the backup's ID",
+			account: newFakeAccount("carol",
+				entities.Playlist{ID: "mix", Name: "Mix", OwnerID: "alice"},
+				entities.Playlist{ID: "carols", Name: "Mix", OwnerID: "carol"}),
+			backup:  snapshot(mix),
+			want:    []PlaylistPlan{{Action: Append, TargetID: "carols", Tracks: items("spotify:track:a", "spotify:track:b", "spotify:track:b", "spotify:track:c")}},
+			skipped: 1,
+		},
+		{
+			name:    "another account without a match gets a copy",
+			account: newFakeAccount("carol"),
+			backup:  snapshot(mix),
+			want:    []PlaylistPlan{{Action: Create, Tracks: items("spotify:track:a", "spotify:track:b", "spotify:track:b", "spotify:track:c")}},
+			skipped: 1,
+		},
+		{
+			name:    "one playlist of the account matches one of the backup",
+			account: newFakeAccount("carol", entities.Playlist{ID: "carols", Name: "Mix", OwnerID: "carol"}),
+			backup:  snapshot(Playlist{ID: "one", Name: "Mix", Owner: alice}, Playlist{ID: "two", Name: "Mix", Owner: alice, Tracks: items("spotify:track:a")}),
+			want: []PlaylistPlan{
+				{Action: Keep, TargetID: "carols"},
+				{Action: Create, Tracks: items("spotify:track:a")},
+			},
+		},
+		{
+			name:    "other users' playlists are followed unless they already are",
+			account: newFakeAccount("carol", entities.Playlist{ID: "theirs", OwnerID: "bob"}),
+			backup:  snapshot(theirs, Playlist{ID: "other", Owner: User{ID: "dave"}}),
+			want:    []PlaylistPlan{{Action: Keep}, {Action: Follow}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, _ := OpenJournal("")
+			plan, err := Diff(tt.account, tt.backup, []Section{Playlists}, j, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(plan.Playlists) != len(tt.want) {
+				t.Fatalf("planned %d playlists, want %d", len(plan.Playlists), len(tt.want))
+			}
+			for i, got := range plan.Playlists {
+				want := tt.want[i]
+				if got.Action != want.Action || got.TargetID != want.TargetID || !slices.Equal(uris(got.Tracks), uris(want.Tracks)) {
+					t.Errorf("%s: %s into %q with %v, want %s into %q with %v", got.Playlist.Name,
+						got.Action, got.TargetID, uris(got.Tracks), want.Action, want.TargetID, uris(want.Tracks))
+				}
+			}
+			if plan.Skipped != tt.skipped {
+				t.Errorf("Skipped = %d, want %d", plan.Skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestRestoreResumesAPartialCopy(t *testing.T) {
+	// 150 tracks, each of them twice: three batches of 100.
+	var tracks []string
+	for i := range 150 {
+		uri := fmt.Sprint("spotify:track:", i)
+		tracks = append(tracks, uri, uri)
+	}
+	backup := snapshot(Playlist{ID: "mix", Name: "Mix", Owner: alice, Tracks: items(tracks...)})
+	account := newFakeAccount("carol")
+	path := filepath.Join(t.TempDir(), "backup.json.gz.progress")
+
+	// The second batch fails with a server error, which may have come after
+	// the tracks were added, so it is not tried again.
+	account.failAdds = []error{nil, &spotify.APIError{Status: http.StatusBadGateway}}
+	j, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := Diff(account, backup, []Section{Playlists}, j, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(account, plan, j, nil); err == nil {
+		t.Fatal("Apply succeeded through a server error")
+	}
+	if account.addCalls != 2 {
+		t.Errorf("AddTracks called %d times, want 2: server errors are not retried", account.addCalls)
+	}
+
+	// A rate limit is waited out.
+	account.failAdds = []error{&spotify.APIError{Status: http.StatusTooManyRequests, RetryAfter: time.Millisecond}}
+	if j, err = OpenJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = Diff(account, backup, []Section{Playlists}, j, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pp := plan.Playlists[0]; pp.Action != Append || pp.TargetID != "new1" || len(pp.Tracks) != 200 {
+		t.Fatalf("resumed plan %s into %q with %d tracks, want to add the last 200 to new1", pp.Action, pp.TargetID, len(pp.Tracks))
+	}
+	if err := Apply(account, plan, j, nil); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if account.created != 1 {
+		t.Errorf("created %d playlists, want 1", account.created)
+	}
+	if got := account.tracks["new1"]; !slices.Equal(got, tracks) {
+		t.Errorf("the copy has %d tracks, want the backup's %d in order", len(got), len(tracks))
+	}
+	if !j.Done["playlist:mix"] || !j.Done[string(Playlists)] {
+		t.Errorf("journal = %+v, want the playlist and section done", j.Done)
+	}
+}
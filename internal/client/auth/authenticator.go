@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"slices"
+	"strings"
 
 	"golang.org/x/oauth2"
 )
@@ -18,16 +20,34 @@ var requiredScopes = []string{
 	"user-modify-playback-state",
 	"user-read-currently-playing",
 	"user-read-playback-position",
+	"user-read-recently-played",
+	"user-top-read",
 	"user-library-read",
 	"user-library-modify",
 	"playlist-read-private",
 	"playlist-read-collaborative",
 	"playlist-modify-public",
 	"playlist-modify-private",
+	"user-follow-read",
+	"user-follow-modify",
 	"user-read-private",
 	"user-read-email",
 }
 
+// MissingScopes returns the scopes spotify-tui needs that token was not
+// granted. A token saved before its scopes were recorded is missing them all.
+func MissingScopes(token *oauth2.Token) []string {
+	scope, _ := token.Extra("scope").(string)
+	granted := strings.Fields(scope)
+	var missing []string
+	for _, s := range requiredScopes {
+		if !slices.Contains(granted, s) {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
 // Authenticator handles OAuth2 token operations
 type Authenticator struct {
 	config *oauth2.Config
@@ -2,7 +2,10 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -10,6 +13,8 @@ import (
 	"github.com/thomassbooth/spotify-tui/internal/repository"
 )
 
+var errInsufficientScope = errors.New("token lacks required scopes")
+
 type Client struct {
 	flow       *AuthFlow
 	serverAddr string
@@ -47,6 +52,13 @@ func NewClient(cfg Config) *Client {
 
 func (c *Client) GetValidToken(ctx context.Context) (*oauth2.Token, error) {
 	token, err := c.TokenRepo.Load()
+	if err == nil {
+		if missing := MissingScopes(token); len(missing) > 0 {
+			// Features added since the last login need scopes it did not ask for.
+			fmt.Printf("The saved login lacks %s, logging in again...\n", strings.Join(missing, ", "))
+			err = errInsufficientScope
+		}
+	}
 	if err == nil {
 		if token.Valid() {
 			return token, nil
@@ -77,6 +89,40 @@ func (c *Client) GetValidToken(ctx context.Context) (*oauth2.Token, error) {
 	return token, nil
 }
 
+// TokenSource returns a source that refreshes token when it expires and
+// saves each new one, for processes that outlive a single access token.
+func (c *Client) TokenSource(ctx context.Context, token *oauth2.Token) oauth2.TokenSource {
+	return &savingTokenSource{
+		src:  c.flow.auth.config.TokenSource(ctx, token),
+		repo: c.TokenRepo,
+		last: token.AccessToken,
+	}
+}
+
+type savingTokenSource struct {
+	src  oauth2.TokenSource
+	repo *repository.TokenRepository
+
+	mu   sync.Mutex
+	last string
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token.AccessToken != s.last {
+		s.last = token.AccessToken
+		if err := s.repo.Save(token); err != nil {
+			fmt.Printf("Warning: failed to save refreshed token: %v\n", err)
+		}
+	}
+	return token, nil
+}
+
 func (c *Client) Logout() error {
 	return c.TokenRepo.Delete()
 }
@@ -84,6 +84,11 @@ func (f *AuthFlow) Authenticate(ctx context.Context, serverAddr string) (*oauth2
 
 	fmt.Println("✓ Successfully authenticated!")
 
+	// Spotify reports the scopes granted; when it does not, they are the ones
+	// asked for, and recording them keeps the next start from logging in again.
+	if _, ok := token.Extra("scope").(string); !ok {
+		token = token.WithExtra(map[string]interface{}{"scope": strings.Join(requiredScopes, " ")})
+	}
 	return token, nil
 }
 
@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/google/go-querystring/query"
 	"golang.org/x/oauth2"
@@ -27,6 +29,14 @@ func NewClient(token *oauth2.Token) *Client {
 	}
 }
 
+// NewClientFromSource creates a client whose token comes from ts, so it can
+// be refreshed while the client is in use.
+func NewClientFromSource(ts oauth2.TokenSource) *Client {
+	return &Client{
+		httpClient: oauth2.NewClient(context.Background(), ts),
+	}
+}
+
 func (c *Client) Get(ctx context.Context, path string, params interface{}) ([]byte, error) {
 	return c.do(ctx, http.MethodGet, path, params, nil)
 }
@@ -37,7 +47,7 @@ func (c *Client) Post(ctx context.Context, path string, params, body interface{}
 
 func (c *Client) Put(ctx context.Context, path string, params, body interface{}) ([]byte, error) {
 	return c.do(ctx, http.MethodPut, path, params, body)
-}	
+}
 
 func (c *Client) Delete(ctx context.Context, path string, params, body interface{}) ([]byte, error) {
 	return c.do(ctx, http.MethodDelete, path, params, body)
@@ -70,9 +80,43 @@ func (c *Client) do(ctx context.Context, method, path string, params, body inter
 		return nil, fmt.Errorf("error reading response body: %w", err)
 	}
 
+	if resp.StatusCode >= 400 {
+		apiErr := newAPIError(resp.StatusCode, respBody)
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			apiErr.RetryAfter = time.Duration(secs) * time.Second
+		}
+		return nil, apiErr
+	}
+
 	return respBody, nil
 }
 
+// APIError is a non-2xx response from the Web API.
+type APIError struct {
+	Status  int
+	Message string
+	// RetryAfter is how long Spotify asks to wait before trying again,
+	// sent with 429 Too Many Requests.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("spotify api: %d %s", e.Status, http.StatusText(e.Status))
+	}
+	return fmt.Sprintf("spotify api: %d %s", e.Status, e.Message)
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	return &APIError{Status: status, Message: payload.Error.Message}
+}
+
 func (c *Client) addQueryParams(path string, params interface{}) (string, error) {
 	fullURL := baseURL + path
 
@@ -0,0 +1,200 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thomassbooth/spotify-tui/internal/client/spotify/response"
+)
+
+// savedTracksBatchSize is the most IDs /me/tracks/contains accepts at once.
+const savedTracksBatchSize = 50
+
+// Largest page and batch sizes the library endpoints accept.
+const (
+	libraryPageSize       = 50
+	savedAlbumsBatchSize  = 20
+	savedShowsBatchSize   = 50
+	followArtistBatchSize = 50
+)
+
+// ContainsSavedTracks reports, in order, whether each track is in the user's
+// Liked Songs. Any number of IDs may be passed; they are sent in batches.
+func (client *Client) ContainsSavedTracks(ctx context.Context, ids []string) ([]bool, error) {
+	out := make([]bool, 0, len(ids))
+
+	for start := 0; start < len(ids); start += savedTracksBatchSize {
+		batch := ids[start:min(start+savedTracksBatchSize, len(ids))]
+		params := map[string]interface{}{"ids": strings.Join(batch, ",")}
+		data, err := client.Get(ctx, "/me/tracks/contains", params)
+		if err != nil {
+			return nil, err
+		}
+
+		var saved []bool
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return nil, fmt.Errorf("failed to decode saved tracks response: %w", err)
+		}
+		out = append(out, saved...)
+	}
+
+	return out, nil
+}
+
+// SaveTracks adds tracks to the user's Liked Songs.
+func (client *Client) SaveTracks(ctx context.Context, ids []string) error {
+	return client.batchSavedTracks(ctx, ids, client.Put)
+}
+
+// RemoveSavedTracks removes tracks from the user's Liked Songs.
+func (client *Client) RemoveSavedTracks(ctx context.Context, ids []string) error {
+	return client.batchSavedTracks(ctx, ids, client.Delete)
+}
+
+func (client *Client) batchSavedTracks(ctx context.Context, ids []string, send func(context.Context, string, interface{}, interface{}) ([]byte, error)) error {
+	for start := 0; start < len(ids); start += savedTracksBatchSize {
+		batch := ids[start:min(start+savedTracksBatchSize, len(ids))]
+		if _, err := send(ctx, "/me/tracks", nil, map[string]interface{}{"ids": batch}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSavedTracks returns a page of the user's Liked Songs from offset,
+// newest first.
+func (client *Client) GetSavedTracks(ctx context.Context, offset int) (*response.SavedTracksResponse, error) {
+	params := map[string]interface{}{"limit": libraryPageSize, "offset": offset}
+	data, err := client.Get(ctx, "/me/tracks", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var page response.SavedTracksResponse
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode saved tracks: %w", err)
+	}
+	return &page, nil
+}
+
+// SavedTrack is a track to add to Liked Songs, dated AddedAt (RFC 3339)
+// when it is set.
+type SavedTrack struct {
+	ID      string `json:"id"`
+	AddedAt string `json:"added_at,omitempty"`
+}
+
+// SaveTracksAt adds tracks to the user's Liked Songs with the dates they
+// were first liked, which keeps their order there.
+func (client *Client) SaveTracksAt(ctx context.Context, tracks []SavedTrack) error {
+	for start := 0; start < len(tracks); start += savedTracksBatchSize {
+		batch := tracks[start:min(start+savedTracksBatchSize, len(tracks))]
+		if _, err := client.Put(ctx, "/me/tracks", nil, map[string]interface{}{"timestamped_ids": batch}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSavedAlbums returns a page of the user's saved albums from offset,
+// newest first.
+func (client *Client) GetSavedAlbums(ctx context.Context, offset int) (*response.SavedAlbumsResponse, error) {
+	params := map[string]interface{}{"limit": libraryPageSize, "offset": offset}
+	data, err := client.Get(ctx, "/me/albums", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var page response.SavedAlbumsResponse
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode saved albums: %w", err)
+	}
+	return &page, nil
+}
+
+// SaveAlbums adds albums to the user's library.
+func (client *Client) SaveAlbums(ctx context.Context, ids []string) error {
+	for start := 0; start < len(ids); start += savedAlbumsBatchSize {
+		batch := ids[start:min(start+savedAlbumsBatchSize, len(ids))]
+		if _, err := client.Put(ctx, "/me/albums", nil, map[string]interface{}{"ids": batch}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSavedShows returns a page of the podcasts the user follows from
+// offset, newest first.
+func (client *Client) GetSavedShows(ctx context.Context, offset int) (*response.SavedShowsResponse, error) {
+	params := map[string]interface{}{"limit": libraryPageSize, "offset": offset}
+	data, err := client.Get(ctx, "/me/shows", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var page response.SavedShowsResponse
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode saved shows: %w", err)
+	}
+	return &page, nil
+}
+
+// SaveShows follows podcasts.
+func (client *Client) SaveShows(ctx context.Context, ids []string) error {
+	for start := 0; start < len(ids); start += savedShowsBatchSize {
+		batch := ids[start:min(start+savedShowsBatchSize, len(ids))]
+		params := map[string]interface{}{"ids": strings.Join(batch, ",")}
+		if _, err := client.Put(ctx, "/me/shows", params, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetFollowedArtists returns a page of the artists the user follows, from
+// after the artist ID after, or from the start when it is empty. Artists
+// are paged by cursor rather than offset.
+func (client *Client) GetFollowedArtists(ctx context.Context, after string) (*response.FollowedArtists, error) {
+	params := map[string]interface{}{"type": "artist", "limit": libraryPageSize}
+	if after != "" {
+		params["after"] = after
+	}
+	data, err := client.Get(ctx, "/me/following", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp response.FollowedArtistsResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode followed artists: %w", err)
+	}
+	return &resp.Artists, nil
+}
+
+// FollowArtists follows artists.
+func (client *Client) FollowArtists(ctx context.Context, ids []string) error {
+	for start := 0; start < len(ids); start += followArtistBatchSize {
+		batch := ids[start:min(start+followArtistBatchSize, len(ids))]
+		params := map[string]interface{}{"type": "artist"}
+		if _, err := client.Put(ctx, "/me/following", params, map[string]interface{}{"ids": batch}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCurrentUser returns the account the token belongs to.
+func (client *Client) GetCurrentUser(ctx context.Context) (*response.User, error) {
+	data, err := client.Get(ctx, "/me", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var user response.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, fmt.Errorf("failed to decode user: %w", err)
+	}
+	return &user, nil
+}
@@ -15,6 +15,11 @@ func (client *Client) GetCurrentPlayback(ctx context.Context) (*entities.Playbac
 		return nil, err
 	}
 
+	// 204 No Content: nothing is playing on any device.
+	if len(data) == 0 {
+		return &entities.PlaybackState{}, nil
+	}
+
 	var state entities.PlaybackState
 	if err := json.Unmarshal(data, &state); err != nil {
 		return nil, fmt.Errorf("Cant decode json response from playback: %w", err)
@@ -40,3 +45,39 @@ func (client *Client) GetQueue(ctx context.Context) (*QueueResponse, error) {
 
 	return &resp, nil
 }
+
+type devicesResponse struct {
+	Devices []entities.Device `json:"devices"`
+}
+
+func (client *Client) GetDevices(ctx context.Context) ([]entities.Device, error) {
+	data, err := client.Get(ctx, "/me/player/devices", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp devicesResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode devices response: %w", err)
+	}
+	return resp.Devices, nil
+}
+
+type recentlyPlayedResponse struct {
+	Items []entities.PlayHistory `json:"items"`
+}
+
+// GetRecentlyPlayed returns the last 50 tracks played, newest first. It is
+// as far back as Spotify keeps.
+func (client *Client) GetRecentlyPlayed(ctx context.Context) ([]entities.PlayHistory, error) {
+	data, err := client.Get(ctx, "/me/player/recently-played", map[string]interface{}{"limit": 50})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp recentlyPlayedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode recently played response: %w", err)
+	}
+	return resp.Items, nil
+}
@@ -4,39 +4,137 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 
 	"github.com/thomassbooth/spotify-tui/internal/client/spotify/response"
 )
 
+// Largest page sizes the API accepts for each endpoint.
+const (
+	playlistsPageSize     = 50
+	playlistItemsPageSize = 100
+)
+
+// GetPlaylists returns every playlist of the current user, following the
+// API's pagination until the last page.
 func (client *Client) GetPlaylists(ctx context.Context) (*response.GetPlaylistsResponse, error) {
-	// Get raw JSON data
-	data, err := client.Get(ctx, "/me/playlists", nil) // Note: /playlists not /playlist
+	var all response.GetPlaylistsResponse
+
+	for offset := 0; ; {
+		params := map[string]interface{}{"limit": playlistsPageSize, "offset": offset}
+		data, err := client.Get(ctx, "/me/playlists", params) // Note: /playlists not /playlist
+		if err != nil {
+			return nil, err
+		}
+
+		var page response.GetPlaylistsResponse
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode playlists response: %w", err)
+		}
+
+		all.Items = append(all.Items, page.Items...)
+		all.Total = page.Total
+		if page.Next == "" || len(page.Items) == 0 {
+			break
+		}
+		offset += len(page.Items)
+	}
+
+	return &all, nil
+}
+
+// GetPlaylist returns one playlist, which need not be in the user's
+// library.
+func (client *Client) GetPlaylist(ctx context.Context, playlistID string) (*response.PlaylistItem, error) {
+	params := map[string]interface{}{"fields": "id,name,description,public,images,owner,tracks.total,uri,type,snapshot_id"}
+	data, err := client.Get(ctx, "/playlists/"+url.PathEscape(playlistID), params)
 	if err != nil {
 		return nil, err
 	}
 
-	// Unmarshal into response struct
-	var response response.GetPlaylistsResponse
-	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("failed to decode playlists response: %w", err)
+	var playlist response.PlaylistItem
+	if err := json.Unmarshal(data, &playlist); err != nil {
+		return nil, fmt.Errorf("failed to decode playlist response: %w", err)
 	}
-
-	return &response, nil
+	return &playlist, nil
 }
 
-func (client *Client) GetPlaylistItems(ctx context.Context, playlistID string) (*response.GetPlaylistItemsResponse, error) {
-	endpoint := fmt.Sprintf("/playlists/%s/tracks", playlistID)
+// CreatePlaylist creates a playlist owned by the current user.
+func (client *Client) CreatePlaylist(ctx context.Context, name, description string, public bool) (*response.PlaylistItem, error) {
+	body := map[string]interface{}{"name": name, "description": description, "public": public}
+	data, err := client.Post(ctx, "/me/playlists", nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var playlist response.PlaylistItem
+	if err := json.Unmarshal(data, &playlist); err != nil {
+		return nil, fmt.Errorf("failed to decode created playlist: %w", err)
+	}
+	return &playlist, nil
+}
 
-	data, err := client.Get(ctx, endpoint, nil)
+// FollowPlaylist adds a playlist of another user to the library.
+func (client *Client) FollowPlaylist(ctx context.Context, playlistID string) error {
+	_, err := client.Put(ctx, "/playlists/"+url.PathEscape(playlistID)+"/followers", nil, nil)
+	return err
+}
 
+// GetPlaylistItems returns a page of a playlist's tracks starting at offset.
+func (client *Client) GetPlaylistItems(ctx context.Context, playlistID string, offset int) (*response.GetPlaylistItemsResponse, error) {
+	endpoint := fmt.Sprintf("/playlists/%s/tracks", playlistID)
+	params := map[string]interface{}{"limit": playlistItemsPageSize, "offset": offset}
+	data, err := client.Get(ctx, endpoint, params)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp response.GetPlaylistItemsResponse
-	if err := json.Unmarshal(data, &resp); err != nil {
+	var page response.GetPlaylistItemsResponse
+	if err := json.Unmarshal(data, &page); err != nil {
 		return nil, err
 	}
+	return &page, nil
+}
+
+// AddPlaylistItems appends tracks to the end of a playlist.
+func (client *Client) AddPlaylistItems(ctx context.Context, playlistID string, uris []string) error {
+	endpoint := fmt.Sprintf("/playlists/%s/tracks", playlistID)
 
-	return &resp, nil
+	for start := 0; start < len(uris); start += playlistItemsPageSize {
+		batch := uris[start:min(start+playlistItemsPageSize, len(uris))]
+		if _, err := client.Post(ctx, endpoint, nil, map[string]interface{}{"uris": batch}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemovePlaylistItems removes every occurrence of the given tracks from a
+// playlist and returns the playlist's new snapshot ID.
+func (client *Client) RemovePlaylistItems(ctx context.Context, playlistID, snapshotID string, uris []string) (string, error) {
+	endpoint := fmt.Sprintf("/playlists/%s/tracks", playlistID)
+
+	for start := 0; start < len(uris); start += playlistItemsPageSize {
+		batch := uris[start:min(start+playlistItemsPageSize, len(uris))]
+		tracks := make([]map[string]string, len(batch))
+		for i, uri := range batch {
+			tracks[i] = map[string]string{"uri": uri}
+		}
+
+		body := map[string]interface{}{"tracks": tracks}
+		if snapshotID != "" {
+			body["snapshot_id"] = snapshotID
+		}
+		data, err := client.Delete(ctx, endpoint, nil, body)
+		if err != nil {
+			return "", err
+		}
+
+		var resp response.SnapshotResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return "", fmt.Errorf("failed to decode remove tracks response: %w", err)
+		}
+		snapshotID = resp.SnapshotID
+	}
+	return snapshotID, nil
 }
@@ -0,0 +1,62 @@
+package response
+
+type SavedTracksResponse struct {
+	Next  string       `json:"next"`
+	Total int          `json:"total"`
+	Items []SavedTrack `json:"items"`
+}
+
+type SavedTrack struct {
+	AddedAt string `json:"added_at"`
+	Track   Track  `json:"track"`
+}
+
+type SavedAlbumsResponse struct {
+	Next  string       `json:"next"`
+	Total int          `json:"total"`
+	Items []SavedAlbum `json:"items"`
+}
+
+type SavedAlbum struct {
+	AddedAt string `json:"added_at"`
+	Album   Album  `json:"album"`
+}
+
+type SavedShowsResponse struct {
+	Next  string      `json:"next"`
+	Total int         `json:"total"`
+	Items []SavedShow `json:"items"`
+}
+
+type SavedShow struct {
+	AddedAt string `json:"added_at"`
+	Show    Show   `json:"show"`
+}
+
+type Show struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Publisher string `json:"publisher"`
+	URI       string `json:"uri"`
+}
+
+type FollowedArtistsResponse struct {
+	Artists FollowedArtists `json:"artists"`
+}
+
+type FollowedArtists struct {
+	Next    string   `json:"next"`
+	Total   int      `json:"total"`
+	Cursors Cursors  `json:"cursors"`
+	Items   []Artist `json:"items"`
+}
+
+type Cursors struct {
+	After string `json:"after"`
+}
+
+type User struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	URI         string `json:"uri"`
+}
@@ -1,7 +1,13 @@
 package response
 
 type GetPlaylistsResponse struct {
-	Items []PlaylistItem `json:"items"`
+	Href     string         `json:"href"`
+	Limit    int            `json:"limit"`
+	Next     string         `json:"next"`
+	Offset   int            `json:"offset"`
+	Previous string         `json:"previous"`
+	Total    int            `json:"total"`
+	Items    []PlaylistItem `json:"items"`
 }
 
 type PlaylistItem struct {
@@ -36,12 +42,14 @@ type PlaylistTrackItem struct {
 }
 
 type Track struct {
-	ID         string   `json:"id"`
-	Name       string   `json:"name"`
-	Album      Album    `json:"album"`
-	Artists    []Artist `json:"artists"`
-	DurationMs int      `json:"duration_ms"`
-	URI        string   `json:"uri"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Album       Album       `json:"album"`
+	Artists     []Artist    `json:"artists"`
+	DurationMs  int         `json:"duration_ms"`
+	Popularity  int         `json:"popularity"`
+	URI         string      `json:"uri"`
+	ExternalIDs ExternalIDs `json:"external_ids"`
 }
 
 type Album struct {
@@ -79,3 +87,20 @@ type PlaylistTracks struct {
 type ExternalURLs struct {
 	Spotify string `json:"spotify"`
 }
+
+type ExternalIDs struct {
+	ISRC string `json:"isrc"`
+}
+
+type SnapshotResponse struct {
+	SnapshotID string `json:"snapshot_id"`
+}
+
+type SearchResponse struct {
+	Tracks SearchTracks `json:"tracks"`
+}
+
+type SearchTracks struct {
+	Total int     `json:"total"`
+	Items []Track `json:"items"`
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/thomassbooth/spotify-tui/internal/client/spotify/response"
 )
 
+// searchLimit is the most results /search returns per type.
+const searchLimit = 50
+
 func (client *Client) GetSearch(ctx context.Context) (*response.GetPlaylistsResponse, error) {
 	// Get raw JSON data
 	data, err := client.Get(ctx, "/me/playlists", nil) // Note: /playlists not /playlist
@@ -22,4 +25,20 @@ func (client *Client) GetSearch(ctx context.Context) (*response.GetPlaylistsResp
 	}
 
 	return &response, nil
-}
\ No newline at end of file
+}
+
+// SearchTracks returns up to limit tracks matching query, which may use the
+// track:, artist:, album: and isrc: field filters.
+func (client *Client) SearchTracks(ctx context.Context, query string, limit int) ([]response.Track, error) {
+	params := map[string]interface{}{"q": query, "type": "track", "limit": min(max(limit, 1), searchLimit)}
+	data, err := client.Get(ctx, "/search", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp response.SearchResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+	return resp.Tracks.Items, nil
+}
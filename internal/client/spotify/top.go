@@ -0,0 +1,82 @@
+package spotify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// artistsBatchSize is the most IDs /artists accepts at once.
+const artistsBatchSize = 50
+
+type topArtistsResponse struct {
+	Items []entities.Artist `json:"items"`
+}
+
+type topTracksResponse struct {
+	Items []entities.Track `json:"items"`
+}
+
+// GetTopArtists returns the user's 50 most listened artists over timeRange:
+// "short_term" (about four weeks), "medium_term" (six months) or
+// "long_term" (a year).
+func (client *Client) GetTopArtists(ctx context.Context, timeRange string) ([]entities.Artist, error) {
+	data, err := client.Get(ctx, "/me/top/artists", map[string]interface{}{"time_range": timeRange, "limit": 50})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp topArtistsResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode top artists response: %w", err)
+	}
+	return resp.Items, nil
+}
+
+// GetTopTracks returns the user's 50 most listened tracks over timeRange;
+// see GetTopArtists.
+func (client *Client) GetTopTracks(ctx context.Context, timeRange string) ([]entities.Track, error) {
+	data, err := client.Get(ctx, "/me/top/tracks", map[string]interface{}{"time_range": timeRange, "limit": 50})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp topTracksResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode top tracks response: %w", err)
+	}
+	return resp.Items, nil
+}
+
+type artistsResponse struct {
+	Artists []*entities.Artist `json:"artists"`
+}
+
+// GetArtists returns the full artists, genres included, for ids. Any number
+// of IDs may be passed; they are sent in batches. Unknown IDs are left out.
+func (client *Client) GetArtists(ctx context.Context, ids []string) ([]entities.Artist, error) {
+	out := make([]entities.Artist, 0, len(ids))
+
+	for start := 0; start < len(ids); start += artistsBatchSize {
+		batch := ids[start:min(start+artistsBatchSize, len(ids))]
+		data, err := client.Get(ctx, "/artists", map[string]interface{}{"ids": strings.Join(batch, ",")})
+		if err != nil {
+			return nil, err
+		}
+
+		var resp artistsResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("failed to decode artists response: %w", err)
+		}
+		for _, a := range resp.Artists {
+			if a != nil {
+				out = append(out, *a)
+			}
+		}
+	}
+
+	return out, nil
+}
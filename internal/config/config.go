@@ -0,0 +1,432 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config is the full set of user-tunable settings. Every field has a default
+// (see Default) so a missing or partial config file is always valid.
+type Config struct {
+	Auth     AuthConfig     `toml:"auth"`
+	Polling  PollingConfig  `toml:"polling"`
+	Layout   LayoutConfig   `toml:"layout"`
+	Theme    ThemeConfig    `toml:"theme"`
+	Keymap   KeymapConfig   `toml:"keymap"`
+	Art      ArtConfig      `toml:"art"`
+	Cache    CacheConfig    `toml:"cache"`
+	Lyrics   LyricsConfig   `toml:"lyrics"`
+	Daemon   DaemonConfig   `toml:"daemon"`
+	MPRIS    MPRISConfig    `toml:"mpris"`
+	Hooks    HooksConfig    `toml:"hooks"`
+	Status   StatusConfig   `toml:"status"`
+	History  HistoryConfig  `toml:"history"`
+	Scrobble ScrobbleConfig `toml:"scrobble"`
+	Export   ExportConfig   `toml:"export"`
+	Backup   BackupConfig   `toml:"backup"`
+}
+
+type AuthConfig struct {
+	ClientID     string   `toml:"client_id"`
+	ClientSecret string   `toml:"client_secret"`
+	CallbackAddr string   `toml:"callback_addr"`
+	Timeout      Duration `toml:"timeout"`
+	TokenPath    string   `toml:"token_path"`
+}
+
+type PollingConfig struct {
+	PlaybackInterval Duration `toml:"playback_interval"`
+	RequestTimeout   Duration `toml:"request_timeout"`
+	VolumeStep       int      `toml:"volume_step"`
+}
+
+// LayoutConfig controls the arrangement of the panes. TrackView is either
+// "table", one row per track with sortable columns, or "compact", the
+// two-line title and artist view. Mouse enables clicking and scrolling.
+// NowPlayingPane adds a third column with the current track on wide
+// terminals.
+type LayoutConfig struct {
+	SidebarRatio   float64 `toml:"sidebar_ratio"`
+	ShowSidebar    bool    `toml:"show_sidebar"`
+	ShowLogo       bool    `toml:"show_logo"`
+	NowPlayingPane bool    `toml:"now_playing_pane"`
+	TrackView      string  `toml:"track_view"`
+	Mouse          bool    `toml:"mouse"`
+}
+
+const (
+	TrackViewTable   = "table"
+	TrackViewCompact = "compact"
+)
+
+// ThemeConfig selects a built-in or user theme by name. Custom themes are
+// read from Dir; the colour fields, as hex strings ("#rrggbb") or ANSI
+// colour numbers, override individual colours of the selected theme.
+type ThemeConfig struct {
+	Name    string `toml:"name"`
+	Dir     string `toml:"dir"`
+	Accent  string `toml:"accent,omitempty"`
+	Text    string `toml:"text,omitempty"`
+	Subtext string `toml:"subtext,omitempty"`
+	Muted   string `toml:"muted,omitempty"`
+	Dim     string `toml:"dim,omitempty"`
+	Border  string `toml:"border,omitempty"`
+}
+
+// ArtConfig controls cover art. Protocol is one of auto, kitty, iterm2,
+// sixel, halfblocks or braille.
+type ArtConfig struct {
+	Enabled  bool   `toml:"enabled"`
+	Protocol string `toml:"protocol"`
+	CacheDir string `toml:"cache_dir"`
+}
+
+// CacheConfig controls the on-disk library cache used to show playlists and
+// tracks before the network responds.
+type CacheConfig struct {
+	Enabled   bool   `toml:"enabled"`
+	Dir       string `toml:"dir"`
+	MaxSizeMB int    `toml:"max_size_mb"`
+}
+
+// LyricsConfig controls the lyrics pane. Dir holds .lrc files named after
+// the track ID, "<artist> - <title>" or the title.
+type LyricsConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Dir     string `toml:"dir"`
+}
+
+// DaemonConfig locates the daemon's control socket. Attach makes the TUI
+// use a daemon listening on Socket instead of talking to Spotify itself.
+type DaemonConfig struct {
+	Socket string `toml:"socket"`
+	Attach bool   `toml:"attach"`
+}
+
+// MPRISConfig controls the MPRIS D-Bus player used by media keys and
+// desktop widgets on Linux. It is registered by the daemon, or by the TUI
+// when it runs without one.
+type MPRISConfig struct {
+	Enabled bool `toml:"enabled"`
+}
+
+// HooksConfig lists shell commands to run on playback events. Each command
+// gets the track as SPOTIFY_* environment variables and the whole event as
+// JSON on stdin. At most MaxConcurrent run at once, each for up to
+// Timeout; failures are written to LogFile.
+type HooksConfig struct {
+	Timeout          Duration `toml:"timeout"`
+	MaxConcurrent    int      `toml:"max_concurrent"`
+	LogFile          string   `toml:"log_file"`
+	TrackChanged     []string `toml:"track_changed"`
+	Paused           []string `toml:"paused"`
+	Resumed          []string `toml:"resumed"`
+	DeviceChanged    []string `toml:"device_changed"`
+	PlaylistSelected []string `toml:"playlist_selected"`
+}
+
+// StatusConfig controls the line printed by `spotify-tui status` for status
+// bars. Format is a Go template over the playback fields ({{.Track}},
+// {{.Artists}}, {{.Progress}}, ...) and Idle is printed when nothing is
+// playing. Lines are cut to MaxWidth cells unless it is 0. Without a
+// daemon, a fetched state is reused by later invocations for CacheTTL.
+type StatusConfig struct {
+	Format   string   `toml:"format"`
+	Idle     string   `toml:"idle"`
+	MaxWidth int      `toml:"max_width"`
+	CacheTTL Duration `toml:"cache_ttl"`
+}
+
+// HistoryConfig controls the local listening history in File, recorded by
+// the daemon, or by the TUI when it runs without one.
+type HistoryConfig struct {
+	Enabled bool   `toml:"enabled"`
+	File    string `toml:"file"`
+}
+
+// ScrobbleConfig controls submitting plays to Last.fm and ListenBrainz,
+// done by the daemon, or by the TUI when it runs without one. Scrobbles
+// that could not be sent wait in QueueDir until they can.
+type ScrobbleConfig struct {
+	QueueDir     string             `toml:"queue_dir"`
+	LastFM       LastFMConfig       `toml:"lastfm"`
+	ListenBrainz ListenBrainzConfig `toml:"listenbrainz"`
+}
+
+// LastFMConfig holds a Last.fm API account and the session key that
+// `spotify-tui scrobble auth` gets for it. APIURL can point at another
+// Last.fm compatible service.
+type LastFMConfig struct {
+	Enabled    bool   `toml:"enabled"`
+	APIKey     string `toml:"api_key"`
+	APISecret  string `toml:"api_secret"`
+	SessionKey string `toml:"session_key"`
+	APIURL     string `toml:"api_url"`
+}
+
+// ListenBrainzConfig holds the user token from the ListenBrainz settings
+// page. APIURL can point at a self-hosted instance.
+type ListenBrainzConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Token   string `toml:"token"`
+	APIURL  string `toml:"api_url"`
+}
+
+// ExportConfig controls where the sidebar exports playlists to: a file
+// named after the playlist in Dir, in Format, one of csv, json, m3u8 and
+// xspf.
+type ExportConfig struct {
+	Dir    string `toml:"dir"`
+	Format string `toml:"format"`
+}
+
+// BackupConfig controls where `spotify-tui backup` saves library
+// archives when not given a file.
+type BackupConfig struct {
+	Dir string `toml:"dir"`
+}
+
+// KeymapConfig maps a component to its action bindings, e.g.
+//
+//	[keymap.global]
+//	quit = ["q", "ctrl+c"]
+type KeymapConfig map[string]map[string][]string
+
+// Duration wraps time.Duration so it can be written as "5s" in TOML.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+// Default returns the built-in configuration, matching the behaviour the app
+// had before it was configurable.
+func Default() *Config {
+	return &Config{
+		Auth: AuthConfig{
+			CallbackAddr: "localhost:8889",
+			Timeout:      Duration{2 * time.Minute},
+			TokenPath:    filepath.Join(homeDir(), ".spotify-tui", "token.json"),
+		},
+		Polling: PollingConfig{
+			PlaybackInterval: Duration{5 * time.Second},
+			RequestTimeout:   Duration{15 * time.Second},
+			VolumeStep:       10,
+		},
+		Layout: LayoutConfig{
+			SidebarRatio:   0.35,
+			ShowSidebar:    true,
+			ShowLogo:       true,
+			NowPlayingPane: true,
+			TrackView:      TrackViewTable,
+			Mouse:          true,
+		},
+		Theme: ThemeConfig{
+			Name: "spotify",
+			Dir:  filepath.Join(filepath.Dir(DefaultPath()), "themes"),
+		},
+		Keymap: defaultKeymap(),
+		Art: ArtConfig{
+			Enabled:  true,
+			Protocol: "auto",
+			CacheDir: filepath.Join(cacheHome(), "spotify-tui", "art"),
+		},
+		Cache: CacheConfig{
+			Enabled:   true,
+			Dir:       filepath.Join(cacheHome(), "spotify-tui", "library"),
+			MaxSizeMB: 100,
+		},
+		Lyrics: LyricsConfig{
+			Enabled: true,
+			Dir:     filepath.Join(filepath.Dir(DefaultPath()), "lyrics"),
+		},
+		Daemon: DaemonConfig{
+			Socket: defaultSocket(),
+			Attach: true,
+		},
+		MPRIS: MPRISConfig{
+			Enabled: true,
+		},
+		Hooks: HooksConfig{
+			Timeout:       Duration{10 * time.Second},
+			MaxConcurrent: 4,
+			LogFile:       filepath.Join(stateHome(), "spotify-tui", "hooks.log"),
+		},
+		Status: StatusConfig{
+			Format:   "{{.StatusIcon}} {{.Artist}} - {{.Track}} {{.Progress}}/{{.Duration}}",
+			MaxWidth: 60,
+			CacheTTL: Duration{3 * time.Second},
+		},
+		History: HistoryConfig{
+			Enabled: true,
+			File:    filepath.Join(stateHome(), "spotify-tui", "history.jsonl"),
+		},
+		Scrobble: ScrobbleConfig{
+			QueueDir: filepath.Join(stateHome(), "spotify-tui", "scrobble"),
+			LastFM: LastFMConfig{
+				APIURL: "https://ws.audioscrobbler.com/2.0/",
+			},
+			ListenBrainz: ListenBrainzConfig{
+				APIURL: "https://api.listenbrainz.org",
+			},
+		},
+		Export: ExportConfig{
+			Dir:    filepath.Join(homeDir(), "Music", "Playlists"),
+			Format: "csv",
+		},
+		Backup: BackupConfig{
+			Dir: filepath.Join(stateHome(), "spotify-tui", "backups"),
+		},
+	}
+}
+
+// DefaultPath returns ~/.config/spotify-tui/config.toml, honouring
+// XDG_CONFIG_HOME when it is set.
+func DefaultPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "spotify-tui", "config.toml")
+	}
+	return filepath.Join(homeDir(), ".config", "spotify-tui", "config.toml")
+}
+
+// Load reads the config file at path on top of the defaults. A missing file
+// is not an error. Credentials fall back to the SPOTIFY_CLIENT_ID and
+// SPOTIFY_CLIENT_SECRET environment variables.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := cfg.decode(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if cfg.Auth.ClientID == "" {
+		cfg.Auth.ClientID = os.Getenv("SPOTIFY_CLIENT_ID")
+	}
+	if cfg.Auth.ClientSecret == "" {
+		cfg.Auth.ClientSecret = os.Getenv("SPOTIFY_CLIENT_SECRET")
+	}
+	cfg.Auth.TokenPath = expandHome(cfg.Auth.TokenPath)
+	cfg.Theme.Dir = expandHome(cfg.Theme.Dir)
+	cfg.Art.CacheDir = expandHome(cfg.Art.CacheDir)
+	cfg.Cache.Dir = expandHome(cfg.Cache.Dir)
+	cfg.Lyrics.Dir = expandHome(cfg.Lyrics.Dir)
+	cfg.Daemon.Socket = expandHome(cfg.Daemon.Socket)
+	cfg.Hooks.LogFile = expandHome(cfg.Hooks.LogFile)
+	cfg.History.File = expandHome(cfg.History.File)
+	cfg.Scrobble.QueueDir = expandHome(cfg.Scrobble.QueueDir)
+	cfg.Export.Dir = expandHome(cfg.Export.Dir)
+	cfg.Backup.Dir = expandHome(cfg.Backup.Dir)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// decode merges TOML data into cfg. Keymap entries are merged per action so
+// overriding one binding keeps the defaults for the rest.
+func (c *Config) decode(data []byte) error {
+	keymap := c.Keymap
+	c.Keymap = nil
+
+	md, err := toml.Decode(string(data), c)
+	if err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, k := range undecoded {
+			keys[i] = k.String()
+		}
+		return fmt.Errorf("unknown config keys: %s", strings.Join(keys, ", "))
+	}
+
+	for section, actions := range c.Keymap {
+		if keymap[section] == nil {
+			keymap[section] = map[string][]string{}
+		}
+		for action, keys := range actions {
+			keymap[section][action] = keys
+		}
+	}
+	c.Keymap = keymap
+	return nil
+}
+
+// Encode renders the config as TOML.
+func (c *Config) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// cacheHome returns $XDG_CACHE_HOME, or ~/.cache when it is unset.
+func cacheHome() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homeDir(), ".cache")
+}
+
+// stateHome returns $XDG_STATE_HOME, or ~/.local/state when it is unset.
+func stateHome() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homeDir(), ".local", "state")
+}
+
+// defaultSocket returns $XDG_RUNTIME_DIR/spotify-tui/daemon.sock, or a
+// per-user directory under the temporary directory when it is unset.
+func defaultSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "spotify-tui", "daemon.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("spotify-tui-%d", os.Getuid()), "daemon.sock")
+}
+
+func homeDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return home
+}
+
+func expandHome(path string) string {
+	if path == "~" {
+		return homeDir()
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(homeDir(), path[2:])
+	}
+	return path
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Keymap sections. Global bindings are checked before any component sees a
+// key; list bindings drive cursor movement in both the sidebar and the track
+// list.
+const (
+	KeymapGlobal     = "global"
+	KeymapList       = "list"
+	KeymapSidebar    = "sidebar"
+	KeymapTracks     = "tracks"
+	KeymapPlaybar    = "playbar"
+	KeymapNavigation = "navigation"
+	KeymapPalette    = "palette"
+	KeymapConfirm    = "confirm"
+)
+
+func defaultKeymap() KeymapConfig {
+	return KeymapConfig{
+		KeymapGlobal: {
+			"quit":             {"q", "ctrl+c"},
+			"search":           {"/"},
+			"cycle_focus":      {"tab"},
+			"cycle_focus_back": {"shift+tab"},
+			"toggle_queue":     {"Q"},
+			"toggle_history":   {"H"},
+			"toggle_shuffle":   {"S"},
+			"cycle_theme":      {"T"},
+			"help":             {"?"},
+			"back":             {"backspace", "[", "alt+left"},
+			"forward":          {"]", "alt+right"},
+			"command_palette":  {":", "ctrl+p"},
+			"sidebar_narrower": {"<"},
+			"sidebar_wider":    {">"},
+			"toggle_sidebar":   {"ctrl+b"},
+			"toggle_logo":      {"ctrl+l"},
+			"now_playing":      {"N"},
+			"lyrics":           {"ctrl+y"},
+			"stats":            {"D"},
+		},
+		KeymapList: {
+			"up":           {"up", "k"},
+			"down":         {"down", "j"},
+			"page_up":      {"pgup", "b", "u"},
+			"page_down":    {"pgdown", "f", "d"},
+			"top":          {"home", "g"},
+			"bottom":       {"end", "G"},
+			"filter":       {"ctrl+f"},
+			"clear_filter": {"esc"},
+		},
+		KeymapSidebar: {
+			"select": {"enter"},
+			"retry":  {"r"},
+			"export": {"e"},
+		},
+		KeymapTracks: {
+			"play":             {"enter"},
+			"retry":            {"r"},
+			"filter_prev":      {"left", "h"},
+			"filter_next":      {"right", "l"},
+			"filter_all":       {"1"},
+			"filter_playlists": {"2"},
+			"filter_albums":    {"3"},
+			"filter_songs":     {"4"},
+			"toggle_view":      {"t"},
+			"sort":             {"s"},
+			"sort_reverse":     {"R"},
+			"toggle_select":    {" "},
+			"visual":           {"v"},
+			"extend_up":        {"shift+up", "K"},
+			"extend_down":      {"shift+down", "J"},
+			"select_all":       {"ctrl+a"},
+			"queue":            {"a"},
+			"add_to_playlist":  {"A"},
+			"remove":           {"x", "delete"},
+			"like":             {"L"},
+			"copy_uris":        {"y"},
+			"copy_links":       {"Y"},
+		},
+		KeymapPlaybar: {
+			"play_pause":  {" ", "enter"},
+			"next":        {"n", "l", "right"},
+			"previous":    {"p", "h", "left"},
+			"volume_up":   {"+", "="},
+			"volume_down": {"-"},
+		},
+		KeymapNavigation: {
+			"submit": {"enter"},
+			"cancel": {"esc"},
+		},
+		KeymapPalette: {
+			"up":       {"up", "ctrl+k"},
+			"down":     {"down", "ctrl+j"},
+			"complete": {"tab"},
+			"run":      {"enter"},
+			"close":    {"esc"},
+		},
+		KeymapConfirm: {
+			"yes": {"y", "enter"},
+		},
+	}
+}
+
+// Keys returns the keys bound to action in section.
+func (k KeymapConfig) Keys(section, action string) []string {
+	return k[section][action]
+}
+
+// Sections returns the section names in a stable order.
+func (k KeymapConfig) Sections() []string {
+	return sortedKeys(k)
+}
+
+// Actions returns the action names of section in a stable order.
+func (k KeymapConfig) Actions(section string) []string {
+	return sortedKeys(k[section])
+}
+
+// sharedScopes lists which sections see the same key presses; a key bound in
+// two sections of the same scope would shadow one of them.
+var sharedScopes = map[string][]string{
+	KeymapGlobal:     nil,
+	KeymapList:       {KeymapGlobal},
+	KeymapSidebar:    {KeymapGlobal, KeymapList},
+	KeymapTracks:     {KeymapGlobal, KeymapList},
+	KeymapPlaybar:    {KeymapGlobal},
+	KeymapNavigation: nil,
+	KeymapPalette:    nil,
+	KeymapConfirm:    nil,
+}
+
+func (k KeymapConfig) validate() error {
+	var errs []error
+	defaults := defaultKeymap()
+
+	for _, section := range k.Sections() {
+		if _, ok := defaults[section]; !ok {
+			errs = append(errs, fmt.Errorf("keymap.%s: unknown section", section))
+			continue
+		}
+
+		owner := map[string]string{}
+		for _, action := range k.Actions(section) {
+			keys := k[section][action]
+			if _, ok := defaults[section][action]; !ok {
+				errs = append(errs, fmt.Errorf("keymap.%s.%s: unknown action", section, action))
+				continue
+			}
+			if len(keys) == 0 {
+				errs = append(errs, fmt.Errorf("keymap.%s.%s: needs at least one key", section, action))
+			}
+			for _, key := range keys {
+				if prev, ok := owner[key]; ok {
+					errs = append(errs, fmt.Errorf("keymap.%s: %q is bound to both %s and %s", section, key, prev, action))
+					continue
+				}
+				owner[key] = action
+			}
+		}
+
+		for _, other := range sharedScopes[section] {
+			for _, action := range k.Actions(section) {
+				for _, key := range k[section][action] {
+					for _, otherAction := range k.Actions(other) {
+						if contains(k[other][otherAction], key) {
+							errs = append(errs, fmt.Errorf("keymap.%s.%s: %q is shadowed by %s.%s", section, action, key, other, otherAction))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var hexColour = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Validate checks every field and reports all problems at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, _, err := net.SplitHostPort(c.Auth.CallbackAddr); err != nil {
+		errs = append(errs, fmt.Errorf("auth.callback_addr: %w", err))
+	}
+	if c.Auth.Timeout.Duration <= 0 {
+		errs = append(errs, errors.New("auth.timeout: must be positive"))
+	}
+	if c.Auth.TokenPath == "" {
+		errs = append(errs, errors.New("auth.token_path: must not be empty"))
+	}
+
+	if c.Polling.PlaybackInterval.Duration < time.Second {
+		errs = append(errs, errors.New("polling.playback_interval: must be at least 1s"))
+	}
+	if c.Polling.RequestTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("polling.request_timeout: must be positive"))
+	}
+	if c.Polling.VolumeStep < 1 || c.Polling.VolumeStep > 100 {
+		errs = append(errs, errors.New("polling.volume_step: must be between 1 and 100"))
+	}
+
+	if c.Layout.SidebarRatio < 0.1 || c.Layout.SidebarRatio > 0.9 {
+		errs = append(errs, errors.New("layout.sidebar_ratio: must be between 0.1 and 0.9"))
+	}
+	switch c.Layout.TrackView {
+	case TrackViewTable, TrackViewCompact:
+	default:
+		errs = append(errs, fmt.Errorf("layout.track_view: %q is not one of table, compact", c.Layout.TrackView))
+	}
+
+	if c.Theme.Name == "" {
+		errs = append(errs, errors.New("theme.name: must not be empty"))
+	}
+	colours := []struct{ name, value string }{
+		{"accent", c.Theme.Accent},
+		{"text", c.Theme.Text},
+		{"subtext", c.Theme.Subtext},
+		{"muted", c.Theme.Muted},
+		{"dim", c.Theme.Dim},
+		{"border", c.Theme.Border},
+	}
+	for _, colour := range colours {
+		if colour.value != "" && !ValidColour(colour.value) {
+			errs = append(errs, fmt.Errorf("theme.%s: %q is not a hex colour or ANSI number", colour.name, colour.value))
+		}
+	}
+
+	switch c.Art.Protocol {
+	case "auto", "kitty", "iterm2", "sixel", "halfblocks", "braille":
+	default:
+		errs = append(errs, fmt.Errorf("art.protocol: %q is not one of auto, kitty, iterm2, sixel, halfblocks, braille", c.Art.Protocol))
+	}
+	if c.Art.Enabled && c.Art.CacheDir == "" {
+		errs = append(errs, errors.New("art.cache_dir: must not be empty"))
+	}
+
+	if c.Cache.Enabled && c.Cache.Dir == "" {
+		errs = append(errs, errors.New("cache.dir: must not be empty"))
+	}
+	if c.Cache.MaxSizeMB < 0 {
+		errs = append(errs, errors.New("cache.max_size_mb: must not be negative"))
+	}
+
+	if c.Lyrics.Enabled && c.Lyrics.Dir == "" {
+		errs = append(errs, errors.New("lyrics.dir: must not be empty"))
+	}
+
+	if c.Daemon.Socket == "" {
+		errs = append(errs, errors.New("daemon.socket: must not be empty"))
+	}
+
+	if c.Hooks.Timeout.Duration <= 0 {
+		errs = append(errs, errors.New("hooks.timeout: must be positive"))
+	}
+	if c.Hooks.MaxConcurrent < 1 {
+		errs = append(errs, errors.New("hooks.max_concurrent: must be at least 1"))
+	}
+	if c.Hooks.LogFile == "" {
+		errs = append(errs, errors.New("hooks.log_file: must not be empty"))
+	}
+
+	if c.Status.Format == "" {
+		errs = append(errs, errors.New("status.format: must not be empty"))
+	}
+	if c.Status.MaxWidth < 0 {
+		errs = append(errs, errors.New("status.max_width: must not be negative"))
+	}
+	if c.Status.CacheTTL.Duration < 0 {
+		errs = append(errs, errors.New("status.cache_ttl: must not be negative"))
+	}
+
+	if c.History.Enabled && c.History.File == "" {
+		errs = append(errs, errors.New("history.file: must not be empty"))
+	}
+
+	lastfm, lb := c.Scrobble.LastFM, c.Scrobble.ListenBrainz
+	if (lastfm.Enabled || lb.Enabled) && c.Scrobble.QueueDir == "" {
+		errs = append(errs, errors.New("scrobble.queue_dir: must not be empty"))
+	}
+	if lastfm.Enabled {
+		if lastfm.APIKey == "" || lastfm.APISecret == "" {
+			errs = append(errs, errors.New("scrobble.lastfm: api_key and api_secret must be set"))
+		}
+		if lastfm.SessionKey == "" {
+			errs = append(errs, errors.New("scrobble.lastfm.session_key: must be set, run `spotify-tui scrobble auth` to get one"))
+		}
+	}
+	if lb.Enabled && lb.Token == "" {
+		errs = append(errs, errors.New("scrobble.listenbrainz.token: must be set"))
+	}
+
+	if c.Export.Dir == "" {
+		errs = append(errs, errors.New("export.dir: must not be empty"))
+	}
+	switch c.Export.Format {
+	case "csv", "json", "m3u8", "xspf":
+	default:
+		errs = append(errs, fmt.Errorf("export.format: %q is not one of csv, json, m3u8, xspf", c.Export.Format))
+	}
+	if c.Backup.Dir == "" {
+		errs = append(errs, errors.New("backup.dir: must not be empty"))
+	}
+
+	if err := c.Keymap.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidColour reports whether s is a hex colour or an ANSI colour number.
+func ValidColour(s string) bool {
+	if hexColour.MatchString(s) {
+		return true
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
@@ -0,0 +1,272 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+// responseMargin is how much longer than the daemon's own request timeout
+// a client waits before giving up on a call.
+const responseMargin = 5 * time.Second
+
+// ErrClosed is returned by calls on a connection that has gone away.
+var ErrClosed = errors.New("daemon connection closed")
+
+// Client is a connection to a running daemon.
+type Client struct {
+	conn    net.Conn
+	timeout time.Duration
+	pid     int
+
+	writeMu sync.Mutex
+	enc     *json.Encoder
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan message
+	err     error                       // why the connection ended, once it has
+	updates chan service.PlaybackUpdate // set by WatchPlayback
+}
+
+// Dial connects to the daemon listening on path and checks it speaks this
+// protocol version. timeout is the daemon's request timeout; calls are
+// given a little longer before they fail.
+func Dial(path string, timeout time.Duration) (*Client, error) {
+	nc, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		conn:    nc,
+		timeout: timeout + responseMargin,
+		enc:     json.NewEncoder(nc),
+		pending: make(map[uint64]chan message),
+	}
+	go c.read()
+
+	var hello helloResult
+	if err := c.call(context.Background(), methodHello, helloParams{Version: ProtocolVersion}, &hello); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	c.pid = hello.PID
+	return c, nil
+}
+
+// PID is the daemon's process ID.
+func (c *Client) PID() int {
+	return c.pid
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Shutdown asks the daemon to exit. The daemon may close the connection
+// before it answers, which counts as success.
+func (c *Client) Shutdown() error {
+	err := c.call(context.Background(), methodShutdown, nil, nil)
+	if errors.Is(err, ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// State returns the playback state the daemon last polled, or a fresh one
+// from Spotify.
+func (c *Client) State(fresh bool) (*entities.PlaybackState, error) {
+	var r stateResult
+	if err := c.call(context.Background(), methodState, stateParams{Fresh: fresh}, &r); err != nil {
+		return nil, err
+	}
+	if r.Error != "" {
+		return nil, errors.New(r.Error)
+	}
+	return r.State, nil
+}
+
+// WatchPlayback subscribes to playback changes. The channel is closed,
+// after an update carrying the error, when the connection ends.
+func (c *Client) WatchPlayback() <-chan service.PlaybackUpdate {
+	c.mu.Lock()
+	if c.updates != nil {
+		c.mu.Unlock()
+		return c.updates
+	}
+	c.updates = make(chan service.PlaybackUpdate, 1)
+	updates, closed := c.updates, c.err != nil
+	c.mu.Unlock()
+
+	if closed {
+		c.finishUpdates()
+		return updates
+	}
+	go func() {
+		if err := c.call(context.Background(), methodSubscribe, nil, nil); err != nil {
+			c.deliver(service.PlaybackUpdate{Err: err})
+		}
+	}()
+	return updates
+}
+
+// call sends a request and decodes its result into result, which may be
+// nil.
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	return c.callWithin(ctx, c.timeout, method, params, result)
+}
+
+// tracksTimeout is how long to wait for the tracks of a playlist of n
+// tracks: the daemon reads them a page of 100 at a time, each page within
+// its own request timeout.
+func (c *Client) tracksTimeout(n int) time.Duration {
+	return c.requestsTimeout(1 + n/100)
+}
+
+// batchesTimeout is how long to wait for the daemon to send n items size at
+// a time, each batch within its own request timeout.
+func (c *Client) batchesTimeout(n, size int) time.Duration {
+	return c.requestsTimeout(max(1, (n+size-1)/size))
+}
+
+func (c *Client) requestsTimeout(requests int) time.Duration {
+	return time.Duration(requests)*(c.timeout-responseMargin) + responseMargin
+}
+
+// callWithin is call for requests that take the daemon more than one
+// request, waiting timeout for them instead.
+func (c *Client) callWithin(ctx context.Context, timeout time.Duration, method string, params, result any) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	reply := make(chan message, 1)
+	c.pending[id] = reply
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	c.writeMu.Lock()
+	err = c.enc.Encode(message{JSONRPC: "2.0", ID: &id, Method: method, Params: raw})
+	c.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrClosed, err)
+	}
+
+	select {
+	case m, ok := <-reply:
+		if !ok {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.err
+		}
+		if m.Error != nil {
+			return m.Error
+		}
+		if result == nil || len(m.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(m.Result, result)
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	}
+}
+
+// read dispatches responses to their callers and notifications to the
+// playback watcher until the connection ends.
+func (c *Client) read() {
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxResponseSize)
+	for scanner.Scan() {
+		var m message
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			continue
+		}
+		if m.ID != nil {
+			c.mu.Lock()
+			reply := c.pending[*m.ID]
+			c.mu.Unlock()
+			if reply != nil {
+				reply <- m
+			}
+			continue
+		}
+		if m.Method == notifyChanged {
+			var r stateResult
+			if err := json.Unmarshal(m.Params, &r); err != nil {
+				continue
+			}
+			u := service.PlaybackUpdate{State: r.State}
+			if r.Error != "" {
+				u.Err = errors.New(r.Error)
+			}
+			c.deliver(u)
+		}
+	}
+
+	c.mu.Lock()
+	c.err = ErrClosed
+	for id, reply := range c.pending {
+		close(reply)
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+	c.finishUpdates()
+}
+
+// deliver passes u to the watcher, replacing an update it has not read yet.
+func (c *Client) deliver(u service.PlaybackUpdate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.updates == nil || c.err != nil {
+		return
+	}
+	select {
+	case c.updates <- u:
+		return
+	default:
+	}
+	select {
+	case <-c.updates:
+	default:
+	}
+	c.updates <- u
+}
+
+// finishUpdates tells the watcher, if any, that the connection is gone.
+func (c *Client) finishUpdates() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.updates == nil {
+		return
+	}
+	select {
+	case <-c.updates:
+	default:
+	}
+	c.updates <- service.PlaybackUpdate{Err: c.err}
+	close(c.updates)
+	c.updates = nil
+}
@@ -0,0 +1,216 @@
+// Package daemon shares one Spotify session between processes. A daemon
+// owns the token, the library cache and the playback state, and serves
+// them over a Unix socket; the TUI and CLI commands attach as clients.
+//
+// The protocol is JSON-RPC 2.0 with one message per line. A connection
+// starts with a daemon.hello request carrying the client's protocol
+// version, which the daemon refuses if it differs from its own. After
+// playback.subscribe the daemon sends playback.changed notifications until
+// the connection closes or playback.unsubscribe is called.
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+)
+
+// ProtocolVersion is raised whenever a method changes incompatibly.
+const ProtocolVersion = 1
+
+// Methods. Parameters and results are the structs below, named after the
+// method.
+const (
+	methodHello       = "daemon.hello"
+	methodShutdown    = "daemon.shutdown"
+	methodState       = "playback.state"
+	methodSubscribe   = "playback.subscribe"
+	methodUnsubscribe = "playback.unsubscribe"
+	methodPlay        = "playback.play"
+	methodPause       = "playback.pause"
+	methodResume      = "playback.resume"
+	methodNext        = "playback.next"
+	methodPrevious    = "playback.previous"
+	methodSeek        = "playback.seek"
+	methodVolume      = "playback.volume"
+	methodShuffle     = "playback.shuffle"
+	methodRepeat      = "playback.repeat"
+	methodDevices     = "playback.devices"
+	methodTransfer    = "playback.transfer"
+	methodQueue       = "playback.queue"
+	methodAddToQueue  = "playback.add_to_queue"
+	methodPlaylists   = "playlists.list"
+	methodCachedLists = "playlists.cached"
+	methodPlaylist    = "playlists.get"
+	methodTracks      = "playlists.tracks"
+	methodCachedTrack = "playlists.cached_tracks"
+	methodAddTracks   = "playlists.add_tracks"
+	methodRemove      = "playlists.remove_tracks"
+	methodSaved       = "library.saved"
+	methodSetSaved    = "library.set_saved"
+	methodTopArtists  = "library.top_artists"
+	methodTopTracks   = "library.top_tracks"
+	methodArtists     = "library.artists"
+	methodExport      = "playlists.export"
+	methodCreate      = "playlists.create"
+	methodSearch      = "library.search_tracks"
+
+	// notifyChanged is sent to subscribers with a stateResult.
+	notifyChanged = "playback.changed"
+)
+
+// Error codes from the JSON-RPC specification, plus our own.
+const (
+	codeParse          = -32700
+	codeInvalidRequest = -32600
+	codeNoMethod       = -32601
+	codeInvalidParams  = -32602
+	codeFailed         = -32000 // the Spotify call failed
+	codeVersion        = -32001 // no or mismatched hello
+)
+
+// message is any JSON-RPC message: a request has a method and an ID, a
+// notification a method and no ID, a response an ID and a result or error.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *uint64         `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is an error returned by the daemon.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("daemon: %s (%d)", e.Message, e.Code)
+}
+
+type helloParams struct {
+	Version int `json:"version"`
+}
+
+type helloResult struct {
+	Version int `json:"version"`
+	PID     int `json:"pid"`
+}
+
+type stateParams struct {
+	// Fresh asks for the state from Spotify rather than the last poll.
+	Fresh bool `json:"fresh,omitempty"`
+}
+
+// stateResult is also the payload of playback.changed.
+type stateResult struct {
+	State *entities.PlaybackState `json:"state"`
+	Error string                  `json:"error,omitempty"`
+}
+
+type playParams struct {
+	TrackURI   string `json:"track_uri"`
+	ContextURI string `json:"context_uri,omitempty"`
+}
+
+type seekParams struct {
+	PositionMs int `json:"position_ms"`
+}
+
+type volumeParams struct {
+	Percent int `json:"percent"`
+}
+
+type shuffleParams struct {
+	State bool `json:"state"`
+}
+
+type repeatParams struct {
+	State string `json:"state"`
+}
+
+type transferParams struct {
+	DeviceID string `json:"device_id"`
+}
+
+type urisParams struct {
+	URIs []string `json:"uris"`
+}
+
+type cachedListsResult struct {
+	Playlists []entities.Playlist `json:"playlists"`
+	OK        bool                `json:"ok"`
+}
+
+type playlistParams struct {
+	ID string `json:"id"`
+}
+
+type tracksParams struct {
+	ID         string `json:"id"`
+	SnapshotID string `json:"snapshot_id"`
+}
+
+type cachedTracksResult struct {
+	Tracks []entities.Track `json:"tracks"`
+	Fresh  bool             `json:"fresh"`
+	OK     bool             `json:"ok"`
+}
+
+type addTracksParams struct {
+	PlaylistID string   `json:"playlist_id"`
+	URIs       []string `json:"uris"`
+}
+
+type removeParams struct {
+	PlaylistID string   `json:"playlist_id"`
+	SnapshotID string   `json:"snapshot_id"`
+	URIs       []string `json:"uris"`
+}
+
+type removeResult struct {
+	SnapshotID string `json:"snapshot_id"`
+}
+
+type savedParams struct {
+	IDs []string `json:"ids"`
+}
+
+type setSavedParams struct {
+	IDs   []string `json:"ids"`
+	Saved bool     `json:"saved"`
+}
+
+type topParams struct {
+	TimeRange string `json:"time_range"`
+}
+
+type artistsParams struct {
+	IDs []string `json:"ids"`
+}
+
+type exportParams struct {
+	Playlist entities.Playlist   `json:"playlist"`
+	Format   playlistfile.Format `json:"format"`
+}
+
+// exportResult is the written file, which the client saves where it
+// likes: the daemon may run in another directory.
+type exportResult struct {
+	Data string `json:"data"`
+}
+
+type createParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Public      bool   `json:"public,omitempty"`
+}
+
+type searchParams struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
@@ -0,0 +1,253 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+var (
+	_ service.Playback  = (*PlaybackClient)(nil)
+	_ service.Playlists = (*PlaylistClient)(nil)
+)
+
+// PlaybackClient controls playback through the daemon.
+type PlaybackClient struct {
+	c *Client
+}
+
+func (c *Client) Playback() *PlaybackClient {
+	return &PlaybackClient{c: c}
+}
+
+// WatchPlayback streams the daemon's playback changes; see
+// Client.WatchPlayback.
+func (p *PlaybackClient) WatchPlayback() <-chan service.PlaybackUpdate {
+	return p.c.WatchPlayback()
+}
+
+func (p *PlaybackClient) GetCurrentPlaybackState() (*entities.PlaybackState, error) {
+	return p.c.State(true)
+}
+
+func (p *PlaybackClient) GetQueue(ctx context.Context) ([]entities.Track, error) {
+	var tracks []entities.Track
+	err := p.c.call(ctx, methodQueue, nil, &tracks)
+	return tracks, err
+}
+
+func (p *PlaybackClient) UpNext() ([]entities.Track, error) {
+	return p.GetQueue(context.Background())
+}
+
+func (p *PlaybackClient) Devices() ([]entities.Device, error) {
+	var devices []entities.Device
+	err := p.c.call(context.Background(), methodDevices, nil, &devices)
+	return devices, err
+}
+
+func (p *PlaybackClient) Play(trackURI string, playlistURI string) error {
+	return p.do(methodPlay, playParams{TrackURI: trackURI, ContextURI: playlistURI})
+}
+
+func (p *PlaybackClient) PausePlayback() error {
+	return p.do(methodPause, nil)
+}
+
+func (p *PlaybackClient) ResumePlayback() error {
+	return p.do(methodResume, nil)
+}
+
+func (p *PlaybackClient) NextTrack() error {
+	return p.do(methodNext, nil)
+}
+
+func (p *PlaybackClient) PreviousTrack() error {
+	return p.do(methodPrevious, nil)
+}
+
+func (p *PlaybackClient) SeekTo(positionMs int) error {
+	return p.do(methodSeek, seekParams{PositionMs: positionMs})
+}
+
+func (p *PlaybackClient) SetVolume(percent int) error {
+	return p.do(methodVolume, volumeParams{Percent: percent})
+}
+
+func (p *PlaybackClient) VolumeUp(current int, step int) error {
+	return p.SetVolume(min(current+step, 100))
+}
+
+func (p *PlaybackClient) VolumeDown(current int, step int) error {
+	return p.SetVolume(max(current-step, 0))
+}
+
+func (p *PlaybackClient) ToggleShufflePlayback(state bool) error {
+	return p.do(methodShuffle, shuffleParams{State: state})
+}
+
+func (p *PlaybackClient) ToggleRepeatPlayback(state string) error {
+	return p.do(methodRepeat, repeatParams{State: state})
+}
+
+func (p *PlaybackClient) TransferPlayback(deviceID string) error {
+	return p.do(methodTransfer, transferParams{DeviceID: deviceID})
+}
+
+func (p *PlaybackClient) AddToQueue(uris []string) error {
+	timeout := p.c.batchesTimeout(len(uris), 1)
+	return p.c.callWithin(context.Background(), timeout, methodAddToQueue, urisParams{URIs: uris}, nil)
+}
+
+func (p *PlaybackClient) do(method string, params any) error {
+	return p.c.call(context.Background(), method, params, nil)
+}
+
+// PlaylistClient reads and edits the library through the daemon, using the
+// daemon's cache.
+type PlaylistClient struct {
+	c *Client
+
+	// The last playlists fetched, so CachedPlaylists, which views call
+	// while rendering, stays off the socket.
+	mu        sync.Mutex
+	playlists []entities.Playlist
+}
+
+func (c *Client) Playlists() *PlaylistClient {
+	return &PlaylistClient{c: c}
+}
+
+func (p *PlaylistClient) CachedPlaylists() ([]entities.Playlist, bool) {
+	p.mu.Lock()
+	playlists := p.playlists
+	p.mu.Unlock()
+	if playlists != nil {
+		return playlists, true
+	}
+
+	var r cachedListsResult
+	if err := p.c.call(context.Background(), methodCachedLists, nil, &r); err != nil || !r.OK {
+		return nil, false
+	}
+	p.remember(r.Playlists)
+	return r.Playlists, true
+}
+
+func (p *PlaylistClient) CachedPlaylistTracks(id, snapshotID string) (tracks []entities.Track, fresh bool, ok bool) {
+	var r cachedTracksResult
+	if err := p.c.call(context.Background(), methodCachedTrack, tracksParams{ID: id, SnapshotID: snapshotID}, &r); err != nil {
+		return nil, false, false
+	}
+	return r.Tracks, r.Fresh, r.OK
+}
+
+func (p *PlaylistClient) GetPlaylists() ([]entities.Playlist, error) {
+	var playlists []entities.Playlist
+	if err := p.c.call(context.Background(), methodPlaylists, nil, &playlists); err != nil {
+		return nil, err
+	}
+	p.remember(playlists)
+	return playlists, nil
+}
+
+func (p *PlaylistClient) Playlist(id string) (entities.Playlist, error) {
+	var playlist entities.Playlist
+	err := p.c.call(context.Background(), methodPlaylist, playlistParams{ID: id}, &playlist)
+	return playlist, err
+}
+
+func (p *PlaylistClient) GetPlaylistTracks(id, snapshotID string) ([]entities.Track, error) {
+	var tracks []entities.Track
+	timeout := p.c.tracksTimeout(p.trackCount(id))
+	err := p.c.callWithin(context.Background(), timeout, methodTracks, tracksParams{ID: id, SnapshotID: snapshotID}, &tracks)
+	return tracks, err
+}
+
+func (p *PlaylistClient) SavedTracks(ids []string) (map[string]bool, error) {
+	var saved map[string]bool
+	err := p.c.call(context.Background(), methodSaved, savedParams{IDs: ids}, &saved)
+	return saved, err
+}
+
+func (p *PlaylistClient) AddTracks(playlistID string, uris []string) error {
+	timeout := p.c.batchesTimeout(len(uris), service.PlaylistBatchSize)
+	return p.c.callWithin(context.Background(), timeout, methodAddTracks, addTracksParams{PlaylistID: playlistID, URIs: uris}, nil)
+}
+
+func (p *PlaylistClient) RemoveTracks(playlistID, snapshotID string, uris []string) (string, error) {
+	var r removeResult
+	timeout := p.c.batchesTimeout(len(uris), service.PlaylistBatchSize)
+	err := p.c.callWithin(context.Background(), timeout, methodRemove, removeParams{PlaylistID: playlistID, SnapshotID: snapshotID, URIs: uris}, &r)
+	return r.SnapshotID, err
+}
+
+func (p *PlaylistClient) SetSaved(ids []string, saved bool) error {
+	timeout := p.c.batchesTimeout(len(ids), service.SavedBatchSize)
+	return p.c.callWithin(context.Background(), timeout, methodSetSaved, setSavedParams{IDs: ids, Saved: saved}, nil)
+}
+
+func (p *PlaylistClient) TopArtists(timeRange string) ([]entities.Artist, error) {
+	var artists []entities.Artist
+	err := p.c.call(context.Background(), methodTopArtists, topParams{TimeRange: timeRange}, &artists)
+	return artists, err
+}
+
+func (p *PlaylistClient) TopTracks(timeRange string) ([]entities.Track, error) {
+	var tracks []entities.Track
+	err := p.c.call(context.Background(), methodTopTracks, topParams{TimeRange: timeRange}, &tracks)
+	return tracks, err
+}
+
+func (p *PlaylistClient) Artists(ids []string) ([]entities.Artist, error) {
+	var artists []entities.Artist
+	err := p.c.call(context.Background(), methodArtists, artistsParams{IDs: ids}, &artists)
+	return artists, err
+}
+
+func (p *PlaylistClient) SearchTracks(query string, limit int) ([]entities.Track, error) {
+	var tracks []entities.Track
+	err := p.c.call(context.Background(), methodSearch, searchParams{Query: query, Limit: limit}, &tracks)
+	return tracks, err
+}
+
+func (p *PlaylistClient) CreatePlaylist(name, description string, public bool) (entities.Playlist, error) {
+	var playlist entities.Playlist
+	err := p.c.call(context.Background(), methodCreate, createParams{Name: name, Description: description, Public: public}, &playlist)
+	return playlist, err
+}
+
+// ExportPlaylist has the daemon fetch and write the playlist, and copies
+// the result to w.
+func (p *PlaylistClient) ExportPlaylist(playlist entities.Playlist, format playlistfile.Format, w io.Writer) error {
+	var r exportResult
+	timeout := p.c.tracksTimeout(playlist.TrackCount)
+	if err := p.c.callWithin(context.Background(), timeout, methodExport, exportParams{Playlist: playlist, Format: format}, &r); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, r.Data)
+	return err
+}
+
+// trackCount is how many tracks playlist id had when the playlists were
+// last fetched, or 0 when it was not among them.
+func (p *PlaylistClient) trackCount(id string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pl := range p.playlists {
+		if pl.ID == id {
+			return pl.TrackCount
+		}
+	}
+	return 0
+}
+
+func (p *PlaylistClient) remember(playlists []entities.Playlist) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.playlists = playlists
+}
@@ -0,0 +1,416 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+// refreshDelay gives Spotify time to apply a command before the state is
+// fetched again for subscribers.
+const refreshDelay = 300 * time.Millisecond
+
+// maxMessageSize bounds a single request line. Responses can be much
+// larger: a long playlist or its export is one line.
+const (
+	maxMessageSize  = 1 << 20
+	maxResponseSize = 64 << 20
+)
+
+type handler func(ctx context.Context, c *conn, params json.RawMessage) (any, error)
+
+// Server answers clients on behalf of the services it was created with.
+type Server struct {
+	playback  *service.PlaybackService
+	playlists *service.PlaylistService
+	store     *service.PlaybackStore
+	methods   map[string]handler
+
+	shutdown context.CancelFunc
+}
+
+func NewServer(playback *service.PlaybackService, playlists *service.PlaylistService, store *service.PlaybackStore) *Server {
+	s := &Server{playback: playback, playlists: playlists, store: store}
+	s.methods = map[string]handler{
+		methodShutdown: func(context.Context, *conn, json.RawMessage) (any, error) {
+			s.shutdown()
+			return nil, nil
+		},
+		methodState: func(ctx context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			var p stateParams
+			if err := decode(raw, &p); err != nil {
+				return nil, err
+			}
+			if p.Fresh {
+				state, err := s.store.Refresh(ctx)
+				return stateResult{State: state, Error: errorString(err)}, nil
+			}
+			state, err := s.store.Current()
+			return stateResult{State: state, Error: errorString(err)}, nil
+		},
+		methodSubscribe: func(_ context.Context, c *conn, _ json.RawMessage) (any, error) {
+			c.subscribe(s.store)
+			state, err := s.store.Current()
+			return stateResult{State: state, Error: errorString(err)}, nil
+		},
+		methodUnsubscribe: func(_ context.Context, c *conn, _ json.RawMessage) (any, error) {
+			c.unsubscribe()
+			return nil, nil
+		},
+
+		methodPlay: control(s, func(p playParams) error {
+			return s.playback.Play(p.TrackURI, p.ContextURI)
+		}),
+		methodPause:    control(s, func(struct{}) error { return s.playback.PausePlayback() }),
+		methodResume:   control(s, func(struct{}) error { return s.playback.ResumePlayback() }),
+		methodNext:     control(s, func(struct{}) error { return s.playback.NextTrack() }),
+		methodPrevious: control(s, func(struct{}) error { return s.playback.PreviousTrack() }),
+		methodSeek:     control(s, func(p seekParams) error { return s.playback.SeekTo(p.PositionMs) }),
+		methodVolume:   control(s, func(p volumeParams) error { return s.playback.SetVolume(p.Percent) }),
+		methodShuffle:  control(s, func(p shuffleParams) error { return s.playback.ToggleShufflePlayback(p.State) }),
+		methodRepeat:   control(s, func(p repeatParams) error { return s.playback.ToggleRepeatPlayback(p.State) }),
+		methodTransfer: control(s, func(p transferParams) error { return s.playback.TransferPlayback(p.DeviceID) }),
+		methodAddToQueue: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p urisParams) (any, error) { return nil, s.playback.AddToQueue(p.URIs) })
+		},
+		methodDevices: func(context.Context, *conn, json.RawMessage) (any, error) {
+			return s.playback.Devices()
+		},
+		methodQueue: func(context.Context, *conn, json.RawMessage) (any, error) {
+			return s.playback.UpNext()
+		},
+
+		methodPlaylists: func(context.Context, *conn, json.RawMessage) (any, error) {
+			return s.playlists.GetPlaylists()
+		},
+		methodCachedLists: func(context.Context, *conn, json.RawMessage) (any, error) {
+			playlists, ok := s.playlists.CachedPlaylists()
+			return cachedListsResult{Playlists: playlists, OK: ok}, nil
+		},
+		methodPlaylist: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p playlistParams) (any, error) { return s.playlists.Playlist(p.ID) })
+		},
+		methodTracks: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p tracksParams) (any, error) {
+				return s.playlists.GetPlaylistTracks(p.ID, p.SnapshotID)
+			})
+		},
+		methodCachedTrack: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p tracksParams) (any, error) {
+				tracks, fresh, ok := s.playlists.CachedPlaylistTracks(p.ID, p.SnapshotID)
+				return cachedTracksResult{Tracks: tracks, Fresh: fresh, OK: ok}, nil
+			})
+		},
+		methodAddTracks: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p addTracksParams) (any, error) {
+				return nil, s.playlists.AddTracks(p.PlaylistID, p.URIs)
+			})
+		},
+		methodRemove: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p removeParams) (any, error) {
+				snapshot, err := s.playlists.RemoveTracks(p.PlaylistID, p.SnapshotID, p.URIs)
+				return removeResult{SnapshotID: snapshot}, err
+			})
+		},
+		methodSaved: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p savedParams) (any, error) { return s.playlists.SavedTracks(p.IDs) })
+		},
+		methodSetSaved: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p setSavedParams) (any, error) { return nil, s.playlists.SetSaved(p.IDs, p.Saved) })
+		},
+		methodTopArtists: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p topParams) (any, error) { return s.playlists.TopArtists(p.TimeRange) })
+		},
+		methodTopTracks: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p topParams) (any, error) { return s.playlists.TopTracks(p.TimeRange) })
+		},
+		methodArtists: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p artistsParams) (any, error) { return s.playlists.Artists(p.IDs) })
+		},
+		methodCreate: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p createParams) (any, error) { return s.playlists.CreatePlaylist(p.Name, p.Description, p.Public) })
+		},
+		methodSearch: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p searchParams) (any, error) { return s.playlists.SearchTracks(p.Query, p.Limit) })
+		},
+		methodExport: func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+			return call(raw, func(p exportParams) (any, error) {
+				var buf bytes.Buffer
+				if err := s.playlists.ExportPlaylist(p.Playlist, p.Format, &buf); err != nil {
+					return nil, err
+				}
+				return exportResult{Data: buf.String()}, nil
+			})
+		},
+	}
+	return s
+}
+
+// control wraps a playback command, refreshing the state afterwards so
+// every subscriber sees its effect without waiting for the next poll.
+func control[P any](s *Server, action func(P) error) handler {
+	return func(_ context.Context, _ *conn, raw json.RawMessage) (any, error) {
+		return call(raw, func(p P) (any, error) {
+			if err := action(p); err != nil {
+				return nil, err
+			}
+			go func() {
+				time.Sleep(refreshDelay)
+				s.store.Refresh(context.Background())
+			}()
+			return nil, nil
+		})
+	}
+}
+
+// call decodes the parameters of a request and runs fn with them.
+func call[P any](raw json.RawMessage, fn func(P) (any, error)) (any, error) {
+	var p P
+	if err := decode(raw, &p); err != nil {
+		return nil, err
+	}
+	return fn(p)
+}
+
+// errInvalidParams marks a decoding failure, answered with codeInvalidParams.
+var errInvalidParams = errors.New("invalid params")
+
+func decode(raw json.RawMessage, v any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidParams, err)
+	}
+	return nil
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// Listen opens the socket at path, creating its directory. A socket left
+// behind by a daemon that did not exit cleanly is replaced; one that still
+// answers is an error.
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if c, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		c.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// Serve answers connections on ln until ctx is cancelled or a client asks
+// the daemon to shut down. It polls playback for subscribers meanwhile.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	ctx, s.shutdown = context.WithCancel(ctx)
+	defer s.shutdown()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.store.Run(ctx)
+	}()
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	var err error
+	for {
+		nc, acceptErr := ln.Accept()
+		if acceptErr != nil {
+			if ctx.Err() == nil {
+				err = acceptErr
+			}
+			break
+		}
+		c := &conn{conn: nc, enc: json.NewEncoder(nc)}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(ctx, c)
+		}()
+	}
+	s.shutdown()
+	wg.Wait()
+	return err
+}
+
+// conn is one client connection.
+type conn struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+	enc     *json.Encoder
+
+	mu        sync.Mutex
+	hello     bool
+	cancelSub func()
+}
+
+func (s *Server) serveConn(ctx context.Context, c *conn) {
+	// Requests end with their connection: nobody is left to answer.
+	ctx, cancel := context.WithCancel(ctx)
+	var requests sync.WaitGroup
+	defer func() {
+		cancel()
+		c.conn.Close()
+		requests.Wait()
+		c.unsubscribe()
+	}()
+	go func() {
+		<-ctx.Done()
+		c.conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(c.conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var m message
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			c.reply(nil, nil, &Error{Code: codeParse, Message: err.Error()})
+			continue
+		}
+		if m.Method == "" || m.ID == nil {
+			c.reply(m.ID, nil, &Error{Code: codeInvalidRequest, Message: "expected a request with a method and an id"})
+			continue
+		}
+
+		// The handshake is answered in order so no request can overtake it.
+		if m.Method == methodHello {
+			result, rpcErr := c.handshake(m.Params)
+			c.reply(m.ID, result, rpcErr)
+			continue
+		}
+		if !c.greeted() {
+			c.reply(m.ID, nil, &Error{Code: codeVersion, Message: "send " + methodHello + " first"})
+			continue
+		}
+		h, ok := s.methods[m.Method]
+		if !ok {
+			c.reply(m.ID, nil, &Error{Code: codeNoMethod, Message: "unknown method " + m.Method})
+			continue
+		}
+		requests.Add(1)
+		go func() {
+			defer requests.Done()
+			result, err := h(ctx, c, m.Params)
+			c.reply(m.ID, result, rpcError(err))
+		}()
+	}
+}
+
+func (c *conn) handshake(raw json.RawMessage) (any, *Error) {
+	var p helloParams
+	if err := decode(raw, &p); err != nil {
+		return nil, &Error{Code: codeInvalidParams, Message: err.Error()}
+	}
+	if p.Version != ProtocolVersion {
+		return nil, &Error{Code: codeVersion, Message: fmt.Sprintf("protocol version %d is not supported, the daemon speaks %d", p.Version, ProtocolVersion)}
+	}
+	c.mu.Lock()
+	c.hello = true
+	c.mu.Unlock()
+	return helloResult{Version: ProtocolVersion, PID: os.Getpid()}, nil
+}
+
+func (c *conn) greeted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hello
+}
+
+// subscribe forwards the store's changes to the client until unsubscribe.
+func (c *conn) subscribe(store *service.PlaybackStore) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancelSub != nil {
+		return
+	}
+	updates, cancel := store.Subscribe()
+	c.cancelSub = cancel
+	go func() {
+		for u := range updates {
+			c.notify(notifyChanged, stateResult{State: u.State, Error: errorString(u.Err)})
+		}
+	}()
+}
+
+func (c *conn) unsubscribe() {
+	c.mu.Lock()
+	cancel := c.cancelSub
+	c.cancelSub = nil
+	c.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (c *conn) reply(id *uint64, result any, rpcErr *Error) {
+	m := message{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			m.Error = &Error{Code: codeFailed, Message: err.Error()}
+		} else {
+			m.Result = data
+		}
+	}
+	c.write(m)
+}
+
+func (c *conn) notify(method string, params any) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	c.write(message{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+// write sends a message. A failed write closes the connection, which ends
+// its read loop.
+func (c *conn) write(m message) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.enc.Encode(m); err != nil {
+		c.conn.Close()
+	}
+}
+
+func rpcError(err error) *Error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errInvalidParams):
+		return &Error{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return &Error{Code: codeFailed, Message: err.Error()}
+}
@@ -1,5 +1,7 @@
 package entities
 
+import "time"
+
 type PlaybackState struct {
 	IsPlaying    bool   `json:"is_playing"`
 	ProgressMs   int    `json:"progress_ms"`
@@ -7,6 +9,15 @@ type PlaybackState struct {
 	Device       Device `json:"device"`
 	ShuffleState bool   `json:"shuffle_state"`
 	RepeatState  string `json:"repeat_state"`
+	// Context is what playback started from; nil for single tracks.
+	Context *PlaybackContext `json:"context"`
+}
+
+// PlaybackContext is a playlist, album, artist or the Liked Songs
+// collection being played.
+type PlaybackContext struct {
+	Type string `json:"type"`
+	URI  string `json:"uri"`
 }
 
 type Device struct {
@@ -17,3 +28,11 @@ type Device struct {
 	VolumePercent int    `json:"volume_percent"`
 	IsMuted       bool   `json:"is_muted"`
 }
+
+// PlayHistory is a play reported by Spotify's recently played endpoint.
+// PlayedAt is roughly when the track stopped playing.
+type PlayHistory struct {
+	Track    Track            `json:"track"`
+	PlayedAt time.Time        `json:"played_at"`
+	Context  *PlaybackContext `json:"context"`
+}
@@ -6,7 +6,16 @@ type Playlist struct {
 	Description string
 	ImageURL    string
 	OwnerName   string
+	OwnerID     string
+	Public      bool
 	TrackCount  int
 	URI         string
 	Type        string
+	SnapshotID  string
+}
+
+// User is the account signed in.
+type User struct {
+	ID   string
+	Name string
 }
@@ -4,20 +4,42 @@ type Track struct {
 	ID         string   `json:"id"`
 	Name       string   `json:"name"`
 	DurationMs int      `json:"duration_ms"`
+	Popularity int      `json:"popularity"`
 	Artists    []Artist `json:"artists"`
 	Album      Album    `json:"album"`
+	AddedAt    string   `json:"added_at,omitempty"` // RFC 3339; only set for playlist and liked tracks
+	// URI and ISRC are only set for playlist tracks. URI differs from
+	// spotify:track:<ID> for episodes and local files.
+	URI  string `json:"uri,omitempty"`
+	ISRC string `json:"isrc,omitempty"`
 }
 
 type Artist struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 	URI  string `json:"uri"`
+	// Genres is only filled in by the full artist endpoints, not where an
+	// artist is listed on a track.
+	Genres []string `json:"genres,omitempty"`
 }
 
 type Album struct {
 	ID     string  `json:"id"`
 	Name   string  `json:"name"`
 	Images []Image `json:"images"`
+	// URI, Artists and AddedAt are only set for the user's saved albums.
+	URI     string   `json:"uri,omitempty"`
+	Artists []Artist `json:"artists,omitempty"`
+	AddedAt string   `json:"added_at,omitempty"`
+}
+
+// Show is a podcast the user follows.
+type Show struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Publisher string `json:"publisher"`
+	URI       string `json:"uri"`
+	AddedAt   string `json:"added_at,omitempty"`
 }
 
 type Image struct {
@@ -0,0 +1,128 @@
+package history
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+const (
+	// minListened keeps tracks flicked past on the way to another out of
+	// the history.
+	minListened = 5 * time.Second
+	// endMargin is how close to its end a track has to get to count as
+	// played through rather than skipped. Playback is polled, so the last
+	// position seen is a few seconds short of where it really stopped.
+	endMargin = 10 * time.Second
+)
+
+// Recorder turns a stream of playback changes into history entries.
+type Recorder struct {
+	store *Store
+	log   *log.Logger
+
+	mu      sync.Mutex
+	current *listen
+}
+
+// listen is the play in progress.
+type listen struct {
+	entry Entry
+	state *entities.PlaybackState // last state seen
+	seen  time.Time               // when state was seen
+}
+
+// NewRecorder writes to store, reporting write failures to logger.
+func NewRecorder(store *Store, logger *log.Logger) *Recorder {
+	return &Recorder{store: store, log: logger}
+}
+
+// Watch records plays from a stream of changes until it is closed.
+func (r *Recorder) Watch(updates <-chan service.PlaybackUpdate) {
+	go func() {
+		for u := range updates {
+			if u.Err == nil {
+				r.Observe(u.State, time.Now())
+			}
+		}
+	}()
+}
+
+// Observe takes the state seen at now. When it ends the play in progress,
+// that play is written to the history.
+func (r *Recorder) Observe(state *entities.PlaybackState, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if state != nil && state.Track.ID == "" {
+		state = nil
+	}
+	if c := r.current; c != nil {
+		position := c.advance(now)
+		restarted := state != nil && state.Track.ID == c.entry.Track.ID &&
+			position >= c.entry.Track.DurationMs-int(endMargin.Milliseconds()) &&
+			state.ProgressMs < position-int(endMargin.Milliseconds())
+		if state == nil || state.Track.ID != c.entry.Track.ID || restarted {
+			c.entry.Skipped = position < c.entry.Track.DurationMs-int(endMargin.Milliseconds())
+			r.write(c.entry)
+			r.current = nil
+		} else {
+			c.state, c.seen = state, now
+			if state.Device.Name != "" {
+				c.entry.Device = state.Device.Name
+			}
+		}
+	}
+
+	if r.current == nil && state != nil {
+		r.current = &listen{
+			entry: Entry{
+				Track:     state.Track,
+				Device:    state.Device.Name,
+				StartedAt: now.Add(-time.Duration(state.ProgressMs) * time.Millisecond).UTC(),
+			},
+			state: state,
+			seen:  now,
+		}
+		if state.Context != nil {
+			r.current.entry.ContextURI = state.Context.URI
+		}
+	}
+}
+
+// Close records the play in progress, if it has gone on long enough. It is
+// not counted as skipped: it may well carry on after the process exits.
+func (r *Recorder) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.current != nil {
+		r.current.advance(time.Now())
+		r.write(r.current.entry)
+		r.current = nil
+	}
+}
+
+// advance counts the time since the last state as listened, if it was
+// playing, and returns the position reached.
+func (l *listen) advance(now time.Time) int {
+	position := l.state.ProgressMs
+	if l.state.IsPlaying {
+		elapsed := int(now.Sub(l.seen).Milliseconds())
+		position += elapsed
+		l.entry.ListenedMs = min(l.entry.ListenedMs+elapsed, l.entry.Track.DurationMs)
+	}
+	l.seen = now
+	return min(position, l.entry.Track.DurationMs)
+}
+
+func (r *Recorder) write(e Entry) {
+	if e.ListenedMs < int(minListened.Milliseconds()) {
+		return
+	}
+	if err := r.store.Append(e); err != nil {
+		r.log.Printf("history: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package history keeps a local record of what was listened to, going
+// further back than the 50 plays Spotify remembers.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// maxLineSize bounds one entry in the history file.
+const maxLineSize = 1024 * 1024
+
+// Entry is one play of a track.
+type Entry struct {
+	Track      entities.Track `json:"track"`
+	ContextURI string         `json:"context_uri,omitempty"`
+	Device     string         `json:"device,omitempty"`
+	StartedAt  time.Time      `json:"started_at"`
+	ListenedMs int            `json:"listened_ms"`
+	// Skipped is set when the track was left before its end.
+	Skipped bool `json:"skipped"`
+	// Imported entries were backfilled from Spotify, which only reports
+	// that a track was played: the start time is worked out from its
+	// length and the whole track counts as listened.
+	Imported bool `json:"imported,omitempty"`
+}
+
+// Matches reports whether every word of query appears in the track name,
+// artists, album or context, ignoring case.
+func (e Entry) Matches(query string) bool {
+	parts := []string{e.Track.Name, e.Track.Album.Name, e.ContextURI}
+	for _, a := range e.Track.Artists {
+		parts = append(parts, a.Name)
+	}
+	text := strings.ToLower(strings.Join(parts, " "))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// Store is the history file: one JSON entry per line, only ever appended
+// to, so the recorder and an import can write to it at the same time.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path is the location of the history file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append adds entries to the end of the file.
+func (s *Store) Append(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var buf []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	// One write per call keeps concurrent writers' lines whole.
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return f.Close()
+}
+
+// Load returns every entry, oldest first. A missing file is an empty
+// history; lines that cannot be read, such as one cut short by a crash,
+// are skipped.
+func (s *Store) Load() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Track.ID == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	// Imports append older plays after newer ones.
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return entries, nil
+}
+
+// Recent returns up to n entries, newest first.
+func (s *Store) Recent(n int) ([]Entry, error) {
+	entries, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	entries = entries[max(len(entries)-n, 0):]
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// Import adds the plays Spotify reports that are not in the history yet,
+// returning how many were added. A play counts as recorded when an entry
+// for the same track started within one track length of it.
+func (s *Store) Import(plays []entities.PlayHistory) (int, error) {
+	existing, err := s.Load()
+	if err != nil {
+		return 0, err
+	}
+	recorded := map[string][]time.Time{}
+	for _, e := range existing {
+		recorded[e.Track.ID] = append(recorded[e.Track.ID], e.StartedAt)
+	}
+
+	var added []Entry
+	for _, p := range plays {
+		if p.Track.ID == "" {
+			continue
+		}
+		length := time.Duration(p.Track.DurationMs) * time.Millisecond
+		started := p.PlayedAt.Add(-length)
+		if slices.ContainsFunc(recorded[p.Track.ID], func(t time.Time) bool {
+			return t.Sub(started).Abs() < length
+		}) {
+			continue
+		}
+		e := Entry{
+			Track:      p.Track,
+			StartedAt:  started.UTC(),
+			ListenedMs: p.Track.DurationMs,
+			Imported:   true,
+		}
+		if p.Context != nil {
+			e.ContextURI = p.Context.URI
+		}
+		added = append(added, e)
+		recorded[p.Track.ID] = append(recorded[p.Track.ID], started)
+	}
+	return len(added), s.Append(added...)
+}
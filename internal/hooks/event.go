@@ -0,0 +1,104 @@
+package hooks
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/art"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// Event names a moment hooks can run on. They match the keys of the
+// [hooks] config section.
+type Event string
+
+const (
+	TrackChanged     Event = "track_changed"
+	Paused           Event = "paused"
+	Resumed          Event = "resumed"
+	DeviceChanged    Event = "device_changed"
+	PlaylistSelected Event = "playlist_selected"
+)
+
+// Payload is passed to hooks as JSON on stdin.
+type Payload struct {
+	Event    Event                   `json:"event"`
+	Time     time.Time               `json:"time"`
+	Playback *entities.PlaybackState `json:"playback,omitempty"`
+	Playlist *Playlist               `json:"playlist,omitempty"`
+}
+
+// Playlist is the playlist opened in a playlist_selected event.
+type Playlist struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	Owner      string `json:"owner"`
+	TrackCount int    `json:"track_count"`
+}
+
+// playbackEvents works out what happened between two playback states. A
+// new track is only reported as changed, even if it also started playing
+// or moved device.
+func playbackEvents(prev, next *entities.PlaybackState) []Event {
+	if next == nil || next.Track.ID == "" {
+		if prev != nil && prev.IsPlaying {
+			return []Event{Paused}
+		}
+		return nil
+	}
+	if prev == nil || prev.Track.ID != next.Track.ID {
+		return []Event{TrackChanged}
+	}
+
+	var events []Event
+	switch {
+	case prev.IsPlaying && !next.IsPlaying:
+		events = append(events, Paused)
+	case !prev.IsPlaying && next.IsPlaying:
+		events = append(events, Resumed)
+	}
+	if prev.Device.ID != "" && next.Device.ID != "" && prev.Device.ID != next.Device.ID {
+		events = append(events, DeviceChanged)
+	}
+	return events
+}
+
+// env describes the payload as SPOTIFY_* environment variables. Fields
+// that do not apply are left out.
+func (p Payload) env() []string {
+	vars := []string{"SPOTIFY_EVENT=" + string(p.Event)}
+	add := func(name, value string) {
+		vars = append(vars, "SPOTIFY_"+name+"="+value)
+	}
+
+	if s := p.Playback; s != nil && s.Track.ID != "" {
+		t := s.Track
+		artists := make([]string, len(t.Artists))
+		for i, a := range t.Artists {
+			artists[i] = a.Name
+		}
+		add("TRACK", t.Name)
+		add("TRACK_ID", t.ID)
+		add("TRACK_URI", "spotify:track:"+t.ID)
+		add("ARTISTS", strings.Join(artists, ", "))
+		add("ALBUM", t.Album.Name)
+		add("ART_URL", art.PickImage(t.Album.Images, 300))
+		add("DURATION_MS", strconv.Itoa(t.DurationMs))
+		add("PROGRESS_MS", strconv.Itoa(s.ProgressMs))
+		add("IS_PLAYING", strconv.FormatBool(s.IsPlaying))
+		add("DEVICE", s.Device.Name)
+		add("VOLUME", strconv.Itoa(s.Device.VolumePercent))
+		if s.Context != nil {
+			add("CONTEXT_URI", s.Context.URI)
+		}
+	}
+	if pl := p.Playlist; pl != nil {
+		add("PLAYLIST", pl.Name)
+		add("PLAYLIST_ID", pl.ID)
+		add("PLAYLIST_URI", pl.URI)
+		add("PLAYLIST_OWNER", pl.Owner)
+	}
+	return vars
+}
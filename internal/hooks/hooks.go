@@ -0,0 +1,182 @@
+// Package hooks runs user commands when playback changes, e.g. to update a
+// status file, post to a chat or set a terminal title.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+// maxLoggedOutput is how much of a failed hook's output is logged.
+const maxLoggedOutput = 2048
+
+// Runner runs the commands configured for each event through sh -c.
+type Runner struct {
+	commands map[Event][]string
+	timeout  time.Duration
+	slots    chan struct{} // one per command allowed to run at once
+	logFile  *os.File
+	log      *log.Logger
+
+	// mu orders Fire against Close, so no hook starts, or logs, once Close
+	// has begun waiting.
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewRunner returns a runner for the configured hooks, appending failures
+// to the configured log file. It returns nil when no hooks are configured;
+// a nil Runner ignores every event.
+func NewRunner(cfg config.HooksConfig) (*Runner, error) {
+	commands := map[Event][]string{
+		TrackChanged:     cfg.TrackChanged,
+		Paused:           cfg.Paused,
+		Resumed:          cfg.Resumed,
+		DeviceChanged:    cfg.DeviceChanged,
+		PlaylistSelected: cfg.PlaylistSelected,
+	}
+	empty := true
+	for _, c := range commands {
+		empty = empty && len(c) == 0
+	}
+	if empty {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.LogFile), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create hook log directory: %w", err)
+	}
+	logFile, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hook log: %w", err)
+	}
+	return &Runner{
+		commands: commands,
+		timeout:  cfg.Timeout.Duration,
+		slots:    make(chan struct{}, cfg.MaxConcurrent),
+		logFile:  logFile,
+		log:      log.New(logFile, "", log.LstdFlags),
+	}, nil
+}
+
+// Handles reports whether any command runs on event.
+func (r *Runner) Handles(event Event) bool {
+	return r != nil && len(r.commands[event]) > 0
+}
+
+// Watch fires playback events for a stream of changes until it is closed.
+// The first state seen counts as a track change.
+func (r *Runner) Watch(updates <-chan service.PlaybackUpdate) {
+	if r == nil {
+		return
+	}
+	go func() {
+		var prev *entities.PlaybackState
+		for u := range updates {
+			if u.Err != nil {
+				continue
+			}
+			for _, event := range playbackEvents(prev, u.State) {
+				r.Fire(Payload{Event: event, Playback: u.State})
+			}
+			prev = u.State
+		}
+	}()
+}
+
+// Fire starts the commands for an event in the background. Commands wait
+// for a free slot for up to the timeout; after that they are dropped and
+// logged, so a stuck hook cannot pile up work. Events fired after Close
+// are ignored.
+func (r *Runner) Fire(p Payload) {
+	if !r.Handles(p.Event) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	if p.Time.IsZero() {
+		p.Time = time.Now()
+	}
+	stdin, err := json.Marshal(p)
+	if err != nil {
+		r.log.Printf("hooks: %s: %v", p.Event, err)
+		return
+	}
+	env := append(os.Environ(), p.env()...)
+
+	for _, command := range r.commands[p.Event] {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			select {
+			case r.slots <- struct{}{}:
+				defer func() { <-r.slots }()
+			case <-time.After(r.timeout):
+				r.log.Printf("hooks: %s: dropped %q, %d hooks already running", p.Event, command, cap(r.slots))
+				return
+			}
+			r.run(p.Event, command, env, stdin)
+		}()
+	}
+}
+
+func (r *Runner) run(event Event, command string, env []string, stdin []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = env
+	cmd.Stdin = bytes.NewReader(stdin)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Background processes a hook leaves behind must not hold it open.
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	if err == nil {
+		return
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", r.timeout)
+	}
+	out := strings.TrimSpace(output.String())
+	if len(out) > maxLoggedOutput {
+		out = out[:maxLoggedOutput] + "…"
+	}
+	r.log.Printf("hooks: %s: %q failed after %s: %v", event, command, time.Since(start).Round(time.Millisecond), err)
+	if out != "" {
+		r.log.Printf("hooks: %s: output:\n%s", event, out)
+	}
+}
+
+// Close waits for every started hook to finish and closes the log.
+func (r *Runner) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+	r.wg.Wait()
+	return r.logFile.Close()
+}
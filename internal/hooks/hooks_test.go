@@ -0,0 +1,125 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+func newTestRunner(t *testing.T, command string, timeout time.Duration) (*Runner, string) {
+	t.Helper()
+	dir := t.TempDir()
+	r, err := NewRunner(config.HooksConfig{
+		Timeout:       config.Duration{Duration: timeout},
+		MaxConcurrent: 1,
+		LogFile:       filepath.Join(dir, "hooks.log"),
+		TrackChanged:  []string{command},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, dir
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFirePassesPayload(t *testing.T) {
+	r, dir := newTestRunner(t, `env | grep ^SPOTIFY_ | sort > "$OUT/env"; cat > "$OUT/stdin"`, 5*time.Second)
+	t.Setenv("OUT", dir)
+
+	state := &entities.PlaybackState{
+		IsPlaying:  true,
+		ProgressMs: 1500,
+		Track: entities.Track{
+			ID:         "abc",
+			Name:       "Song",
+			DurationMs: 200_000,
+			Artists:    []entities.Artist{{Name: "A"}, {Name: "B"}},
+			Album:      entities.Album{Name: "Album"},
+		},
+		Device:  entities.Device{Name: "Desk", VolumePercent: 40},
+		Context: &entities.PlaybackContext{URI: "spotify:playlist:xyz"},
+	}
+	r.Fire(Payload{Event: TrackChanged, Playback: state})
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	env := readFile(t, filepath.Join(dir, "env"))
+	for _, want := range []string{
+		"SPOTIFY_EVENT=track_changed",
+		"SPOTIFY_TRACK=Song",
+		"SPOTIFY_TRACK_URI=spotify:track:abc",
+		"SPOTIFY_ARTISTS=A, B",
+		"SPOTIFY_ALBUM=Album",
+		"SPOTIFY_DURATION_MS=200000",
+		"SPOTIFY_PROGRESS_MS=1500",
+		"SPOTIFY_IS_PLAYING=true",
+		"SPOTIFY_DEVICE=Desk",
+		"SPOTIFY_VOLUME=40",
+		"SPOTIFY_CONTEXT_URI=spotify:playlist:xyz",
+	} {
+		if !strings.Contains(env, want+"\n") {
+			t.Errorf("environment lacks %s:\n%s", want, env)
+		}
+	}
+	if strings.Contains(env, "SPOTIFY_PLAYLIST") {
+		t.Errorf("playlist variables set for a track change:\n%s", env)
+	}
+
+	var got Payload
+	if err := json.Unmarshal([]byte(readFile(t, filepath.Join(dir, "stdin"))), &got); err != nil {
+		t.Fatalf("stdin is not a payload: %v", err)
+	}
+	if got.Event != TrackChanged || got.Time.IsZero() || got.Playback == nil || got.Playback.Track.ID != "abc" {
+		t.Errorf("stdin = %+v", got)
+	}
+}
+
+func TestFireDropsWithoutASlot(t *testing.T) {
+	r, dir := newTestRunner(t, `touch "$OUT/ran"`, 100*time.Millisecond)
+	t.Setenv("OUT", dir)
+
+	// The only slot is taken by a hook that outlasts the timeout.
+	r.slots <- struct{}{}
+	r.Fire(Payload{Event: TrackChanged})
+	r.wg.Wait()
+	<-r.slots
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "ran")); err == nil {
+		t.Error("the hook ran without a slot")
+	}
+	if log := readFile(t, filepath.Join(dir, "hooks.log")); !strings.Contains(log, "dropped") {
+		t.Errorf("log = %q, want the dropped hook", log)
+	}
+}
+
+func TestFireAfterClose(t *testing.T) {
+	r, dir := newTestRunner(t, `touch "$OUT/ran"`, 5*time.Second)
+	t.Setenv("OUT", dir)
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r.Fire(Payload{Event: TrackChanged})
+	r.wg.Wait()
+	if _, err := os.Stat(filepath.Join(dir, "ran")); err == nil {
+		t.Error("a hook ran after Close")
+	}
+}
@@ -0,0 +1,212 @@
+// Package importer brings playlist files from other services into Spotify:
+// each entry is looked up in the catalog, the results are scored against
+// it, and the tracks picked are added to a playlist in batches.
+package importer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+)
+
+const (
+	// Confident is the score from which the best candidate is taken
+	// without review.
+	Confident = 0.85
+	// Plausible is the score from which the best candidate is picked by
+	// default when the entry is reviewed; below it nothing is.
+	Plausible = 0.5
+	// minScore is the lowest score still offered as a candidate.
+	minScore = 0.3
+	// maxCandidates is how many candidates an entry keeps.
+	maxCandidates = 5
+	// searchLimit is how many results each search asks for.
+	searchLimit = 10
+	// BatchSize is the most tracks Spotify adds to a playlist at once.
+	BatchSize = 100
+)
+
+// Catalog is where tracks are searched, such as service.Playlists.
+type Catalog interface {
+	SearchTracks(query string, limit int) ([]entities.Track, error)
+}
+
+// Target is where the matched tracks go, such as service.Playlists.
+type Target interface {
+	CreatePlaylist(name, description string, public bool) (entities.Playlist, error)
+	AddTracks(playlistID string, uris []string) error
+}
+
+// Candidate is a catalog track that may be the one an entry describes.
+type Candidate struct {
+	Track entities.Track
+	Score float64
+}
+
+// Match is an entry of the file with its candidates, best first, and the
+// one picked: an index into Candidates, or -1 to leave the entry out.
+type Match struct {
+	Entry      playlistfile.Entry
+	Candidates []Candidate
+	Choice     int
+}
+
+// Chosen returns the candidate picked, if any.
+func (m Match) Chosen() (Candidate, bool) {
+	if m.Choice < 0 || m.Choice >= len(m.Candidates) {
+		return Candidate{}, false
+	}
+	return m.Candidates[m.Choice], true
+}
+
+// NeedsReview reports whether the match is not certain enough to take
+// without asking: its best candidate scores below Confident, or there is
+// none.
+func (m Match) NeedsReview() bool {
+	return len(m.Candidates) == 0 || m.Candidates[0].Score < Confident
+}
+
+// Find searches the catalog for each entry, calling progress after each.
+// An entry that already names a Spotify track is taken as is. It stops at
+// the first failed search, or when ctx is done, and returns the matches so
+// far.
+func Find(ctx context.Context, catalog Catalog, entries []playlistfile.Entry, progress func(done, total int)) ([]Match, error) {
+	matches := make([]Match, 0, len(entries))
+	for i, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return matches, err
+		}
+		m, err := find(catalog, e)
+		if err != nil {
+			return matches, fmt.Errorf("failed to search for %s: %w", Describe(e), err)
+		}
+		matches = append(matches, m)
+		if progress != nil {
+			progress(i+1, len(entries))
+		}
+	}
+	return matches, nil
+}
+
+func find(catalog Catalog, e playlistfile.Entry) (Match, error) {
+	if id, ok := strings.CutPrefix(e.URI, "spotify:track:"); ok && id != "" {
+		t := entities.Track{ID: id, URI: e.URI, Name: e.Title, DurationMs: e.DurationMs, Album: entities.Album{Name: e.Album}}
+		for _, a := range e.Artists {
+			t.Artists = append(t.Artists, entities.Artist{Name: a})
+		}
+		return Match{Entry: e, Candidates: []Candidate{{Track: t, Score: 1}}}, nil
+	}
+
+	seen := map[string]bool{}
+	var candidates []Candidate
+	for _, query := range queries(e) {
+		tracks, err := catalog.SearchTracks(query, searchLimit)
+		if err != nil {
+			return Match{}, err
+		}
+		for _, t := range tracks {
+			if t.ID == "" || seen[t.ID] {
+				continue
+			}
+			seen[t.ID] = true
+			if score := Score(e, t); score >= minScore {
+				candidates = append(candidates, Candidate{Track: t, Score: score})
+			}
+		}
+		slices.SortStableFunc(candidates, func(a, b Candidate) int { return cmp.Compare(b.Score, a.Score) })
+		// Broader searches only help while nothing is certain.
+		if len(candidates) > 0 && candidates[0].Score >= Confident {
+			break
+		}
+	}
+
+	m := Match{Entry: e, Candidates: candidates[:min(len(candidates), maxCandidates)], Choice: -1}
+	if len(m.Candidates) > 0 && m.Candidates[0].Score >= Plausible {
+		m.Choice = 0
+	}
+	return m, nil
+}
+
+// queries are the searches for an entry, most precise first: by ISRC,
+// by title and artist fields, then as plain words.
+func queries(e playlistfile.Entry) []string {
+	var out []string
+	if e.ISRC != "" {
+		out = append(out, "isrc:"+e.ISRC)
+	}
+	title := strings.TrimSpace(bareTitle(e.Title))
+	if title == "" {
+		title = e.Title
+	}
+	if title == "" {
+		return out
+	}
+	if len(e.Artists) > 0 {
+		out = append(out, fmt.Sprintf("track:%s artist:%s", title, e.Artists[0]))
+		out = append(out, e.Artists[0]+" "+title)
+	} else {
+		out = append(out, title)
+	}
+	return out
+}
+
+// URIs returns the tracks picked, in the order of the file.
+func URIs(matches []Match) []string {
+	var uris []string
+	for _, m := range matches {
+		if c, ok := m.Chosen(); ok {
+			uri := c.Track.URI
+			if uri == "" {
+				uri = "spotify:track:" + c.Track.ID
+			}
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// Add appends uris to a playlist BatchSize at a time, calling progress
+// after each batch. On failure it returns how many were added.
+func Add(target Target, playlistID string, uris []string, progress func(added, total int)) (int, error) {
+	for start := 0; start < len(uris); start += BatchSize {
+		end := min(start+BatchSize, len(uris))
+		if err := target.AddTracks(playlistID, uris[start:end]); err != nil {
+			return start, err
+		}
+		if progress != nil {
+			progress(end, len(uris))
+		}
+	}
+	return len(uris), nil
+}
+
+// Into adds the tracks picked to playlist, or to a new private playlist
+// called name when playlist is nil. It returns the playlist and how many
+// tracks were added to it.
+func Into(target Target, playlist *entities.Playlist, name string, matches []Match, progress func(added, total int)) (entities.Playlist, int, error) {
+	var p entities.Playlist
+	if playlist != nil {
+		p = *playlist
+	} else {
+		created, err := target.CreatePlaylist(name, "Imported with spotify-tui", false)
+		if err != nil {
+			return p, 0, fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		p = created
+	}
+	added, err := Add(target, p.ID, URIs(matches), progress)
+	return p, added, err
+}
+
+// Describe is how an entry is shown: "artist - title".
+func Describe(e playlistfile.Entry) string {
+	if len(e.Artists) == 0 {
+		return e.Title
+	}
+	return e.Artist() + " - " + e.Title
+}
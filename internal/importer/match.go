@@ -0,0 +1,181 @@
+package importer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+)
+
+// How much each part of an entry counts towards its score. Parts the
+// entry does not have are left out and the rest weighed up to 1.
+const (
+	titleWeight    = 0.55
+	artistWeight   = 0.3
+	durationWeight = 0.15
+)
+
+// Lengths within durationSlack of each other score fully; the score falls
+// to 0 at durationLimit apart.
+const (
+	durationSlack = 2000
+	durationLimit = 20000
+)
+
+// Score rates how likely t is the track e describes, from 0 to 1. A shared
+// ISRC is a certain match. Otherwise the title, artists and length are
+// compared, ignoring case, punctuation and additions such as "(feat. …)"
+// or "- Remastered 2011".
+func Score(e playlistfile.Entry, t entities.Track) float64 {
+	if e.ISRC != "" && strings.EqualFold(e.ISRC, t.ISRC) {
+		return 1
+	}
+
+	score := titleWeight * titleSimilarity(e.Title, t.Name)
+	weight := titleWeight
+	if len(e.Artists) > 0 {
+		score += artistWeight * artistSimilarity(e.Artists, t.Artists)
+		weight += artistWeight
+	}
+	if e.DurationMs > 0 && t.DurationMs > 0 {
+		diff := abs(e.DurationMs - t.DurationMs)
+		d := 1 - float64(max(diff-durationSlack, 0))/float64(durationLimit-durationSlack)
+		score += durationWeight * max(d, 0)
+		weight += durationWeight
+	}
+	return score / weight
+}
+
+// titleSimilarity compares titles whole and with their additions dropped,
+// and keeps the better.
+func titleSimilarity(a, b string) float64 {
+	return max(similarity(normalize(a), normalize(b)), similarity(normalize(bareTitle(a)), normalize(bareTitle(b))))
+}
+
+// artistSimilarity compares the artists as a whole and the file's first
+// artist with each of the track's, and keeps the best. Files often list
+// only the main artist, or all of them in one field.
+func artistSimilarity(names []string, artists []entities.Artist) float64 {
+	if len(artists) == 0 {
+		return 0
+	}
+	theirs := make([]string, len(artists))
+	for i, a := range artists {
+		theirs[i] = a.Name
+	}
+	best := similarity(normalize(strings.Join(names, " ")), normalize(strings.Join(theirs, " ")))
+	for _, name := range theirs {
+		best = max(best, similarity(normalize(names[0]), normalize(name)))
+	}
+	return best
+}
+
+// bareTitle drops bracketed parts and anything after a spaced dash, where
+// featured artists, versions and remaster notes go.
+func bareTitle(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch r {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth = max(depth-1, 0)
+		default:
+			if depth == 0 {
+				b.WriteRune(r)
+			}
+		}
+	}
+	out := b.String()
+	for _, sep := range []string{" - ", " – ", " — "} {
+		out, _, _ = strings.Cut(out, sep)
+	}
+	// Lowercasing keeps byte offsets for all but a few scripts.
+	if lower := strings.ToLower(out); len(lower) == len(out) {
+		for _, sep := range []string{" feat. ", " ft. ", " featuring "} {
+			if i := strings.Index(lower, sep); i >= 0 {
+				out, lower = out[:i], lower[:i]
+			}
+		}
+	}
+	return out
+}
+
+// normalize lowercases s, spells out "&", drops a leading "the" and
+// everything but letters and digits, and collapses spaces.
+func normalize(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), "&", " and ")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return ' '
+		}
+		return -1
+	}, s)
+	return strings.TrimPrefix(strings.Join(strings.Fields(s), " "), "the ")
+}
+
+// similarity is the better of an edit distance ratio, which forgives
+// typos, and the share of words in common, which forgives word order.
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if a == "" || b == "" {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	edit := 1 - float64(levenshtein(ra, rb))/float64(max(len(ra), len(rb)))
+	return max(edit, wordOverlap(strings.Fields(a), strings.Fields(b)))
+}
+
+// wordOverlap is the Dice coefficient of the two sets of words.
+func wordOverlap(a, b []string) float64 {
+	sa, sb := wordSet(a), wordSet(b)
+	common := 0
+	for w := range sb {
+		if sa[w] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(sa)+len(sb))
+}
+
+func wordSet(words []string) map[string]bool {
+	out := make(map[string]bool, len(words))
+	for _, w := range words {
+		out[w] = true
+	}
+	return out
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
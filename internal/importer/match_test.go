@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+)
+
+func catalogTrack(name string, ms int, artists ...string) entities.Track {
+	t := entities.Track{ID: "id", Name: name, DurationMs: ms}
+	for _, a := range artists {
+		t.Artists = append(t.Artists, entities.Artist{Name: a})
+	}
+	return t
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry playlistfile.Entry
+		track entities.Track
+		min   float64 // the score is at least this
+		max   float64 // and at most this
+	}{
+		{
+			name:  "shared ISRC is certain whatever the names",
+			entry: playlistfile.Entry{Title: "Something else", Artists: []string{"Nobody"}, ISRC: "gbaym0000001"},
+			track: entities.Track{Name: "Song", ISRC: "GBAYM0000001"},
+			min:   1, max: 1,
+		},
+		{
+			name:  "exact match",
+			entry: playlistfile.Entry{Title: "Hey Jude", Artists: []string{"The Beatles"}, DurationMs: 431_000},
+			track: catalogTrack("Hey Jude", 431_000, "The Beatles"),
+			min:   1, max: 1,
+		},
+		{
+			name:  "remaster note dropped",
+			entry: playlistfile.Entry{Title: "Hey Jude", Artists: []string{"The Beatles"}},
+			track: catalogTrack("Hey Jude - Remastered 2015", 0, "The Beatles"),
+			min:   1, max: 1,
+		},
+		{
+			name:  "featured artist in brackets dropped",
+			entry: playlistfile.Entry{Title: "Stay (feat. Justin Bieber)", Artists: []string{"The Kid LAROI"}},
+			track: catalogTrack("STAY", 0, "The Kid LAROI", "Justin Bieber"),
+			min:   1, max: 1,
+		},
+		{
+			name:  "featured artist after feat. dropped",
+			entry: playlistfile.Entry{Title: "Lean On feat. MØ", Artists: []string{"Major Lazer"}},
+			track: catalogTrack("Lean On", 0, "Major Lazer", "MØ", "DJ Snake"),
+			min:   1, max: 1,
+		},
+		{
+			name:  "artists listed in another order",
+			entry: playlistfile.Entry{Title: "Under Pressure", Artists: []string{"David Bowie, Queen"}},
+			track: catalogTrack("Under Pressure", 0, "Queen", "David Bowie"),
+			min:   1, max: 1,
+		},
+		{
+			name:  "ampersand and punctuation",
+			entry: playlistfile.Entry{Title: "Rock & Roll", Artists: []string{"Led Zeppelin"}},
+			track: catalogTrack("Rock and Roll!", 0, "Led Zeppelin"),
+			min:   1, max: 1,
+		},
+		{
+			name:  "length within the slack",
+			entry: playlistfile.Entry{Title: "Song", Artists: []string{"Band"}, DurationMs: 200_000},
+			track: catalogTrack("Song", 201_900, "Band"),
+			min:   1, max: 1,
+		},
+		{
+			name:  "length halfway to the limit",
+			entry: playlistfile.Entry{Title: "Song", Artists: []string{"Band"}, DurationMs: 200_000},
+			track: catalogTrack("Song", 211_000, "Band"),
+			min:   0.92, max: 0.93,
+		},
+		{
+			name:  "length far off counts nothing",
+			entry: playlistfile.Entry{Title: "Song", Artists: []string{"Band"}, DurationMs: 200_000},
+			track: catalogTrack("Song", 400_000, "Band"),
+			min:   0.85, max: 0.85,
+		},
+		{
+			name:  "same title by someone else is uncertain",
+			entry: playlistfile.Entry{Title: "Hallelujah", Artists: []string{"Jeff Buckley"}},
+			track: catalogTrack("Hallelujah", 0, "Leonard Cohen"),
+			min:   Plausible, max: Confident,
+		},
+		{
+			name:  "another song entirely",
+			entry: playlistfile.Entry{Title: "Bohemian Rhapsody", Artists: []string{"Queen"}},
+			track: catalogTrack("Smells Like Teen Spirit", 0, "Nirvana"),
+			min:   0, max: minScore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.entry, tt.track)
+			if got < tt.min-1e-9 || got > tt.max+1e-9 {
+				t.Errorf("Score = %.3f, want between %.3f and %.3f", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestBareTitle(t *testing.T) {
+	tests := map[string]string{
+		"Song":                           "Song",
+		"Song (feat. Someone)":           "Song",
+		"Song [Live]":                    "Song",
+		"Song - Remastered 2011":         "Song",
+		"Song – 2009 Remaster":           "Song",
+		"Song ft. Someone":               "Song",
+		"Song Featuring Someone (Remix)": "Song",
+		"Half-Life":                      "Half-Life",
+	}
+	for in, want := range tests {
+		if got := strings.TrimSpace(bareTitle(in)); got != want {
+			t.Errorf("bareTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSimilarityForgivesWordOrder(t *testing.T) {
+	if got := similarity(normalize("Simon & Garfunkel"), normalize("Garfunkel and Simon")); got != 1 {
+		t.Errorf("reordered words = %.2f, want 1", got)
+	}
+	if got := similarity("yesterday", "yesterdy"); got < 0.85 {
+		t.Errorf("one typo = %.2f, want at least 0.85", got)
+	}
+}
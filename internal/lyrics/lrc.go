@@ -0,0 +1,124 @@
+package lyrics
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Line is one line of lyrics. At is when it starts being sung; it is zero
+// for every line of unsynced lyrics.
+type Line struct {
+	At   time.Duration
+	Text string
+}
+
+// Lyrics are the words of a track, synced to its playback position when the
+// source had timestamps.
+type Lyrics struct {
+	Lines  []Line
+	Synced bool
+	Source string // the provider that found them
+}
+
+var (
+	// timestamp matches [mm:ss], [mm:ss.xx] and [mm:ss.xxx].
+	timestamp = regexp.MustCompile(`^\[(\d+):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+	// tag matches ID tags such as [ar:Artist] and [offset:+250].
+	tag = regexp.MustCompile(`^\[([a-zA-Z]+):(.*)\]$`)
+)
+
+// ParseLRC reads lyrics in the LRC format. A line may carry several
+// timestamps when it is sung more than once. Text without any timestamps is
+// returned as unsynced lyrics.
+func ParseLRC(r io.Reader) (*Lyrics, error) {
+	var (
+		synced []Line
+		plain  []Line
+		offset time.Duration
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\uFEFF"))
+
+		var stamps []time.Duration
+		for {
+			m := timestamp.FindStringSubmatch(line)
+			if m == nil {
+				break
+			}
+			stamps = append(stamps, parseTimestamp(m[1], m[2], m[3]))
+			line = line[len(m[0]):]
+		}
+		text := strings.TrimSpace(line)
+
+		if len(stamps) == 0 {
+			if m := tag.FindStringSubmatch(text); m != nil {
+				// A positive offset makes the lyrics appear sooner.
+				if strings.EqualFold(m[1], "offset") {
+					if ms, err := strconv.Atoi(strings.TrimSpace(m[2])); err == nil {
+						offset = time.Duration(ms) * time.Millisecond
+					}
+				}
+				continue
+			}
+			plain = append(plain, Line{Text: text})
+			continue
+		}
+		for _, at := range stamps {
+			synced = append(synced, Line{At: at, Text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(synced) == 0 {
+		return &Lyrics{Lines: trimBlank(plain)}, nil
+	}
+	for i := range synced {
+		synced[i].At = max(synced[i].At-offset, 0)
+	}
+	sort.SliceStable(synced, func(i, j int) bool { return synced[i].At < synced[j].At })
+	return &Lyrics{Lines: synced, Synced: true}, nil
+}
+
+func parseTimestamp(minutes, seconds, fraction string) time.Duration {
+	m, _ := strconv.Atoi(minutes)
+	s, _ := strconv.Atoi(seconds)
+	d := time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	if fraction != "" {
+		// Tenths in .x, hundredths in .xx, milliseconds in .xxx.
+		f, _ := strconv.Atoi(fraction)
+		for range 3 - len(fraction) {
+			f *= 10
+		}
+		d += time.Duration(f) * time.Millisecond
+	}
+	return d
+}
+
+// trimBlank drops blank lines at the start and end.
+func trimBlank(lines []Line) []Line {
+	for len(lines) > 0 && lines[0].Text == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1].Text == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// LineAt returns the index of the line being sung at pos, or -1 before the
+// first one starts. Unsynced lyrics always return -1.
+func (l *Lyrics) LineAt(pos time.Duration) int {
+	if l == nil || !l.Synced {
+		return -1
+	}
+	return sort.Search(len(l.Lines), func(i int) bool { return l.Lines[i].At > pos }) - 1
+}
@@ -0,0 +1,134 @@
+package lyrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ms(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+func TestParseLRC(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		lines  []Line
+		synced bool
+	}{
+		{
+			name:   "one timestamp per line",
+			in:     "[00:01.00]first\n[00:02.50]second\n",
+			lines:  []Line{{ms(1000), "first"}, {ms(2500), "second"}},
+			synced: true,
+		},
+		{
+			name:   "several timestamps on one line",
+			in:     "[00:10.00][00:01.00]chorus\n[00:05.00]verse\n",
+			lines:  []Line{{ms(1000), "chorus"}, {ms(5000), "verse"}, {ms(10000), "chorus"}},
+			synced: true,
+		},
+		{
+			name: "fractions of one to three digits",
+			in:   "[00:01.5]tenths\n[00:02.25]hundredths\n[00:03.125]millis\n[00:04]none\n[00:05:50]colon\n",
+			lines: []Line{
+				{ms(1500), "tenths"},
+				{ms(2250), "hundredths"},
+				{ms(3125), "millis"},
+				{ms(4000), "none"},
+				{ms(5500), "colon"},
+			},
+			synced: true,
+		},
+		{
+			name:   "minutes past the hour",
+			in:     "[61:00.00]late\n",
+			lines:  []Line{{61 * time.Minute, "late"}},
+			synced: true,
+		},
+		{
+			name:   "positive offset plays sooner and clamps at zero",
+			in:     "[offset:+500]\n[00:00.20]clamped\n[00:02.00]sooner\n",
+			lines:  []Line{{0, "clamped"}, {ms(1500), "sooner"}},
+			synced: true,
+		},
+		{
+			name:   "negative offset plays later",
+			in:     "[offset:-250]\n[00:01.00]later\n",
+			lines:  []Line{{ms(1250), "later"}},
+			synced: true,
+		},
+		{
+			name:   "ID tags are dropped",
+			in:     "[ar:Artist]\n[ti:Title]\n[00:01.00]words\n",
+			lines:  []Line{{ms(1000), "words"}},
+			synced: true,
+		},
+		{
+			name:   "byte order mark",
+			in:     "\ufeff[00:01.00]first\n",
+			lines:  []Line{{ms(1000), "first"}},
+			synced: true,
+		},
+		{
+			name:   "instrumental gap keeps its blank line",
+			in:     "[00:01.00]words\n[00:02.00]\n[00:03.00]more\n",
+			lines:  []Line{{ms(1000), "words"}, {ms(2000), ""}, {ms(3000), "more"}},
+			synced: true,
+		},
+		{
+			name:  "unsynced fallback trims blank lines at the ends",
+			in:    "\ufeff[ar:Artist]\n\nfirst line\n\nsecond line\n\n",
+			lines: []Line{{0, "first line"}, {0, ""}, {0, "second line"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLRC(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("ParseLRC: %v", err)
+			}
+			if got.Synced != tt.synced {
+				t.Errorf("Synced = %v, want %v", got.Synced, tt.synced)
+			}
+			if !reflect.DeepEqual(got.Lines, tt.lines) {
+				t.Errorf("Lines = %v, want %v", got.Lines, tt.lines)
+			}
+		})
+	}
+}
+
+func TestLineAt(t *testing.T) {
+	l := &Lyrics{
+		Synced: true,
+		Lines:  []Line{{ms(1000), "a"}, {ms(2000), "b"}, {ms(2000), "c"}, {ms(5000), "d"}},
+	}
+	tests := []struct {
+		pos  time.Duration
+		want int
+	}{
+		{0, -1},
+		{ms(999), -1},
+		{ms(1000), 0},
+		{ms(1999), 0},
+		{ms(2000), 2},
+		{ms(4999), 2},
+		{ms(5000), 3},
+		{time.Hour, 3},
+	}
+	for _, tt := range tests {
+		if got := l.LineAt(tt.pos); got != tt.want {
+			t.Errorf("LineAt(%v) = %d, want %d", tt.pos, got, tt.want)
+		}
+	}
+
+	unsynced := &Lyrics{Lines: []Line{{0, "a"}}}
+	if got := unsynced.LineAt(time.Minute); got != -1 {
+		t.Errorf("unsynced LineAt = %d, want -1", got)
+	}
+	var none *Lyrics
+	if got := none.LineAt(time.Minute); got != -1 {
+		t.Errorf("nil LineAt = %d, want -1", got)
+	}
+}
@@ -0,0 +1,157 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// ErrNotFound is returned when no provider has lyrics for a track.
+var ErrNotFound = errors.New("no lyrics found")
+
+// Provider looks up the lyrics of a track. Providers return ErrNotFound
+// when they have none, so the next one can be asked.
+type Provider interface {
+	Name() string
+	Lyrics(ctx context.Context, track entities.Track) (*Lyrics, error)
+}
+
+// LocalProvider reads .lrc (or plain .txt) files from a directory. For each
+// track it tries, in order:
+//
+//	<track id>.lrc
+//	<artist> - <title>.lrc
+//	<title>.lrc
+//
+// and the same names with .txt. Names are matched case-insensitively and
+// characters that cannot appear in file names may be left out.
+type LocalProvider struct {
+	dir string
+}
+
+func NewLocalProvider(dir string) *LocalProvider {
+	return &LocalProvider{dir: dir}
+}
+
+func (p *LocalProvider) Name() string { return "local" }
+
+func (p *LocalProvider) Lyrics(_ context.Context, track entities.Track) (*Lyrics, error) {
+	entries, err := os.ReadDir(p.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lyrics directory: %w", err)
+	}
+
+	files := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			files[fileKey(e.Name())] = e.Name()
+		}
+	}
+
+	var names []string
+	if track.ID != "" {
+		names = append(names, track.ID)
+	}
+	if len(track.Artists) > 0 {
+		names = append(names, track.Artists[0].Name+" - "+track.Name)
+	}
+	names = append(names, track.Name)
+
+	for _, name := range names {
+		for _, ext := range []string{".lrc", ".txt"} {
+			file, ok := files[fileKey(name+ext)]
+			if !ok {
+				continue
+			}
+			f, err := os.Open(filepath.Join(p.dir, file))
+			if err != nil {
+				return nil, err
+			}
+			l, err := ParseLRC(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			return l, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// fileKey normalises a file name for matching: lower case, without the
+// characters file systems reject.
+func fileKey(name string) string {
+	name = strings.ToLower(name)
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// maxCachedLyrics bounds the in-memory cache; it is cleared when full.
+const maxCachedLyrics = 200
+
+// Finder asks its providers in order and caches the answer, including
+// ErrNotFound, by track ID.
+type Finder struct {
+	providers []Provider
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+type cached struct {
+	lyrics *Lyrics
+	err    error
+}
+
+func NewFinder(providers ...Provider) *Finder {
+	return &Finder{providers: providers, cache: make(map[string]cached)}
+}
+
+// Find returns the lyrics of track from the first provider that has them.
+// Errors other than ErrNotFound are not cached, so they are retried on the
+// next request.
+func (f *Finder) Find(ctx context.Context, track entities.Track) (*Lyrics, error) {
+	f.mu.Lock()
+	c, ok := f.cache[track.ID]
+	f.mu.Unlock()
+	if ok {
+		return c.lyrics, c.err
+	}
+
+	for _, p := range f.providers {
+		l, err := p.Lyrics(ctx, track)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s lyrics: %w", p.Name(), err)
+		}
+		l.Source = p.Name()
+		f.store(track.ID, cached{lyrics: l})
+		return l, nil
+	}
+	f.store(track.ID, cached{err: ErrNotFound})
+	return nil, ErrNotFound
+}
+
+func (f *Finder) store(trackID string, c cached) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.cache) >= maxCachedLyrics {
+		clear(f.cache)
+	}
+	f.cache[trackID] = c
+}
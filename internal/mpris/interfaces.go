@@ -0,0 +1,230 @@
+package mpris
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// root is org.mpris.MediaPlayer2. The player runs in a terminal, so it can
+// neither be raised nor quit from outside.
+type root struct{}
+
+func (root) Raise() *dbus.Error { return nil }
+func (root) Quit() *dbus.Error  { return nil }
+
+// player is org.mpris.MediaPlayer2.Player.
+type player struct {
+	p *Player
+}
+
+func (m player) Next() *dbus.Error {
+	return m.p.run(m.p.playback.NextTrack)
+}
+
+func (m player) Previous() *dbus.Error {
+	return m.p.run(m.p.playback.PreviousTrack)
+}
+
+func (m player) Pause() *dbus.Error {
+	return m.p.run(m.p.playback.PausePlayback)
+}
+
+func (m player) Play() *dbus.Error {
+	return m.p.run(m.p.playback.ResumePlayback)
+}
+
+// Stop pauses; Spotify has no stopped state to return to.
+func (m player) Stop() *dbus.Error {
+	return m.Pause()
+}
+
+func (m player) PlayPause() *dbus.Error {
+	if state, _ := m.p.current(); state != nil && state.IsPlaying {
+		return m.Pause()
+	}
+	return m.Play()
+}
+
+// SeekBy is the Seek method, renamed in Go so it is not mistaken for
+// io.Seeker. It moves the position by offset microseconds; seeking past
+// the end skips to the next track, as the specification asks.
+func (m player) SeekBy(offset int64) *dbus.Error {
+	state, pos := m.p.current()
+	if state == nil || state.Track.ID == "" {
+		return nil
+	}
+	target := pos + int(offset/1000)
+	if target >= state.Track.DurationMs {
+		return m.Next()
+	}
+	return m.p.run(func() error { return m.p.playback.SeekTo(max(target, 0)) })
+}
+
+// SetPosition seeks to position microseconds, ignored unless trackID is
+// still the current track.
+func (m player) SetPosition(trackID dbus.ObjectPath, position int64) *dbus.Error {
+	state, _ := m.p.current()
+	if state == nil || trackPath(state.Track.ID) != trackID {
+		return nil
+	}
+	if position < 0 || int(position/1000) > state.Track.DurationMs {
+		return nil
+	}
+	return m.p.run(func() error { return m.p.playback.SeekTo(int(position / 1000)) })
+}
+
+// OpenUri plays a spotify:track: URI.
+func (m player) OpenUri(uri string) *dbus.Error {
+	if !strings.HasPrefix(uri, "spotify:track:") {
+		return dbus.MakeFailedError(fmt.Errorf("cannot open %s: only spotify:track: URIs are supported", uri))
+	}
+	return m.p.run(func() error { return m.p.playback.Play(uri, "") })
+}
+
+// properties is org.freedesktop.DBus.Properties. It is implemented here
+// rather than with the prop package because Position has to be worked out
+// when it is read.
+type properties struct {
+	p *Player
+}
+
+func (m properties) all(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	switch iface {
+	case rootInterface:
+		return rootProperties(), nil
+	case playerInterface:
+		m.p.mu.Lock()
+		defer m.p.mu.Unlock()
+		return m.p.playerProperties(), nil
+	}
+	return nil, prop.ErrIfaceNotFound
+}
+
+func (m properties) Get(iface, name string) (dbus.Variant, *dbus.Error) {
+	props, err := m.all(iface)
+	if err != nil {
+		return dbus.Variant{}, err
+	}
+	v, ok := props[name]
+	if !ok {
+		return dbus.Variant{}, prop.ErrPropNotFound
+	}
+	return v, nil
+}
+
+func (m properties) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	return m.all(iface)
+}
+
+// Set changes the writable player properties: Volume, Shuffle and
+// LoopStatus. Rate only accepts 1.
+func (m properties) Set(iface, name string, value dbus.Variant) *dbus.Error {
+	if _, err := m.Get(iface, name); err != nil {
+		return err
+	}
+	if iface != playerInterface {
+		return prop.ErrReadOnly
+	}
+
+	switch name {
+	case "Volume":
+		v, ok := value.Value().(float64)
+		if !ok {
+			return prop.ErrInvalidArg
+		}
+		percent := int(min(max(v, 0), 1)*100 + 0.5)
+		return m.p.run(func() error { return m.p.playback.SetVolume(percent) })
+	case "Shuffle":
+		v, ok := value.Value().(bool)
+		if !ok {
+			return prop.ErrInvalidArg
+		}
+		return m.p.run(func() error { return m.p.playback.ToggleShufflePlayback(v) })
+	case "LoopStatus":
+		v, ok := value.Value().(string)
+		if !ok {
+			return prop.ErrInvalidArg
+		}
+		state, ok := repeatState(v)
+		if !ok {
+			return prop.ErrInvalidArg
+		}
+		return m.p.run(func() error { return m.p.playback.ToggleRepeatPlayback(state) })
+	case "Rate":
+		if v, ok := value.Value().(float64); !ok || v != 1 {
+			return prop.ErrInvalidArg
+		}
+		return nil
+	}
+	return prop.ErrReadOnly
+}
+
+// playerMethodNames maps Go method names to the D-Bus ones they differ from.
+var playerMethodNames = map[string]string{"SeekBy": "Seek"}
+
+func playerMethods() []introspect.Method {
+	methods := introspect.Methods(player{})
+	for i, m := range methods {
+		if name, ok := playerMethodNames[m.Name]; ok {
+			methods[i].Name = name
+		}
+	}
+	return methods
+}
+
+// introspection describes the exported object for clients that look
+// before they call.
+func (p *Player) introspection() *introspect.Node {
+	property := func(name, sig, access string) introspect.Property {
+		return introspect.Property{Name: name, Type: sig, Access: access}
+	}
+	return &introspect.Node{
+		Name: string(objectPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{
+				Name:    rootInterface,
+				Methods: introspect.Methods(root{}),
+				Properties: []introspect.Property{
+					property("Identity", "s", "read"),
+					property("CanQuit", "b", "read"),
+					property("CanRaise", "b", "read"),
+					property("CanSetFullscreen", "b", "read"),
+					property("Fullscreen", "b", "read"),
+					property("HasTrackList", "b", "read"),
+					property("SupportedUriSchemes", "as", "read"),
+					property("SupportedMimeTypes", "as", "read"),
+				},
+			},
+			{
+				Name:    playerInterface,
+				Methods: playerMethods(),
+				Signals: []introspect.Signal{
+					{Name: "Seeked", Args: []introspect.Arg{{Name: "Position", Type: "x"}}},
+				},
+				Properties: []introspect.Property{
+					property("PlaybackStatus", "s", "read"),
+					property("LoopStatus", "s", "readwrite"),
+					property("Rate", "d", "readwrite"),
+					property("MinimumRate", "d", "read"),
+					property("MaximumRate", "d", "read"),
+					property("Shuffle", "b", "readwrite"),
+					property("Metadata", "a{sv}", "read"),
+					property("Volume", "d", "readwrite"),
+					property("Position", "x", "read"),
+					property("CanGoNext", "b", "read"),
+					property("CanGoPrevious", "b", "read"),
+					property("CanPlay", "b", "read"),
+					property("CanPause", "b", "read"),
+					property("CanSeek", "b", "read"),
+					property("CanControl", "b", "read"),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,307 @@
+// Package mpris exposes playback over D-Bus as an MPRIS2 media player, so
+// media keys, desktop widgets and tools such as playerctl can control it.
+package mpris
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/thomassbooth/spotify-tui/internal/art"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+const (
+	busName         = "org.mpris.MediaPlayer2.spotify_tui"
+	objectPath      = dbus.ObjectPath("/org/mpris/MediaPlayer2")
+	rootInterface   = "org.mpris.MediaPlayer2"
+	playerInterface = "org.mpris.MediaPlayer2.Player"
+	propsInterface  = "org.freedesktop.DBus.Properties"
+	trackPathPrefix = "/org/mpris/MediaPlayer2/track/"
+	noTrack         = dbus.ObjectPath("/org/mpris/MediaPlayer2/TrackList/NoTrack")
+)
+
+// seekThreshold is how far the position may jump from where steady playback
+// would have put it before clients are sent a Seeked signal.
+const seekThreshold = 2 * time.Second
+
+// refreshDelay gives Spotify time to apply a command before the state is
+// fetched again.
+const refreshDelay = 300 * time.Millisecond
+
+// Player is the MPRIS service for one playback source. Commands go to
+// playback; the properties follow the states passed to Update.
+type Player struct {
+	conn     *dbus.Conn
+	ownConn  bool // opened by Register, closed with the player
+	playback service.Playback
+	name     string
+
+	mu      sync.Mutex
+	state   *entities.PlaybackState
+	fetched time.Time               // when state was current
+	emitted map[string]dbus.Variant // player properties clients last heard of
+}
+
+// Register connects to the session bus and exports a player there.
+func Register(playback service.Playback) (*Player, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the session bus: %w", err)
+	}
+	p, err := New(conn, playback)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	p.ownConn = true
+	return p, nil
+}
+
+// New exports the player on conn and claims the MPRIS bus name. When
+// another instance holds the name, a per-process one is used instead, as
+// the specification allows.
+func New(conn *dbus.Conn, playback service.Playback) (*Player, error) {
+	p := &Player{conn: conn, playback: playback, emitted: map[string]dbus.Variant{}}
+
+	exports := []struct {
+		v     any
+		names map[string]string
+		iface string
+	}{
+		{root{}, nil, rootInterface},
+		{player{p}, playerMethodNames, playerInterface},
+		{properties{p}, nil, propsInterface},
+		{introspect.NewIntrospectable(p.introspection()), nil, "org.freedesktop.DBus.Introspectable"},
+	}
+	for _, e := range exports {
+		if err := conn.ExportWithMap(e.v, e.names, objectPath, e.iface); err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", e.iface, err)
+		}
+	}
+
+	for _, name := range []string{busName, fmt.Sprintf("%s.instance%d", busName, os.Getpid())} {
+		reply, err := conn.RequestName(name, dbus.NameFlagDoNotQueue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to request %s: %w", name, err)
+		}
+		if reply == dbus.RequestNameReplyPrimaryOwner {
+			p.name = name
+			return p, nil
+		}
+	}
+	return nil, errors.New("mpris: bus name is taken")
+}
+
+// Name is the bus name the player was registered under.
+func (p *Player) Name() string {
+	return p.name
+}
+
+// Close releases the bus name, and the connection when Register opened it.
+func (p *Player) Close() error {
+	_, err := p.conn.ReleaseName(p.name)
+	if p.ownConn {
+		return p.conn.Close()
+	}
+	return err
+}
+
+// Follow updates the player from a stream of playback changes until it is
+// closed. It fetches the current state first.
+func (p *Player) Follow(updates <-chan service.PlaybackUpdate) {
+	go func() {
+		if state, err := p.playback.GetCurrentPlaybackState(); err == nil {
+			p.Update(state)
+		}
+		for u := range updates {
+			if u.Err == nil {
+				p.Update(u.State)
+			}
+		}
+	}()
+}
+
+// Update takes a new playback state, telling clients what changed.
+func (p *Player) Update(state *entities.PlaybackState) {
+	p.mu.Lock()
+	prev, prevAt := p.state, p.fetched
+	p.state, p.fetched = state, time.Now()
+	props := p.playerProperties()
+
+	changed := map[string]dbus.Variant{}
+	for name, v := range props {
+		if name == "Position" {
+			continue // clients extrapolate it; jumps are sent as Seeked
+		}
+		if old, ok := p.emitted[name]; !ok || !reflect.DeepEqual(old.Value(), v.Value()) {
+			changed[name] = v
+			p.emitted[name] = v
+		}
+	}
+	p.mu.Unlock()
+
+	if len(changed) > 0 {
+		p.conn.Emit(objectPath, propsInterface+".PropertiesChanged", playerInterface, changed, []string{})
+	}
+	if seeked(prev, prevAt, state) {
+		p.conn.Emit(objectPath, playerInterface+".Seeked", microseconds(state.ProgressMs))
+	}
+}
+
+// seeked reports whether next is the same track as prev at a position
+// steady playback since prevAt would not have reached.
+func seeked(prev *entities.PlaybackState, prevAt time.Time, next *entities.PlaybackState) bool {
+	if prev == nil || next == nil || prev.Track.ID != next.Track.ID {
+		return false
+	}
+	expected := time.Duration(prev.ProgressMs) * time.Millisecond
+	if prev.IsPlaying {
+		expected += time.Since(prevAt)
+	}
+	drift := time.Duration(next.ProgressMs)*time.Millisecond - expected
+	return drift > seekThreshold || drift < -seekThreshold
+}
+
+// position returns the current position in milliseconds, moved on by the
+// time since the state was fetched.
+func (p *Player) position() int {
+	if p.state == nil {
+		return 0
+	}
+	pos := p.state.ProgressMs
+	if p.state.IsPlaying {
+		pos += int(time.Since(p.fetched).Milliseconds())
+	}
+	return min(pos, p.state.Track.DurationMs)
+}
+
+// playerProperties returns the Player interface properties. p.mu is held.
+func (p *Player) playerProperties() map[string]dbus.Variant {
+	s := p.state
+	status, loop, shuffle, volume := "Stopped", "None", false, 0.0
+	metadata := map[string]dbus.Variant{"mpris:trackid": dbus.MakeVariant(noTrack)}
+	if s != nil && s.Track.ID != "" {
+		status = "Paused"
+		if s.IsPlaying {
+			status = "Playing"
+		}
+		loop = loopStatus(s.RepeatState)
+		shuffle = s.ShuffleState
+		volume = float64(s.Device.VolumePercent) / 100
+		metadata = trackMetadata(s.Track)
+	}
+
+	return map[string]dbus.Variant{
+		"PlaybackStatus": dbus.MakeVariant(status),
+		"LoopStatus":     dbus.MakeVariant(loop),
+		"Rate":           dbus.MakeVariant(1.0),
+		"MinimumRate":    dbus.MakeVariant(1.0),
+		"MaximumRate":    dbus.MakeVariant(1.0),
+		"Shuffle":        dbus.MakeVariant(shuffle),
+		"Metadata":       dbus.MakeVariant(metadata),
+		"Volume":         dbus.MakeVariant(volume),
+		"Position":       dbus.MakeVariant(microseconds(p.position())),
+		"CanGoNext":      dbus.MakeVariant(true),
+		"CanGoPrevious":  dbus.MakeVariant(true),
+		"CanPlay":        dbus.MakeVariant(true),
+		"CanPause":       dbus.MakeVariant(true),
+		"CanSeek":        dbus.MakeVariant(s != nil && s.Track.ID != ""),
+		"CanControl":     dbus.MakeVariant(true),
+	}
+}
+
+func rootProperties() map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"Identity":            dbus.MakeVariant("spotify-tui"),
+		"CanQuit":             dbus.MakeVariant(false),
+		"CanRaise":            dbus.MakeVariant(false),
+		"CanSetFullscreen":    dbus.MakeVariant(false),
+		"Fullscreen":          dbus.MakeVariant(false),
+		"HasTrackList":        dbus.MakeVariant(false),
+		"SupportedUriSchemes": dbus.MakeVariant([]string{"spotify"}),
+		"SupportedMimeTypes":  dbus.MakeVariant([]string{}),
+	}
+}
+
+// trackMetadata describes a track with the xesam and mpris fields clients
+// display.
+func trackMetadata(t entities.Track) map[string]dbus.Variant {
+	artists := make([]string, len(t.Artists))
+	for i, a := range t.Artists {
+		artists[i] = a.Name
+	}
+	m := map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(trackPath(t.ID)),
+		"mpris:length":  dbus.MakeVariant(microseconds(t.DurationMs)),
+		"xesam:title":   dbus.MakeVariant(t.Name),
+		"xesam:artist":  dbus.MakeVariant(artists),
+		"xesam:album":   dbus.MakeVariant(t.Album.Name),
+		"xesam:url":     dbus.MakeVariant("https://open.spotify.com/track/" + t.ID),
+	}
+	if url := art.PickImage(t.Album.Images, 300); url != "" {
+		m["mpris:artUrl"] = dbus.MakeVariant(url)
+	}
+	return m
+}
+
+// trackPath turns a track ID into an object path. Spotify IDs are base62,
+// which object paths accept as they are.
+func trackPath(id string) dbus.ObjectPath {
+	return dbus.ObjectPath(trackPathPrefix + id)
+}
+
+func microseconds(ms int) int64 {
+	return int64(ms) * 1000
+}
+
+// loopStatus maps a Spotify repeat state to MPRIS, and repeatState back.
+func loopStatus(repeat string) string {
+	switch repeat {
+	case "track":
+		return "Track"
+	case "context":
+		return "Playlist"
+	}
+	return "None"
+}
+
+func repeatState(loop string) (string, bool) {
+	switch loop {
+	case "None":
+		return "off", true
+	case "Track":
+		return "track", true
+	case "Playlist":
+		return "context", true
+	}
+	return "", false
+}
+
+// current returns the state and position for commands that depend on them.
+func (p *Player) current() (*entities.PlaybackState, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state, p.position()
+}
+
+// run performs a command, then fetches the state so every follower of the
+// playback source, this player included, sees the result.
+func (p *Player) run(action func() error) *dbus.Error {
+	if err := action(); err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	go func() {
+		time.Sleep(refreshDelay)
+		if state, err := p.playback.GetCurrentPlaybackState(); err == nil {
+			p.Update(state)
+		}
+	}()
+	return nil
+}
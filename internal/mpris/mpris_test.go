@@ -0,0 +1,323 @@
+package mpris
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+// fakePlayback records the commands it is sent. Methods the player does not
+// use are left to the nil interface and panic if called.
+type fakePlayback struct {
+	service.Playback
+
+	mu    sync.Mutex
+	state *entities.PlaybackState
+	calls []string
+}
+
+func (f *fakePlayback) record(format string, args ...any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, fmt.Sprintf(format, args...))
+	return nil
+}
+
+func (f *fakePlayback) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *fakePlayback) GetCurrentPlaybackState() (*entities.PlaybackState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state, nil
+}
+
+func (f *fakePlayback) NextTrack() error            { return f.record("next") }
+func (f *fakePlayback) PreviousTrack() error        { return f.record("previous") }
+func (f *fakePlayback) PausePlayback() error        { return f.record("pause") }
+func (f *fakePlayback) ResumePlayback() error       { return f.record("resume") }
+func (f *fakePlayback) SeekTo(ms int) error         { return f.record("seek %d", ms) }
+func (f *fakePlayback) SetVolume(percent int) error { return f.record("volume %d", percent) }
+func (f *fakePlayback) ToggleShufflePlayback(on bool) error {
+	return f.record("shuffle %v", on)
+}
+func (f *fakePlayback) ToggleRepeatPlayback(state string) error {
+	return f.record("repeat %s", state)
+}
+
+// startBus runs a private message bus for the test and returns its address.
+// Tests are skipped where dbus-daemon is not installed.
+func startBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "bus.conf")
+	err = os.WriteFile(config, []byte(`<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:dir=`+dir+`</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address=1")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("dbus-daemon would not start: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading the bus address: %v", err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+func connect(t *testing.T, addr string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatalf("connecting to the bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// newTestPlayer exports a player on a private bus and returns it with a
+// second connection to drive it from, as a desktop client would.
+func newTestPlayer(t *testing.T) (*Player, *fakePlayback, dbus.BusObject, *dbus.Conn) {
+	t.Helper()
+	addr := startBus(t)
+	playback := &fakePlayback{}
+	p, err := New(connect(t, addr), playback)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	client := connect(t, addr)
+	return p, playback, client.Object(p.Name(), objectPath), client
+}
+
+func testState() *entities.PlaybackState {
+	return &entities.PlaybackState{
+		IsPlaying:   true,
+		ProgressMs:  10_000,
+		RepeatState: "track",
+		Device:      entities.Device{VolumePercent: 42},
+		Track: entities.Track{
+			ID:         "4uLU6hMCjMI75M1A2tKUQC",
+			Name:       "Never Gonna Give You Up",
+			DurationMs: 213_000,
+			Artists:    []entities.Artist{{Name: "Rick Astley"}},
+			Album:      entities.Album{Name: "Whenever You Need Somebody"},
+		},
+	}
+}
+
+func get(t *testing.T, obj dbus.BusObject, name string) any {
+	t.Helper()
+	v, err := obj.GetProperty(playerInterface + "." + name)
+	if err != nil {
+		t.Fatalf("Get %s: %v", name, err)
+	}
+	return v.Value()
+}
+
+func TestProperties(t *testing.T) {
+	p, _, obj, _ := newTestPlayer(t)
+
+	if got := get(t, obj, "PlaybackStatus"); got != "Stopped" {
+		t.Errorf("PlaybackStatus before any state = %v, want Stopped", got)
+	}
+
+	p.Update(testState())
+	tests := []struct {
+		name string
+		want any
+	}{
+		{"PlaybackStatus", "Playing"},
+		{"LoopStatus", "Track"},
+		{"Shuffle", false},
+		{"Volume", 0.42},
+		{"CanSeek", true},
+	}
+	for _, tt := range tests {
+		if got := get(t, obj, tt.name); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	metadata, ok := get(t, obj, "Metadata").(map[string]dbus.Variant)
+	if !ok {
+		t.Fatalf("Metadata is not a{sv}")
+	}
+	wantMetadata := map[string]any{
+		"mpris:trackid": trackPath("4uLU6hMCjMI75M1A2tKUQC"),
+		"mpris:length":  int64(213_000_000),
+		"xesam:title":   "Never Gonna Give You Up",
+		"xesam:artist":  []string{"Rick Astley"},
+		"xesam:album":   "Whenever You Need Somebody",
+	}
+	for key, want := range wantMetadata {
+		if got := metadata[key].Value(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Metadata[%s] = %v, want %v", key, got, want)
+		}
+	}
+
+	if pos, _ := get(t, obj, "Position").(int64); pos < 10_000_000 {
+		t.Errorf("Position = %d, want at least the fetched 10s", pos)
+	}
+}
+
+func TestPropertiesChanged(t *testing.T) {
+	p, _, _, client := newTestPlayer(t)
+
+	signals := make(chan *dbus.Signal, 10)
+	client.Signal(signals)
+	if err := client.AddMatchSignal(dbus.WithMatchInterface(propsInterface), dbus.WithMatchMember("PropertiesChanged")); err != nil {
+		t.Fatal(err)
+	}
+	next := func() map[string]dbus.Variant {
+		t.Helper()
+		select {
+		case sig := <-signals:
+			if sig.Body[0] != playerInterface {
+				t.Fatalf("PropertiesChanged for %v, want %s", sig.Body[0], playerInterface)
+			}
+			return sig.Body[1].(map[string]dbus.Variant)
+		case <-time.After(2 * time.Second):
+			t.Fatal("no PropertiesChanged signal")
+			return nil
+		}
+	}
+
+	state := testState()
+	p.Update(state)
+	changed := next()
+	for _, name := range []string{"PlaybackStatus", "Metadata", "LoopStatus", "Volume"} {
+		if _, ok := changed[name]; !ok {
+			t.Errorf("first update did not announce %s", name)
+		}
+	}
+	if _, ok := changed["Position"]; ok {
+		t.Error("Position was announced; clients extrapolate it")
+	}
+
+	paused := *state
+	paused.IsPlaying = false
+	p.Update(&paused)
+	changed = next()
+	if len(changed) != 1 || changed["PlaybackStatus"].Value() != "Paused" {
+		t.Errorf("pausing announced %v, want only PlaybackStatus=Paused", changed)
+	}
+
+	// Nothing changed, so nothing is sent.
+	p.Update(&paused)
+	select {
+	case sig := <-signals:
+		t.Errorf("unchanged state announced %v", sig.Body[1])
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSeekPastEndSkips(t *testing.T) {
+	p, playback, obj, _ := newTestPlayer(t)
+	p.Update(testState())
+
+	if err := obj.Call(playerInterface+".Seek", 0, int64(5_000_000)).Err; err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	if err := obj.Call(playerInterface+".Seek", 0, int64(10*time.Minute/time.Microsecond)).Err; err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+
+	calls := playback.Calls()
+	if len(calls) != 2 || !strings.HasPrefix(calls[0], "seek 15") || calls[1] != "next" {
+		t.Errorf("calls = %q, want a seek to about 15s then next", calls)
+	}
+}
+
+func TestSetPosition(t *testing.T) {
+	p, playback, obj, _ := newTestPlayer(t)
+	p.Update(testState())
+
+	stale := trackPath("0000000000000000000000")
+	if err := obj.Call(playerInterface+".SetPosition", 0, stale, int64(30_000_000)).Err; err != nil {
+		t.Fatalf("SetPosition: %v", err)
+	}
+	if calls := playback.Calls(); len(calls) != 0 {
+		t.Fatalf("SetPosition for another track ran %q", calls)
+	}
+
+	current := trackPath("4uLU6hMCjMI75M1A2tKUQC")
+	if err := obj.Call(playerInterface+".SetPosition", 0, current, int64(30_000_000)).Err; err != nil {
+		t.Fatalf("SetPosition: %v", err)
+	}
+	if calls := playback.Calls(); !reflect.DeepEqual(calls, []string{"seek 30000"}) {
+		t.Errorf("calls = %q, want [seek 30000]", calls)
+	}
+}
+
+func TestSetProperties(t *testing.T) {
+	p, playback, obj, _ := newTestPlayer(t)
+	p.Update(testState())
+
+	sets := []struct {
+		name  string
+		value any
+	}{
+		{"LoopStatus", "Playlist"},
+		{"LoopStatus", "None"},
+		{"Shuffle", true},
+		{"Volume", 0.5},
+	}
+	for _, s := range sets {
+		if err := obj.SetProperty(playerInterface+"."+s.name, dbus.MakeVariant(s.value)); err != nil {
+			t.Fatalf("Set %s: %v", s.name, err)
+		}
+	}
+	want := []string{"repeat context", "repeat off", "shuffle true", "volume 50"}
+	if calls := playback.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+
+	if err := obj.SetProperty(playerInterface+".LoopStatus", dbus.MakeVariant("Forever")); err == nil {
+		t.Error("Set LoopStatus=Forever succeeded")
+	}
+	if err := obj.SetProperty(playerInterface+".PlaybackStatus", dbus.MakeVariant("Paused")); err == nil {
+		t.Error("Set PlaybackStatus succeeded; it is read-only")
+	}
+}
@@ -0,0 +1,145 @@
+// Package playlistfile reads and writes playlists as files that outlive
+// Spotify: CSV and JSON for spreadsheets and scripts, and M3U8 and XSPF for
+// other players. Tracks keep their Spotify URIs so a file can be matched
+// back.
+package playlistfile
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// Format is a kind of playlist file, named after its extension.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+	M3U8 Format = "m3u8"
+	XSPF Format = "xspf"
+)
+
+// Formats lists every format.
+var Formats = []Format{CSV, JSON, M3U8, XSPF}
+
+// Ext is the file extension of f, with the dot.
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// ParseFormat accepts a format by name, case-insensitively, and "m3u" for
+// M3U8.
+func ParseFormat(s string) (Format, bool) {
+	s = strings.ToLower(strings.TrimPrefix(s, "."))
+	if s == "m3u" {
+		return M3U8, true
+	}
+	for _, f := range Formats {
+		if s == string(f) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// FormatOf returns the format of path by its extension.
+func FormatOf(path string) (Format, bool) {
+	return ParseFormat(filepath.Ext(path))
+}
+
+// Filename turns a playlist name into a file name with f's extension,
+// replacing the characters file systems reject.
+func Filename(name string, f Format) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		name = "playlist"
+	}
+	return name + f.Ext()
+}
+
+// Playlist is what a file holds: a playlist's details and its tracks in
+// order.
+type Playlist struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Owner       string  `json:"owner,omitempty"`
+	URI         string  `json:"uri,omitempty"`
+	Entries     []Entry `json:"tracks"`
+}
+
+// Entry is one track of a playlist file.
+type Entry struct {
+	Title      string   `json:"title"`
+	Artists    []string `json:"artists"`
+	Album      string   `json:"album,omitempty"`
+	DurationMs int      `json:"duration_ms"`
+	URI        string   `json:"uri,omitempty"`
+	ISRC       string   `json:"isrc,omitempty"`
+	AddedAt    string   `json:"added_at,omitempty"`
+}
+
+// Artist joins the entry's artists the way players show them.
+func (e Entry) Artist() string {
+	return strings.Join(e.Artists, ", ")
+}
+
+// New builds the file contents for a playlist and its tracks.
+func New(p entities.Playlist, tracks []entities.Track) Playlist {
+	out := Playlist{
+		Name:        p.Name,
+		Description: p.Description,
+		Owner:       p.OwnerName,
+		URI:         p.URI,
+		Entries:     make([]Entry, 0, len(tracks)),
+	}
+	for _, t := range tracks {
+		artists := make([]string, len(t.Artists))
+		for i, a := range t.Artists {
+			artists[i] = a.Name
+		}
+		uri := t.URI
+		if uri == "" && t.ID != "" {
+			uri = "spotify:track:" + t.ID
+		}
+		out.Entries = append(out.Entries, Entry{
+			Title:      t.Name,
+			Artists:    artists,
+			Album:      t.Album.Name,
+			DurationMs: t.DurationMs,
+			URI:        uri,
+			ISRC:       t.ISRC,
+			AddedAt:    t.AddedAt,
+		})
+	}
+	return out
+}
+
+// Write writes p to w in format f.
+func Write(w io.Writer, f Format, p Playlist) error {
+	var err error
+	switch f {
+	case CSV:
+		err = writeCSV(w, p)
+	case JSON:
+		err = writeJSON(w, p)
+	case M3U8:
+		err = writeM3U8(w, p)
+	case XSPF:
+		err = writeXSPF(w, p)
+	default:
+		return fmt.Errorf("unknown playlist format %q", f)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", f, err)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package playlistfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ReadFile reads a playlist file, in the format its extension names or,
+// failing that, the one its contents look like. A playlist without a name
+// is named after the file.
+func ReadFile(path string) (Playlist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Playlist{}, err
+	}
+	f, ok := FormatOf(path)
+	if !ok {
+		f = sniff(data)
+	}
+	p, err := Read(bytes.NewReader(data), f)
+	if err != nil {
+		return Playlist{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return p, nil
+}
+
+// sniff guesses the format of a file without a known extension.
+func sniff(data []byte) Format {
+	head := strings.TrimLeftFunc(strings.TrimPrefix(string(data[:min(len(data), 512)]), "\ufeff"), unicode.IsSpace)
+	switch {
+	case strings.HasPrefix(head, "#EXTM3U"):
+		return M3U8
+	case strings.HasPrefix(head, "<"):
+		return XSPF
+	case strings.HasPrefix(head, "{"):
+		return JSON
+	}
+	return CSV
+}
+
+// Read parses a playlist in format f. Besides the files Write produces it
+// accepts what other services and players export: CSV with headers such as
+// "Track Name" and "Artist Name(s)" or with just artist and title columns,
+// plain M3U lists of "artist - title" files, and XSPF from any player.
+// Entries with an artist but no title, or the reverse, split "artist -
+// title" into the two.
+func Read(r io.Reader, f Format) (Playlist, error) {
+	var (
+		p   Playlist
+		err error
+	)
+	switch f {
+	case CSV:
+		p, err = readCSV(r)
+	case JSON:
+		err = json.NewDecoder(r).Decode(&p)
+	case M3U8:
+		p, err = readM3U(r)
+	case XSPF:
+		p, err = readXSPF(r)
+	default:
+		return Playlist{}, fmt.Errorf("unknown playlist format %q", f)
+	}
+	if err != nil {
+		return Playlist{}, fmt.Errorf("failed to read %s: %w", f, err)
+	}
+
+	entries := p.Entries[:0]
+	for _, e := range p.Entries {
+		e = split(e)
+		if e.Title != "" || e.URI != "" || e.ISRC != "" {
+			entries = append(entries, e)
+		}
+	}
+	p.Entries = entries
+	return p, nil
+}
+
+// split fills in the artist and title of an entry that has them in one
+// "artist - title" field.
+func split(e Entry) Entry {
+	switch {
+	case len(e.Artists) == 0:
+		if artist, title, ok := cutDash(e.Title); ok {
+			e.Artists, e.Title = []string{artist}, title
+		}
+	case e.Title == "" && len(e.Artists) == 1:
+		if artist, title, ok := cutDash(e.Artists[0]); ok {
+			e.Artists, e.Title = []string{artist}, title
+		}
+	}
+	return e
+}
+
+// cutDash splits s around the first spaced hyphen or dash.
+func cutDash(s string) (before, after string, ok bool) {
+	for _, sep := range []string{" - ", " – ", " — "} {
+		if before, after, ok := strings.Cut(s, sep); ok {
+			return strings.TrimSpace(before), strings.TrimSpace(after), true
+		}
+	}
+	return "", "", false
+}
+
+// csvColumns maps header names, lowercased with everything but letters
+// and digits dropped, to the field they hold.
+var csvColumns = map[string]string{
+	"title": "title", "track": "title", "trackname": "title", "name": "title", "song": "title", "songname": "title",
+	"artist": "artists", "artists": "artists", "artistname": "artists", "artistnames": "artists", "performer": "artists",
+	"album": "album", "albumname": "album", "release": "album",
+	"durationms": "duration_ms", "duration": "duration", "length": "duration", "time": "duration",
+	"uri": "uri", "trackuri": "uri", "spotifyuri": "uri", "url": "uri", "link": "uri",
+	"isrc": "isrc", "addedat": "added_at", "dateadded": "added_at",
+}
+
+func readCSV(r io.Reader) (Playlist, error) {
+	in := csv.NewReader(r)
+	in.FieldsPerRecord = -1
+	in.LazyQuotes = true
+	rows, err := in.ReadAll()
+	if err != nil {
+		return Playlist{}, err
+	}
+	if len(rows) == 0 {
+		return Playlist{}, nil
+	}
+	if len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+
+	fields := map[string]int{}
+	for i, name := range rows[0] {
+		key := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, name)
+		if field, ok := csvColumns[key]; ok {
+			if _, seen := fields[field]; !seen {
+				fields[field] = i
+			}
+		}
+	}
+	_, hasTitle := fields["title"]
+	_, hasArtists := fields["artists"]
+	if !hasTitle && !hasArtists {
+		// No header: the columns are artist and title, or "artist - title".
+		fields = map[string]int{"title": 0}
+		if len(rows[0]) > 1 {
+			fields = map[string]int{"artists": 0, "title": 1}
+		}
+	} else {
+		rows = rows[1:]
+	}
+
+	var p Playlist
+	for _, row := range rows {
+		get := func(field string) string {
+			if i, ok := fields[field]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		e := Entry{
+			Title:   get("title"),
+			Album:   get("album"),
+			URI:     spotifyURI(get("uri")),
+			ISRC:    strings.ToUpper(get("isrc")),
+			AddedAt: get("added_at"),
+		}
+		if artists := get("artists"); artists != "" {
+			for _, a := range strings.Split(artists, ";") {
+				if a = strings.TrimSpace(a); a != "" {
+					e.Artists = append(e.Artists, a)
+				}
+			}
+		}
+		if ms, err := strconv.Atoi(get("duration_ms")); err == nil {
+			e.DurationMs = ms
+		} else {
+			e.DurationMs = parseDuration(get("duration"))
+		}
+		p.Entries = append(p.Entries, e)
+	}
+	return p, nil
+}
+
+// parseDuration reads "m:ss", "h:mm:ss" or a number of seconds as
+// milliseconds, or 0 when s is none of them.
+func parseDuration(s string) int {
+	if s == "" {
+		return 0
+	}
+	secs := 0.0
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		secs = secs*60 + n
+	}
+	return int(secs * 1000)
+}
+
+// spotifyURI turns a track URI or open.spotify.com link into a
+// spotify:track: URI, and anything else into "".
+func spotifyURI(s string) string {
+	if strings.HasPrefix(s, "spotify:track:") {
+		return s
+	}
+	if _, rest, ok := strings.Cut(s, "open.spotify.com/track/"); ok {
+		id, _, _ := strings.Cut(rest, "?")
+		return "spotify:track:" + strings.TrimSuffix(id, "/")
+	}
+	return ""
+}
+
+func readM3U(r io.Reader) (Playlist, error) {
+	var (
+		p       Playlist
+		pending Entry
+		info    bool // pending has an #EXTINF line
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			secs, display, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			// Attributes such as tvg-id="…" may follow the length.
+			secs, _, _ = strings.Cut(secs, " ")
+			pending, info = Entry{Title: strings.TrimSpace(display)}, true
+			if n, err := strconv.ParseFloat(secs, 64); err == nil && n > 0 {
+				pending.DurationMs = int(n * 1000)
+			}
+		case strings.HasPrefix(line, "#EXTALB:"):
+			pending.Album = strings.TrimSpace(strings.TrimPrefix(line, "#EXTALB:"))
+		case strings.HasPrefix(line, "#EXTART:"):
+			pending.Artists = []string{strings.TrimSpace(strings.TrimPrefix(line, "#EXTART:"))}
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			p.Name = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#"):
+		default:
+			e := pending
+			e.URI = spotifyURI(line)
+			if !info && e.URI == "" {
+				// A bare path: the file name is usually "artist - title".
+				name := filepath.Base(filepath.FromSlash(strings.ReplaceAll(line, `\`, "/")))
+				e.Title = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			p.Entries = append(p.Entries, e)
+			pending, info = Entry{}, false
+		}
+	}
+	return p, scanner.Err()
+}
+
+func readXSPF(r io.Reader) (Playlist, error) {
+	var doc xspfPlaylist
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return Playlist{}, err
+	}
+	p := Playlist{
+		Name:        strings.TrimSpace(doc.Title),
+		Description: strings.TrimSpace(doc.Annotation),
+		Owner:       strings.TrimSpace(doc.Creator),
+	}
+	if strings.HasPrefix(doc.Identifier, "spotify:playlist:") {
+		p.URI = doc.Identifier
+	}
+	for _, t := range doc.Tracks {
+		e := Entry{
+			Title:      strings.TrimSpace(t.Title),
+			Album:      strings.TrimSpace(t.Album),
+			DurationMs: t.Duration,
+			URI:        spotifyURI(strings.TrimSpace(t.Location)),
+		}
+		if creator := strings.TrimSpace(t.Creator); creator != "" {
+			e.Artists = []string{creator}
+		}
+		if isrc, ok := strings.CutPrefix(strings.TrimSpace(t.Identifier), "isrc:"); ok {
+			e.ISRC = strings.ToUpper(isrc)
+		}
+		if e.Title == "" && e.URI == "" && t.Location != "" {
+			name := filepath.Base(t.Location)
+			if unescaped, err := url.PathUnescape(name); err == nil {
+				name = unescaped
+			}
+			e.Title = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		p.Entries = append(p.Entries, e)
+	}
+	return p, nil
+}
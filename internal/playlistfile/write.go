@@ -0,0 +1,115 @@
+package playlistfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvHeader are the columns of a CSV file. Artists are separated by "; "
+// since artist names can contain commas.
+var csvHeader = []string{"title", "artists", "album", "duration_ms", "uri", "isrc", "added_at"}
+
+func writeCSV(w io.Writer, p Playlist) error {
+	out := csv.NewWriter(w)
+	rows := [][]string{csvHeader}
+	for _, e := range p.Entries {
+		rows = append(rows, []string{e.Title, strings.Join(e.Artists, "; "), e.Album, strconv.Itoa(e.DurationMs), e.URI, e.ISRC, e.AddedAt})
+	}
+	return out.WriteAll(rows)
+}
+
+func writeJSON(w io.Writer, p Playlist) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// writeM3U8 writes an extended M3U in UTF-8: each track is an #EXTINF line
+// with its length in seconds and "artist - title", then its Spotify URI.
+func writeM3U8(w io.Writer, p Playlist) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "#EXTM3U")
+	if p.Name != "" {
+		fmt.Fprintf(out, "#PLAYLIST:%s\n", oneLine(p.Name))
+	}
+	for _, e := range p.Entries {
+		fmt.Fprintf(out, "#EXTINF:%d,%s - %s\n", (e.DurationMs+500)/1000, oneLine(e.Artist()), oneLine(e.Title))
+		if e.Album != "" {
+			fmt.Fprintf(out, "#EXTALB:%s\n", oneLine(e.Album))
+		}
+		fmt.Fprintln(out, e.URI)
+	}
+	return out.Flush()
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// xspfNamespace is the XML namespace of XSPF version 1.
+const xspfNamespace = "http://xspf.org/ns/0/"
+
+type xspfPlaylist struct {
+	XMLName    xml.Name    `xml:"playlist"`
+	Version    int         `xml:"version,attr"`
+	Namespace  string      `xml:"xmlns,attr"`
+	Title      string      `xml:"title,omitempty"`
+	Creator    string      `xml:"creator,omitempty"`
+	Annotation string      `xml:"annotation,omitempty"`
+	Identifier string      `xml:"identifier,omitempty"`
+	Tracks     []xspfTrack `xml:"trackList>track"`
+}
+
+// xspfTrack keeps the Spotify URI as the location and the ISRC, when
+// known, as an isrc: identifier.
+type xspfTrack struct {
+	Location   string `xml:"location,omitempty"`
+	Identifier string `xml:"identifier,omitempty"`
+	Title      string `xml:"title,omitempty"`
+	Creator    string `xml:"creator,omitempty"`
+	Album      string `xml:"album,omitempty"`
+	Duration   int    `xml:"duration,omitempty"` // milliseconds
+}
+
+func writeXSPF(w io.Writer, p Playlist) error {
+	doc := xspfPlaylist{
+		Version:    1,
+		Namespace:  xspfNamespace,
+		Title:      p.Name,
+		Creator:    p.Owner,
+		Annotation: p.Description,
+		Identifier: p.URI,
+		Tracks:     make([]xspfTrack, 0, len(p.Entries)),
+	}
+	for _, e := range p.Entries {
+		t := xspfTrack{
+			Location: e.URI,
+			Title:    e.Title,
+			Creator:  e.Artist(),
+			Album:    e.Album,
+			Duration: e.DurationMs,
+		}
+		if e.ISRC != "" {
+			t.Identifier = "isrc:" + e.ISRC
+		}
+		doc.Tracks = append(doc.Tracks, t)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
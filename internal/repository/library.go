@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// Cache kinds used by LibraryRepository.
+const (
+	KindPlaylists = "playlists"
+	KindTracks    = "tracks"
+	KindAlbums    = "albums"
+	KindArtists   = "artists"
+)
+
+// playlistsKey is the single entry holding the user's playlist list.
+const playlistsKey = "me"
+
+// tracksFormat is bumped whenever entities.Track gains fields, so tracks
+// cached by an older version are refetched instead of shown incomplete.
+const tracksFormat = "3"
+
+// catalogFormat versions cached albums and artists the way tracksFormat
+// does tracks. Neither has a snapshot_id, so an entry is also trusted only
+// for catalogMaxAge: names, artwork and genres do change, but rarely.
+const (
+	catalogFormat = "1"
+	catalogMaxAge = 30 * 24 * time.Hour
+)
+
+func tracksVersion(snapshotID string) string {
+	return snapshotID + "@" + tracksFormat
+}
+
+// LibraryRepository stores library and catalog data in a CacheRepository.
+// Playlist tracks are versioned by the playlist's snapshot_id; albums and
+// artists are keyed by their Spotify ID.
+type LibraryRepository struct {
+	cache *CacheRepository
+}
+
+func NewLibraryRepository(cache *CacheRepository) *LibraryRepository {
+	return &LibraryRepository{
+		cache: cache,
+	}
+}
+
+func (r *LibraryRepository) Playlists() ([]entities.Playlist, bool) {
+	var out []entities.Playlist
+	_, ok, err := r.cache.Get(KindPlaylists, playlistsKey, &out)
+	return out, ok && err == nil
+}
+
+func (r *LibraryRepository) SavePlaylists(playlists []entities.Playlist) error {
+	return r.cache.Put(KindPlaylists, playlistsKey, "", playlists)
+}
+
+// PlaylistTracks returns the cached tracks of a playlist. fresh reports
+// whether they were stored for snapshotID, i.e. the playlist has not changed
+// since.
+func (r *LibraryRepository) PlaylistTracks(playlistID, snapshotID string) (tracks []entities.Track, fresh bool, ok bool) {
+	entry, ok, err := r.cache.Get(KindTracks, playlistID, &tracks)
+	if err != nil || !ok {
+		return nil, false, false
+	}
+
+	albums := map[string]entities.Album{}
+	for i, t := range tracks {
+		if t.Album.ID == "" || t.Album.Name != "" {
+			continue
+		}
+		album, found := albums[t.Album.ID]
+		if !found {
+			// An album evicted on its own leaves the tracks incomplete, which
+			// is as good as having none.
+			if _, found, err := r.cache.Get(KindAlbums, t.Album.ID, &album); err != nil || !found {
+				return nil, false, false
+			}
+			albums[t.Album.ID] = album
+		}
+		tracks[i].Album = album
+	}
+	return tracks, snapshotID != "" && entry.Version == tracksVersion(snapshotID), true
+}
+
+// SavePlaylistTracks stores the tracks of a playlist. Their albums are
+// stored once each by ID, shared between playlists, and only rewritten once
+// they are older than catalogMaxAge.
+func (r *LibraryRepository) SavePlaylistTracks(playlistID, snapshotID string, tracks []entities.Track) error {
+	stored := make([]entities.Track, len(tracks))
+	var items []cacheItem
+	seen := map[string]bool{}
+	for i, t := range tracks {
+		if id := t.Album.ID; id != "" {
+			if !seen[id] {
+				seen[id] = true
+				var cached entities.Album
+				if !r.current(KindAlbums, id, &cached) {
+					items = append(items, cacheItem{kind: KindAlbums, id: id, version: catalogFormat, value: t.Album})
+				}
+			}
+			t.Album = entities.Album{ID: id}
+		}
+		stored[i] = t
+	}
+	items = append(items, cacheItem{kind: KindTracks, id: playlistID, version: tracksVersion(snapshotID), value: stored})
+	return r.cache.putAll(items)
+}
+
+// Artists returns the cached full artists among ids and the IDs it holds no
+// current entry for.
+func (r *LibraryRepository) Artists(ids []string) (found []entities.Artist, missing []string) {
+	for _, id := range ids {
+		var artist entities.Artist
+		if r.current(KindArtists, id, &artist) {
+			found = append(found, artist)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing
+}
+
+// SaveArtists stores full artists, genres included, by ID.
+func (r *LibraryRepository) SaveArtists(artists []entities.Artist) error {
+	items := make([]cacheItem, 0, len(artists))
+	for _, a := range artists {
+		if a.ID != "" {
+			items = append(items, cacheItem{kind: KindArtists, id: a.ID, version: catalogFormat, value: a})
+		}
+	}
+	return r.cache.putAll(items)
+}
+
+// current decodes the album or artist kind/id into v and reports whether it
+// was stored in this format within catalogMaxAge.
+func (r *LibraryRepository) current(kind, id string, v interface{}) bool {
+	entry, ok, err := r.cache.Get(kind, id, v)
+	return err == nil && ok && entry.Version == catalogFormat && time.Since(entry.StoredAt) < catalogMaxAge
+}
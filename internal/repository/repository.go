@@ -1 +1,285 @@
 package repository
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheRepository is a size-limited JSON store on disk. Entries are grouped
+// by kind (e.g. "playlists") and carry a version string such as a Spotify
+// snapshot_id, so callers can tell whether what they got is still current.
+// When the store grows past maxBytes the least recently read entries are
+// evicted.
+type CacheRepository struct {
+	dir      string
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+// CacheEntry describes a stored value.
+type CacheEntry struct {
+	Version  string    `json:"version"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+type cacheFile struct {
+	CacheEntry
+	Data json.RawMessage `json:"data"`
+}
+
+func NewCacheRepository(dir string, maxBytes int64) *CacheRepository {
+	return &CacheRepository{
+		dir:      dir,
+		maxBytes: maxBytes,
+	}
+}
+
+// Get decodes the entry kind/id into v. ok is false when there is no entry.
+func (r *CacheRepository) Get(kind, id string, v interface{}) (CacheEntry, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := r.path(kind, id)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return CacheEntry{}, false, nil
+	}
+	if err != nil {
+		return CacheEntry{}, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var f cacheFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		// A corrupt entry is as good as a missing one.
+		os.Remove(path)
+		return CacheEntry{}, false, nil
+	}
+	if err := json.Unmarshal(f.Data, v); err != nil {
+		os.Remove(path)
+		return CacheEntry{}, false, nil
+	}
+
+	// Bump the modification time so eviction treats it as recently used.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return f.CacheEntry, true, nil
+}
+
+// Put stores v under kind/id with the given version.
+func (r *CacheRepository) Put(kind, id, version string, v interface{}) error {
+	return r.putAll([]cacheItem{{kind: kind, id: id, version: version, value: v}})
+}
+
+// Overwrite stores v like Put without enforcing the size limit, for a small
+// entry rewritten so often that scanning the whole cache each time would
+// cost more than the entry. The next Put evicts as usual.
+func (r *CacheRepository) Overwrite(kind, id, version string, v interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.write(kind, id, version, v)
+}
+
+type cacheItem struct {
+	kind    string
+	id      string
+	version string
+	value   interface{}
+}
+
+// putAll writes several entries and runs eviction once at the end.
+func (r *CacheRepository) putAll(items []cacheItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range items {
+		if err := r.write(item.kind, item.id, item.version, item.value); err != nil {
+			return err
+		}
+	}
+	return r.evict()
+}
+
+func (r *CacheRepository) write(kind, id, version string, v interface{}) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	data, err := json.Marshal(cacheFile{
+		CacheEntry: CacheEntry{Version: version, StoredAt: time.Now()},
+		Data:       payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	path := r.path(kind, id)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temp file first so readers never see a partial entry.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Delete removes kind/id if present.
+func (r *CacheRepository) Delete(kind, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.Remove(r.path(kind, id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every entry. Only files the cache wrote are touched, so a
+// cache dir pointed at a folder that holds anything else keeps it; kind
+// directories left empty are removed.
+func (r *CacheRepository) Clear() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	files, err := r.files()
+	if err != nil {
+		return err
+	}
+	dirs := map[string]bool{}
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		dirs[filepath.Dir(f.path)] = true
+	}
+	for dir := range dirs {
+		// Fails, as it should, when the directory still holds other files.
+		_ = os.Remove(dir)
+	}
+	return nil
+}
+
+// Stats returns the number of entries and their total size in bytes.
+func (r *CacheRepository) Stats() (int, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	files, err := r.files()
+	if err != nil {
+		return 0, 0, err
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	return len(files), total, nil
+}
+
+func (r *CacheRepository) Dir() string {
+	return r.dir
+}
+
+type cachedFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (r *CacheRepository) files() ([]cachedFile, error) {
+	var out []cachedFile
+	err := filepath.WalkDir(r.dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !r.isEntry(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		out = append(out, cachedFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan cache: %w", err)
+	}
+	return out, nil
+}
+
+// evict deletes the least recently used entries until the cache fits in
+// maxBytes. A limit of zero disables eviction.
+func (r *CacheRepository) evict() error {
+	if r.maxBytes <= 0 {
+		return nil
+	}
+
+	files, err := r.files()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	if total <= r.maxBytes {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= r.maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to evict cache entry: %w", err)
+		}
+		total -= f.size
+	}
+	return nil
+}
+
+// isEntry reports whether path is where path puts entries: a hashed name in
+// a kind directory.
+func (r *CacheRepository) isEntry(p string) bool {
+	if filepath.Dir(filepath.Dir(p)) != filepath.Clean(r.dir) {
+		return false
+	}
+	name, ok := strings.CutSuffix(filepath.Base(p), ".json")
+	if !ok || len(name) != 2*sha1.Size {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+// path keeps IDs out of file names so any string is a safe key.
+func (r *CacheRepository) path(kind, id string) string {
+	sum := sha1.Sum([]byte(id))
+	return filepath.Join(r.dir, kind, hex.EncodeToString(sum[:])+".json")
+}
@@ -23,8 +23,10 @@ func NewTokenRepository(tokenPath string) *TokenRepository {
 type TokenData struct {
 	AccessToken  string    `json:"access_token"`
 	TokenType    string    `json:"token_type"`
-	RefreshToken  string    `json:"refresh_token"`
+	RefreshToken string    `json:"refresh_token"`
 	Expiry       time.Time `json:"expiry"`
+	// Scope is the space-separated scopes the token was granted.
+	Scope string `json:"scope,omitempty"`
 }
 
 func (r *TokenRepository) Save(token *oauth2.Token) error {
@@ -33,11 +35,20 @@ func (r *TokenRepository) Save(token *oauth2.Token) error {
 		return fmt.Errorf("failed to create token directory: %w", err)
 	}
 
+	// A refresh need not repeat the scopes; they stay as granted at login.
+	scope, _ := token.Extra("scope").(string)
+	if scope == "" {
+		if saved, err := r.Load(); err == nil {
+			scope, _ = saved.Extra("scope").(string)
+		}
+	}
+
 	data := TokenData{
 		AccessToken:  token.AccessToken,
 		TokenType:    token.TokenType,
-		RefreshToken:  token.RefreshToken,
+		RefreshToken: token.RefreshToken,
 		Expiry:       token.Expiry,
+		Scope:        scope,
 	}
 
 	jsonData, err := json.MarshalIndent(data, "", "  ")
@@ -63,12 +74,13 @@ func (r *TokenRepository) Load() (*oauth2.Token, error) {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
-	return &oauth2.Token{
+	token := &oauth2.Token{
 		AccessToken:  tokenData.AccessToken,
 		TokenType:    tokenData.TokenType,
-		RefreshToken:  tokenData.RefreshToken,
+		RefreshToken: tokenData.RefreshToken,
 		Expiry:       tokenData.Expiry,
-	}, nil
+	}
+	return token.WithExtra(map[string]interface{}{"scope": tokenData.Scope}), nil
 }
 
 func (r *TokenRepository) Delete() error {
@@ -0,0 +1,151 @@
+package scrobble
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestLastFMSign(t *testing.T) {
+	l := NewLastFM("", "key", "secret", "")
+	params := url.Values{"method": {"auth.getSession"}, "api_key": {"key"}, "token": {"tok"}, "format": {"json"}}
+	// md5("api_keykeymethodauth.getSessiontokentoksecret"); format is left out.
+	if got, want := l.sign(params), "04e870be4bb79756721b7bc1937fe83d"; got != want {
+		t.Errorf("sign = %s, want %s", got, want)
+	}
+}
+
+func TestLastFMSubmit(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("got %s with %s, want a form POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		r.ParseForm()
+		form = r.PostForm
+		io.WriteString(w, `{"scrobbles":{}}`)
+	}))
+	defer srv.Close()
+
+	l := NewLastFM(srv.URL, "key", "secret", "session")
+	batch := []Scrobble{
+		{Track: "One", Artists: []string{"A", "B"}, Album: "Album", DurationMs: 200_500, StartedAt: time.Unix(1700000000, 0)},
+		{Track: "Two", Artists: []string{"C"}, DurationMs: 60_000, StartedAt: time.Unix(1700000300, 0)},
+	}
+	if err := l.Submit(context.Background(), batch); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	want := map[string]string{
+		"method":       "track.scrobble",
+		"api_key":      "key",
+		"sk":           "session",
+		"format":       "json",
+		"artist[0]":    "A",
+		"track[0]":     "One",
+		"album[0]":     "Album",
+		"timestamp[0]": "1700000000",
+		"duration[0]":  "200",
+		"artist[1]":    "C",
+		"track[1]":     "Two",
+		"timestamp[1]": "1700000300",
+		"album[1]":     "",
+	}
+	for k, v := range want {
+		if got := form.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	sig := form.Get("api_sig")
+	form.Del("api_sig")
+	if want := l.sign(form); sig != want {
+		t.Errorf("api_sig = %s, want %s", sig, want)
+	}
+}
+
+func TestLastFMErrors(t *testing.T) {
+	tests := []struct {
+		body     string
+		rejected bool
+	}{
+		{`{"error":6,"message":"Invalid parameters"}`, true},
+		{`{"error":9,"message":"Invalid session key"}`, false},
+		{`{"error":16,"message":"Service temporarily unavailable"}`, false},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, tt.body)
+		}))
+		err := NewLastFM(srv.URL, "key", "secret", "session").Submit(context.Background(), scrobbles(1))
+		srv.Close()
+		if err == nil || errors.Is(err, ErrRejected) != tt.rejected {
+			t.Errorf("%s: err = %v, want rejected=%v", tt.body, err, tt.rejected)
+		}
+	}
+}
+
+func TestListenBrainzSubmit(t *testing.T) {
+	var (
+		auth string
+		sub  lbSubmission
+	)
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/submit-listens" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		sub = lbSubmission{}
+		json.NewDecoder(r.Body).Decode(&sub)
+		w.WriteHeader(status)
+		io.WriteString(w, `{"error":"bad listen"}`)
+	}))
+	defer srv.Close()
+	l := NewListenBrainz(srv.URL+"/", "tok")
+
+	one := Scrobble{Track: "One", Artists: []string{"A", "B"}, Album: "Album", DurationMs: 200_500, SpotifyID: "abc", StartedAt: time.Unix(1700000000, 0)}
+	if err := l.Submit(context.Background(), []Scrobble{one}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if auth != "Token tok" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if sub.ListenType != "single" || len(sub.Payload) != 1 {
+		t.Fatalf("submitted %+v, want one single listen", sub)
+	}
+	got := sub.Payload[0]
+	if got.ListenedAt != 1700000000 || got.TrackMetadata.ArtistName != "A, B" || got.TrackMetadata.ReleaseName != "Album" ||
+		got.TrackMetadata.AdditionalInfo.SpotifyID != "https://open.spotify.com/track/abc" ||
+		got.TrackMetadata.AdditionalInfo.DurationMs != 200_500 {
+		t.Errorf("listen = %+v", got)
+	}
+
+	if err := l.Submit(context.Background(), scrobbles(3)); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if sub.ListenType != "import" || len(sub.Payload) != 3 {
+		t.Errorf("submitted %s of %d, want an import of 3", sub.ListenType, len(sub.Payload))
+	}
+
+	if err := l.NowPlaying(context.Background(), one); err != nil {
+		t.Fatalf("NowPlaying: %v", err)
+	}
+	if sub.ListenType != "playing_now" || sub.Payload[0].ListenedAt != 0 {
+		t.Errorf("now playing sent %+v, want playing_now without listened_at", sub)
+	}
+
+	status = http.StatusBadRequest
+	if err := l.Submit(context.Background(), scrobbles(1)); !errors.Is(err, ErrRejected) {
+		t.Errorf("400: err = %v, want rejected", err)
+	}
+	status = http.StatusUnauthorized
+	if err := l.Submit(context.Background(), scrobbles(1)); err == nil || errors.Is(err, ErrRejected) {
+		t.Errorf("401: err = %v, want a retryable error", err)
+	}
+}
@@ -0,0 +1,178 @@
+package scrobble
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultLastFMURL is the Last.fm API endpoint.
+const DefaultLastFMURL = "https://ws.audioscrobbler.com/2.0/"
+
+// lastFMAuthURL is where the user grants an application access.
+const lastFMAuthURL = "https://www.last.fm/api/auth/"
+
+// Last.fm error codes that retrying cannot fix. Everything else, such as
+// an invalid session or the service being down, is worth another try once
+// the config is fixed or the service is back.
+var lastFMRejected = []int{
+	6, // invalid parameters
+	7, // invalid resource
+}
+
+// LastFM scrobbles to Last.fm with an API account and a session key
+// obtained through Token, AuthURL and Session.
+type LastFM struct {
+	apiURL  string
+	key     string
+	secret  string
+	session string
+	http    *http.Client
+}
+
+// NewLastFM talks to apiURL, or Last.fm itself when it is empty.
+func NewLastFM(apiURL, key, secret, session string) *LastFM {
+	if apiURL == "" {
+		apiURL = DefaultLastFMURL
+	}
+	return &LastFM{apiURL: apiURL, key: key, secret: secret, session: session, http: http.DefaultClient}
+}
+
+func (l *LastFM) Name() string { return "lastfm" }
+
+// BatchSize is the most scrobbles Last.fm takes in one call.
+func (l *LastFM) BatchSize() int { return 50 }
+
+func (l *LastFM) NowPlaying(ctx context.Context, s Scrobble) error {
+	params := url.Values{}
+	params.Set("artist", s.Artist())
+	params.Set("track", s.Track)
+	if s.Album != "" {
+		params.Set("album", s.Album)
+	}
+	params.Set("duration", strconv.Itoa(s.DurationMs/1000))
+	return l.call(ctx, "track.updateNowPlaying", params, nil)
+}
+
+func (l *LastFM) Submit(ctx context.Context, batch []Scrobble) error {
+	params := url.Values{}
+	for i, s := range batch {
+		key := func(name string) string { return fmt.Sprintf("%s[%d]", name, i) }
+		params.Set(key("artist"), s.Artist())
+		params.Set(key("track"), s.Track)
+		params.Set(key("timestamp"), strconv.FormatInt(s.StartedAt.Unix(), 10))
+		if s.Album != "" {
+			params.Set(key("album"), s.Album)
+		}
+		params.Set(key("duration"), strconv.Itoa(s.DurationMs/1000))
+	}
+	// Scrobbles Last.fm ignores, such as ones older than two weeks, are
+	// still answered with success and are not worth keeping.
+	return l.call(ctx, "track.scrobble", params, nil)
+}
+
+// Token starts the login: the user approves it at AuthURL, then Session
+// swaps it for a session key.
+func (l *LastFM) Token(ctx context.Context) (string, error) {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := l.call(ctx, "auth.getToken", url.Values{}, &resp); err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+// AuthURL is the page where the user approves token.
+func (l *LastFM) AuthURL(token string) string {
+	return lastFMAuthURL + "?" + url.Values{"api_key": {l.key}, "token": {token}}.Encode()
+}
+
+// Session returns the session key and user name for an approved token.
+func (l *LastFM) Session(ctx context.Context, token string) (key, user string, err error) {
+	var resp struct {
+		Session struct {
+			Name string `json:"name"`
+			Key  string `json:"key"`
+		} `json:"session"`
+	}
+	if err := l.call(ctx, "auth.getSession", url.Values{"token": {token}}, &resp); err != nil {
+		return "", "", err
+	}
+	return resp.Session.Key, resp.Session.Name, nil
+}
+
+// call makes a signed API call, decoding the response into out if it is
+// not nil.
+func (l *LastFM) call(ctx context.Context, method string, params url.Values, out any) error {
+	params.Set("method", method)
+	params.Set("api_key", l.key)
+	if l.session != "" && !strings.HasPrefix(method, "auth.") {
+		params.Set("sk", l.session)
+	}
+	params.Set("api_sig", l.sign(params))
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.apiURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := l.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("last.fm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read last.fm response: %w", err)
+	}
+
+	var apiErr struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != 0 {
+		if slices.Contains(lastFMRejected, apiErr.Error) {
+			return fmt.Errorf("%w by last.fm: %s (error %d)", ErrRejected, apiErr.Message, apiErr.Error)
+		}
+		return fmt.Errorf("last.fm: %s (error %d)", apiErr.Message, apiErr.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("last.fm returned %s", resp.Status)
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse last.fm response: %w", err)
+		}
+	}
+	return nil
+}
+
+// sign is the md5 of every parameter name and value in name order followed
+// by the secret, as Last.fm expects in api_sig.
+func (l *LastFM) sign(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if k != "format" && k != "callback" {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteString(params.Get(k))
+	}
+	b.WriteString(l.secret)
+	sum := md5.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package scrobble
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultListenBrainzURL is the ListenBrainz API root.
+const DefaultListenBrainzURL = "https://api.listenbrainz.org"
+
+// ListenBrainz submits listens with a user token.
+type ListenBrainz struct {
+	apiURL string
+	token  string
+	http   *http.Client
+}
+
+// NewListenBrainz talks to apiURL, or ListenBrainz itself when it is empty.
+func NewListenBrainz(apiURL, token string) *ListenBrainz {
+	if apiURL == "" {
+		apiURL = DefaultListenBrainzURL
+	}
+	return &ListenBrainz{apiURL: strings.TrimSuffix(apiURL, "/"), token: token, http: http.DefaultClient}
+}
+
+func (l *ListenBrainz) Name() string { return "listenbrainz" }
+
+// BatchSize keeps imports well under the API's request size limit.
+func (l *ListenBrainz) BatchSize() int { return 100 }
+
+type lbSubmission struct {
+	ListenType string     `json:"listen_type"`
+	Payload    []lbListen `json:"payload"`
+}
+
+type lbListen struct {
+	ListenedAt    int64           `json:"listened_at,omitempty"`
+	TrackMetadata lbTrackMetadata `json:"track_metadata"`
+}
+
+type lbTrackMetadata struct {
+	ArtistName     string           `json:"artist_name"`
+	TrackName      string           `json:"track_name"`
+	ReleaseName    string           `json:"release_name,omitempty"`
+	AdditionalInfo lbAdditionalInfo `json:"additional_info"`
+}
+
+type lbAdditionalInfo struct {
+	Artists          []string `json:"artist_names,omitempty"`
+	DurationMs       int      `json:"duration_ms,omitempty"`
+	SpotifyID        string   `json:"spotify_id,omitempty"`
+	MusicService     string   `json:"music_service"`
+	SubmissionClient string   `json:"submission_client"`
+}
+
+func newListen(s Scrobble) lbListen {
+	listen := lbListen{TrackMetadata: lbTrackMetadata{
+		ArtistName:  strings.Join(s.Artists, ", "),
+		TrackName:   s.Track,
+		ReleaseName: s.Album,
+		AdditionalInfo: lbAdditionalInfo{
+			Artists:          s.Artists,
+			DurationMs:       s.DurationMs,
+			MusicService:     "spotify.com",
+			SubmissionClient: "spotify-tui",
+		},
+	}}
+	if s.SpotifyID != "" {
+		listen.TrackMetadata.AdditionalInfo.SpotifyID = "https://open.spotify.com/track/" + s.SpotifyID
+	}
+	return listen
+}
+
+func (l *ListenBrainz) NowPlaying(ctx context.Context, s Scrobble) error {
+	return l.submit(ctx, lbSubmission{ListenType: "playing_now", Payload: []lbListen{newListen(s)}})
+}
+
+func (l *ListenBrainz) Submit(ctx context.Context, batch []Scrobble) error {
+	sub := lbSubmission{ListenType: "single"}
+	if len(batch) > 1 {
+		sub.ListenType = "import"
+	}
+	for _, s := range batch {
+		listen := newListen(s)
+		listen.ListenedAt = s.StartedAt.Unix()
+		sub.Payload = append(sub.Payload, listen)
+	}
+	return l.submit(ctx, sub)
+}
+
+func (l *ListenBrainz) submit(ctx context.Context, sub lbSubmission) error {
+	body, err := json.Marshal(sub)
+	if err != nil {
+		return fmt.Errorf("failed to encode listens: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.apiURL+"/1/submit-listens", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+l.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := l.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("listenbrainz request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	respBody, _ := io.ReadAll(resp.Body)
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	msg := resp.Status
+	if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+		msg = apiErr.Error
+	}
+	// A bad request will be just as bad next time; a bad token, rate limit
+	// or outage may not be.
+	if resp.StatusCode == http.StatusBadRequest {
+		return fmt.Errorf("%w by listenbrainz: %s", ErrRejected, msg)
+	}
+	return fmt.Errorf("listenbrainz: %s", msg)
+}
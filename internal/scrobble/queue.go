@@ -0,0 +1,189 @@
+package scrobble
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// minBackoff and maxBackoff bound the wait before retrying a service
+	// that could not be reached.
+	minBackoff = time.Minute
+	maxBackoff = 30 * time.Minute
+)
+
+// queueFile is what a queue keeps on disk.
+type queueFile struct {
+	Pending     []Scrobble `json:"pending"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt time.Time  `json:"last_error_at,omitzero"`
+}
+
+// queue holds one backend's scrobbles until it accepts them.
+type queue struct {
+	backend Backend
+	path    string
+	timeout time.Duration
+	log     *log.Logger
+	wake    chan struct{}
+
+	mu   sync.Mutex
+	file queueFile
+}
+
+func queuePath(dir string, b Backend) string {
+	return filepath.Join(dir, b.Name()+".json")
+}
+
+func openQueue(b Backend, dir string, timeout time.Duration, logger *log.Logger) (*queue, error) {
+	path := queuePath(dir, b)
+	f, err := readQueue(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return &queue{
+		backend: b,
+		path:    path,
+		timeout: timeout,
+		log:     logger,
+		wake:    make(chan struct{}, 1),
+		file:    f,
+	}, nil
+}
+
+// readQueue loads a queue file; a missing one is empty.
+func readQueue(path string) (queueFile, error) {
+	var f queueFile
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, fmt.Errorf("failed to read scrobble queue: %w", err)
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("failed to parse scrobble queue %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// save writes the queue through a temporary file so a crash leaves the old
+// copy whole. Callers hold mu.
+func (q *queue) save() error {
+	data, err := json.Marshal(q.file)
+	if err != nil {
+		return fmt.Errorf("failed to encode scrobble queue: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create scrobble queue directory: %w", err)
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write scrobble queue: %w", err)
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return fmt.Errorf("failed to write scrobble queue: %w", err)
+	}
+	return nil
+}
+
+// add queues s and wakes the submitter.
+func (q *queue) add(s Scrobble) {
+	q.mu.Lock()
+	q.file.Pending = append(q.file.Pending, s)
+	if err := q.save(); err != nil {
+		q.log.Printf("scrobble: %s: %v", q.backend.Name(), err)
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run submits queued scrobbles until ctx is done, backing off while the
+// service cannot be reached.
+func (q *queue) run(ctx context.Context) {
+	backoff := time.Duration(0)
+	for {
+		if err := q.flush(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			backoff = min(max(backoff*2, minBackoff), maxBackoff)
+			q.log.Printf("scrobble: %s: %v, retrying in %s", q.backend.Name(), err, backoff)
+		} else {
+			backoff = 0
+		}
+
+		var (
+			retry <-chan time.Time
+			timer *time.Timer
+		)
+		if backoff > 0 {
+			timer = time.NewTimer(backoff)
+			retry = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-q.wake:
+		case <-retry:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// flush submits everything queued, a batch at a time. A rejected batch is
+// dropped; any other failure stops the flush and keeps the rest queued.
+func (q *queue) flush(ctx context.Context) error {
+	for {
+		q.mu.Lock()
+		n := min(len(q.file.Pending), q.backend.BatchSize())
+		batch := append([]Scrobble(nil), q.file.Pending[:n]...)
+		q.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx, q.timeout)
+		err := q.backend.Submit(reqCtx, batch)
+		cancel()
+
+		q.mu.Lock()
+		switch {
+		case err == nil:
+			q.file.LastError, q.file.LastErrorAt = "", time.Time{}
+		case errors.Is(err, ErrRejected):
+			q.log.Printf("scrobble: %s: dropped %d scrobbles: %v", q.backend.Name(), n, err)
+			q.file.LastError, q.file.LastErrorAt = err.Error(), time.Now()
+		default:
+			q.file.LastError, q.file.LastErrorAt = err.Error(), time.Now()
+			if saveErr := q.save(); saveErr != nil {
+				q.log.Printf("scrobble: %s: %v", q.backend.Name(), saveErr)
+			}
+			q.mu.Unlock()
+			return err
+		}
+		// Scrobbles added while submitting sit after the batch.
+		q.file.Pending = q.file.Pending[n:]
+		saveErr := q.save()
+		q.mu.Unlock()
+		if saveErr != nil {
+			return saveErr
+		}
+	}
+}
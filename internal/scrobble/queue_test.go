@@ -0,0 +1,71 @@
+package scrobble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func scrobbles(n int) []Scrobble {
+	out := make([]Scrobble, n)
+	for i := range out {
+		out[i] = Scrobble{Track: fmt.Sprint("track ", i), Artists: []string{"Artist"}, StartedAt: time.Unix(int64(i), 0).UTC()}
+	}
+	return out
+}
+
+func TestQueueFlush(t *testing.T) {
+	dir := t.TempDir()
+	b := &fakeBackend{batchSize: 2, fail: []error{nil, fmt.Errorf("%w: bad", ErrRejected), errors.New("503 Service Unavailable")}}
+	q, err := openQueue(b, dir, time.Second, discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range scrobbles(7) {
+		q.add(s)
+	}
+
+	// The first batch goes through, the second is rejected and dropped, and
+	// the outage keeps the rest.
+	if err := q.flush(context.Background()); err == nil {
+		t.Fatal("flush succeeded through an outage")
+	}
+	if len(b.submitted) != 1 || b.submitted[0][0].Track != "track 0" {
+		t.Errorf("submitted %v, want only the first batch", b.submitted)
+	}
+	if got := queued(q); len(got) != 3 || got[0].Track != "track 4" {
+		t.Fatalf("kept %v, want tracks 4 to 6", got)
+	}
+
+	// What is left survives a restart and is sent once the service is back.
+	reopened, err := openQueue(b, dir, time.Second, discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := queued(reopened); len(got) != 3 {
+		t.Fatalf("reopened queue holds %d scrobbles, want 3", len(got))
+	}
+	status, err := Pending([]Backend{b}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status[0].Pending != 3 || status[0].LastError == "" {
+		t.Errorf("Pending = %+v, want 3 and the outage", status[0])
+	}
+
+	if err := reopened.flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got := queued(reopened); len(got) != 0 {
+		t.Errorf("kept %v after a clean flush", got)
+	}
+	if len(b.submitted) != 3 || len(b.submitted[1]) != 2 || len(b.submitted[2]) != 1 {
+		t.Errorf("submitted %v, want batches of 2 then 1", b.submitted)
+	}
+	status, _ = Pending([]Backend{b}, dir)
+	if status[0].Pending != 0 || status[0].LastError != "" {
+		t.Errorf("Pending = %+v, want empty with the error cleared", status[0])
+	}
+}
@@ -0,0 +1,241 @@
+// Package scrobble submits what is played to Last.fm and ListenBrainz:
+// "now playing" as a track starts, and a scrobble once enough of it was
+// heard. Scrobbles wait in a queue on disk until the service accepts them,
+// so nothing is lost while offline.
+package scrobble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+const (
+	// minTrackLength is the shortest track that is ever scrobbled.
+	minTrackLength = 30 * time.Second
+	// maxThreshold caps how much of a long track has to be heard; shorter
+	// ones need half their length.
+	maxThreshold = 4 * time.Minute
+	// restartMargin is how close to its end a track must have got for a
+	// jump back to the start to count as playing it again.
+	restartMargin = 10 * time.Second
+)
+
+// ErrRejected marks a submission the service refused outright, which
+// retrying cannot fix. Anything else is retried later.
+var ErrRejected = errors.New("rejected")
+
+// Scrobble is one listen, as sent to a service.
+type Scrobble struct {
+	Track      string    `json:"track"`
+	Artists    []string  `json:"artists"`
+	Album      string    `json:"album,omitempty"`
+	DurationMs int       `json:"duration_ms"`
+	SpotifyID  string    `json:"spotify_id"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// Artist is the main artist, which is what services match on.
+func (s Scrobble) Artist() string {
+	if len(s.Artists) == 0 {
+		return ""
+	}
+	return s.Artists[0]
+}
+
+func newScrobble(t entities.Track, started time.Time) Scrobble {
+	artists := make([]string, len(t.Artists))
+	for i, a := range t.Artists {
+		artists[i] = a.Name
+	}
+	return Scrobble{
+		Track:      t.Name,
+		Artists:    artists,
+		Album:      t.Album.Name,
+		DurationMs: t.DurationMs,
+		SpotifyID:  t.ID,
+		StartedAt:  started.UTC(),
+	}
+}
+
+// Backend is a scrobbling service.
+type Backend interface {
+	// Name identifies the service in logs and its queue file.
+	Name() string
+	NowPlaying(ctx context.Context, s Scrobble) error
+	// Submit sends up to BatchSize scrobbles at once. Errors wrapping
+	// ErrRejected drop the batch; others keep it queued.
+	Submit(ctx context.Context, batch []Scrobble) error
+	BatchSize() int
+}
+
+// Scrobbler follows playback and feeds every backend.
+type Scrobbler struct {
+	queues  []*queue
+	timeout time.Duration
+	log     *log.Logger
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	current *listen
+}
+
+// listen is the play in progress.
+type listen struct {
+	scrobble Scrobble
+	heard    time.Duration
+	state    *entities.PlaybackState // last state seen
+	seen     time.Time               // when state was seen
+}
+
+// New starts a scrobbler for backends, keeping each one's queue in dir.
+// timeout bounds every request.
+func New(backends []Backend, dir string, timeout time.Duration, logger *log.Logger) (*Scrobbler, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scrobbler{timeout: timeout, log: logger, cancel: cancel}
+	for _, b := range backends {
+		q, err := openQueue(b, dir, timeout, logger)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		s.queues = append(s.queues, q)
+	}
+	for _, q := range s.queues {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			q.run(ctx)
+		}()
+	}
+	return s, nil
+}
+
+// Watch scrobbles from a stream of playback changes until it is closed.
+func (s *Scrobbler) Watch(updates <-chan service.PlaybackUpdate) {
+	go func() {
+		for u := range updates {
+			if u.Err == nil {
+				s.Observe(u.State, time.Now())
+			}
+		}
+	}()
+}
+
+// Observe takes the state seen at now. A new track, or one resumed, is
+// sent as now playing; the play it ends is scrobbled if enough of it was
+// heard.
+func (s *Scrobbler) Observe(state *entities.PlaybackState, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if state != nil && state.Track.ID == "" {
+		state = nil
+	}
+	if c := s.current; c != nil {
+		wasPlaying := c.state.IsPlaying
+		position := c.advance(now)
+		restarted := state != nil && state.Track.ID == c.scrobble.SpotifyID &&
+			position >= c.scrobble.DurationMs-int(restartMargin.Milliseconds()) &&
+			state.ProgressMs < position-int(restartMargin.Milliseconds())
+		if state == nil || state.Track.ID != c.scrobble.SpotifyID || restarted {
+			s.finish(c)
+			s.current = nil
+		} else {
+			c.state, c.seen = state, now
+			if state.IsPlaying && !wasPlaying {
+				s.nowPlaying(c.scrobble)
+			}
+		}
+	}
+
+	if s.current == nil && state != nil {
+		started := now.Add(-time.Duration(state.ProgressMs) * time.Millisecond)
+		s.current = &listen{scrobble: newScrobble(state.Track, started), state: state, seen: now}
+		if state.IsPlaying {
+			s.nowPlaying(s.current.scrobble)
+		}
+	}
+}
+
+// advance adds the time since the last state to what was heard, if it was
+// playing, and returns the position reached.
+func (l *listen) advance(now time.Time) int {
+	position := l.state.ProgressMs
+	if l.state.IsPlaying {
+		elapsed := now.Sub(l.seen)
+		position += int(elapsed.Milliseconds())
+		l.heard += elapsed
+	}
+	l.seen = now
+	return position
+}
+
+// finish queues c when it was heard for long enough: half the track or
+// four minutes, whichever comes first, of a track over 30 seconds.
+func (s *Scrobbler) finish(c *listen) {
+	length := time.Duration(c.scrobble.DurationMs) * time.Millisecond
+	if length <= minTrackLength || c.heard < min(length/2, maxThreshold) {
+		return
+	}
+	for _, q := range s.queues {
+		q.add(c.scrobble)
+	}
+}
+
+// nowPlaying tells every service in the background; it is only a hint, so
+// failures are logged and not retried.
+func (s *Scrobbler) nowPlaying(sc Scrobble) {
+	for _, q := range s.queues {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+			defer cancel()
+			if err := q.backend.NowPlaying(ctx, sc); err != nil {
+				s.log.Printf("scrobble: %s: now playing: %v", q.backend.Name(), err)
+			}
+		}()
+	}
+}
+
+// Close scrobbles the play in progress if it qualifies and stops
+// submitting. Whatever is still queued is sent on the next start.
+func (s *Scrobbler) Close() {
+	s.mu.Lock()
+	if c := s.current; c != nil {
+		c.advance(time.Now())
+		s.finish(c)
+		s.current = nil
+	}
+	s.mu.Unlock()
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Pending reports how many scrobbles each backend's queue in dir holds
+// and the last error it met, without submitting anything.
+func Pending(backends []Backend, dir string) ([]QueueStatus, error) {
+	var out []QueueStatus
+	for _, b := range backends {
+		f, err := readQueue(queuePath(dir, b))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+		out = append(out, QueueStatus{Backend: b.Name(), Pending: len(f.Pending), LastError: f.LastError, LastErrorAt: f.LastErrorAt})
+	}
+	return out, nil
+}
+
+// QueueStatus describes one backend's queue.
+type QueueStatus struct {
+	Backend     string
+	Pending     int
+	LastError   string
+	LastErrorAt time.Time
+}
@@ -0,0 +1,197 @@
+package scrobble
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// fakeBackend records what it is sent and fails submissions with the
+// errors queued in fail, one per call.
+type fakeBackend struct {
+	batchSize int
+
+	mu         sync.Mutex
+	nowPlaying []Scrobble
+	submitted  [][]Scrobble
+	fail       []error
+}
+
+func (f *fakeBackend) Name() string { return "fake" }
+
+func (f *fakeBackend) BatchSize() int {
+	if f.batchSize == 0 {
+		return 50
+	}
+	return f.batchSize
+}
+
+func (f *fakeBackend) NowPlaying(ctx context.Context, s Scrobble) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nowPlaying = append(f.nowPlaying, s)
+	return nil
+}
+
+func (f *fakeBackend) Submit(ctx context.Context, batch []Scrobble) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.fail) > 0 {
+		err := f.fail[0]
+		f.fail = f.fail[1:]
+		if err != nil {
+			return err
+		}
+	}
+	f.submitted = append(f.submitted, batch)
+	return nil
+}
+
+var discard = log.New(io.Discard, "", 0)
+
+// newTestScrobbler returns a scrobbler whose queue is not submitted, so
+// what finished plays queued can be read back.
+func newTestScrobbler(t *testing.T) (*Scrobbler, *queue) {
+	t.Helper()
+	q, err := openQueue(&fakeBackend{}, t.TempDir(), time.Second, discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Scrobbler{queues: []*queue{q}, timeout: time.Second, log: discard}, q
+}
+
+func queued(q *queue) []Scrobble {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]Scrobble(nil), q.file.Pending...)
+}
+
+func track(id string, length time.Duration) entities.Track {
+	return entities.Track{
+		ID:         id,
+		Name:       "Track " + id,
+		DurationMs: int(length.Milliseconds()),
+		Artists:    []entities.Artist{{Name: "Artist"}},
+	}
+}
+
+func playing(t entities.Track, progress time.Duration) *entities.PlaybackState {
+	return &entities.PlaybackState{IsPlaying: true, Track: t, ProgressMs: int(progress.Milliseconds())}
+}
+
+func paused(t entities.Track, progress time.Duration) *entities.PlaybackState {
+	s := playing(t, progress)
+	s.IsPlaying = false
+	return s
+}
+
+func TestObserveThresholds(t *testing.T) {
+	tests := []struct {
+		name   string
+		length time.Duration
+		heard  time.Duration
+		want   bool
+	}{
+		{"half of a short track", 3 * time.Minute, 90 * time.Second, true},
+		{"just under half", 3 * time.Minute, 89 * time.Second, false},
+		{"four minutes of a long one", 20 * time.Minute, 4 * time.Minute, true},
+		{"under four minutes of a long one", 20 * time.Minute, 4*time.Minute - time.Second, false},
+		{"30 seconds is too short", 30 * time.Second, 30 * time.Second, false},
+		{"31 seconds is long enough", 31 * time.Second, 20 * time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, q := newTestScrobbler(t)
+			start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+			a, b := track("a", tt.length), track("b", 3*time.Minute)
+
+			s.Observe(playing(a, 0), start)
+			s.Observe(playing(b, 0), start.Add(tt.heard))
+
+			got := queued(q)
+			if (len(got) == 1) != tt.want {
+				t.Fatalf("queued %d scrobbles, want scrobbled=%v", len(got), tt.want)
+			}
+			if tt.want && (got[0].SpotifyID != "a" || !got[0].StartedAt.Equal(start)) {
+				t.Errorf("scrobbled %+v, want track a started at %v", got[0], start)
+			}
+		})
+	}
+}
+
+func TestObservePauseIsNotHeard(t *testing.T) {
+	s, q := newTestScrobbler(t)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := track("a", 3*time.Minute)
+
+	s.Observe(playing(a, 0), start)
+	s.Observe(paused(a, 60*time.Second), start.Add(60*time.Second))
+	// An hour on pause adds nothing.
+	s.Observe(playing(a, 60*time.Second), start.Add(time.Hour))
+	s.Observe(nil, start.Add(time.Hour+20*time.Second))
+	if got := queued(q); len(got) != 0 {
+		t.Fatalf("80s of a 3 minute track was scrobbled: %+v", got)
+	}
+
+	s.Observe(playing(a, 0), start.Add(2*time.Hour))
+	s.Observe(paused(a, 45*time.Second), start.Add(2*time.Hour+45*time.Second))
+	s.Observe(playing(a, 45*time.Second), start.Add(3*time.Hour))
+	s.Observe(nil, start.Add(3*time.Hour+45*time.Second))
+	if got := queued(q); len(got) != 1 {
+		t.Fatalf("90s heard around a pause queued %d scrobbles, want 1", len(got))
+	}
+}
+
+func TestObserveRestart(t *testing.T) {
+	s, q := newTestScrobbler(t)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := track("a", 3*time.Minute)
+
+	// Seeking back early in the track is the same play.
+	s.Observe(playing(a, 0), start)
+	s.Observe(playing(a, 0), start.Add(30*time.Second))
+	if got := queued(q); len(got) != 0 {
+		t.Fatalf("seeking back early scrobbled %+v", got)
+	}
+
+	// Back to the start from within the margin of the end is a new play.
+	s.Observe(playing(a, 175*time.Second), start.Add(205*time.Second))
+	s.Observe(playing(a, time.Second), start.Add(211*time.Second))
+	if got := queued(q); len(got) != 1 {
+		t.Fatalf("repeating the track queued %d scrobbles, want 1", len(got))
+	}
+	s.mu.Lock()
+	started := s.current.scrobble.StartedAt
+	s.mu.Unlock()
+	if want := start.Add(210 * time.Second); !started.Equal(want) {
+		t.Errorf("the repeat started at %v, want %v", started, want)
+	}
+}
+
+func TestObserveNowPlaying(t *testing.T) {
+	b := &fakeBackend{}
+	q, err := openQueue(b, t.TempDir(), time.Second, discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Scrobbler{queues: []*queue{q}, timeout: time.Second, log: discard}
+	start := time.Now()
+	a := track("a", 3*time.Minute)
+
+	s.Observe(paused(a, 0), start)
+	s.Observe(playing(a, 0), start.Add(time.Second))
+	s.Observe(playing(a, 5*time.Second), start.Add(6*time.Second))
+
+	// Now playing is sent in the background.
+	time.Sleep(200 * time.Millisecond)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.nowPlaying) != 1 || b.nowPlaying[0].SpotifyID != "a" {
+		t.Errorf("sent now playing for %+v, want once for a on resume", b.nowPlaying)
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+)
+
+// Playback is the playback control the TUI needs. PlaybackService talks to
+// Spotify itself; the daemon client forwards each call to a running daemon.
+type Playback interface {
+	GetCurrentPlaybackState() (*entities.PlaybackState, error)
+	GetQueue(ctx context.Context) ([]entities.Track, error)
+	UpNext() ([]entities.Track, error)
+	Devices() ([]entities.Device, error)
+	Play(trackURI string, playlistURI string) error
+	PausePlayback() error
+	ResumePlayback() error
+	NextTrack() error
+	PreviousTrack() error
+	SeekTo(positionMs int) error
+	SetVolume(percent int) error
+	VolumeUp(current int, step int) error
+	VolumeDown(current int, step int) error
+	ToggleShufflePlayback(state bool) error
+	ToggleRepeatPlayback(state string) error
+	TransferPlayback(deviceID string) error
+	AddToQueue(uris []string) error
+}
+
+// Playlists is the library access the TUI needs, implemented by
+// PlaylistService and the daemon client.
+type Playlists interface {
+	CachedPlaylists() ([]entities.Playlist, bool)
+	CachedPlaylistTracks(id, snapshotID string) (tracks []entities.Track, fresh bool, ok bool)
+	GetPlaylists() ([]entities.Playlist, error)
+	Playlist(id string) (entities.Playlist, error)
+	GetPlaylistTracks(id, snapshotID string) ([]entities.Track, error)
+	SavedTracks(ids []string) (map[string]bool, error)
+	AddTracks(playlistID string, uris []string) error
+	RemoveTracks(playlistID, snapshotID string, uris []string) (string, error)
+	SetSaved(ids []string, saved bool) error
+	TopArtists(timeRange string) ([]entities.Artist, error)
+	TopTracks(timeRange string) ([]entities.Track, error)
+	Artists(ids []string) ([]entities.Artist, error)
+	ExportPlaylist(p entities.Playlist, format playlistfile.Format, w io.Writer) error
+	SearchTracks(query string, limit int) ([]entities.Track, error)
+	CreatePlaylist(name, description string, public bool) (entities.Playlist, error)
+}
+
+var (
+	_ Playback  = (*PlaybackService)(nil)
+	_ Playlists = (*PlaylistService)(nil)
+)
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+
+	"github.com/thomassbooth/spotify-tui/internal/client/spotify"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// The whole library is read a page at a time, each page with its own
+// timeout, so a large one does not run out of time.
+
+// CurrentUser returns the account signed in.
+func (s *PlaylistService) CurrentUser() (entities.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	resp, err := s.client.GetCurrentUser(ctx)
+	if err != nil {
+		return entities.User{}, err
+	}
+	name := resp.DisplayName
+	if name == "" {
+		name = resp.ID
+	}
+	return entities.User{ID: resp.ID, Name: name}, nil
+}
+
+// PlaylistTracks fetches every track of p, however many pages that takes.
+func (s *PlaylistService) PlaylistTracks(p entities.Playlist) ([]entities.Track, error) {
+	return s.playlistTracks(p.ID, p.SnapshotID)
+}
+
+// LikedSongs returns every track in the user's Liked Songs, newest first,
+// with the date each was liked.
+func (s *PlaylistService) LikedSongs() ([]entities.Track, error) {
+	var out []entities.Track
+	for offset := 0; ; {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		page, err := s.client.GetSavedTracks(ctx, offset)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			track := trackEntity(item.Track)
+			track.AddedAt = item.AddedAt
+			out = append(out, track)
+		}
+		if page.Next == "" || len(page.Items) == 0 {
+			return out, nil
+		}
+		offset += len(page.Items)
+	}
+}
+
+// SavedAlbums returns every album in the user's library, newest first.
+func (s *PlaylistService) SavedAlbums() ([]entities.Album, error) {
+	var out []entities.Album
+	for offset := 0; ; {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		page, err := s.client.GetSavedAlbums(ctx, offset)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			album := entities.Album{ID: item.Album.ID, Name: item.Album.Name, URI: item.Album.URI, AddedAt: item.AddedAt}
+			for _, a := range item.Album.Artists {
+				album.Artists = append(album.Artists, entities.Artist{ID: a.ID, Name: a.Name, URI: a.URI})
+			}
+			out = append(out, album)
+		}
+		if page.Next == "" || len(page.Items) == 0 {
+			return out, nil
+		}
+		offset += len(page.Items)
+	}
+}
+
+// SavedShows returns every podcast the user follows, newest first.
+func (s *PlaylistService) SavedShows() ([]entities.Show, error) {
+	var out []entities.Show
+	for offset := 0; ; {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		page, err := s.client.GetSavedShows(ctx, offset)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			out = append(out, entities.Show{
+				ID:        item.Show.ID,
+				Name:      item.Show.Name,
+				Publisher: item.Show.Publisher,
+				URI:       item.Show.URI,
+				AddedAt:   item.AddedAt,
+			})
+		}
+		if page.Next == "" || len(page.Items) == 0 {
+			return out, nil
+		}
+		offset += len(page.Items)
+	}
+}
+
+// FollowedArtists returns every artist the user follows.
+func (s *PlaylistService) FollowedArtists() ([]entities.Artist, error) {
+	var out []entities.Artist
+	for after := ""; ; {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		page, err := s.client.GetFollowedArtists(ctx, after)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range page.Items {
+			out = append(out, entities.Artist{ID: a.ID, Name: a.Name, URI: a.URI})
+		}
+		if page.Next == "" || page.Cursors.After == "" || len(page.Items) == 0 {
+			return out, nil
+		}
+		after = page.Cursors.After
+	}
+}
+
+// SaveTracksAt adds tracks to the user's Liked Songs. Those with an
+// AddedAt keep it as the date they were liked.
+func (s *PlaylistService) SaveTracksAt(tracks []entities.Track) error {
+	var dated []spotify.SavedTrack
+	var undated []string
+	for _, t := range tracks {
+		if t.AddedAt != "" {
+			dated = append(dated, spotify.SavedTrack{ID: t.ID, AddedAt: t.AddedAt})
+		} else {
+			undated = append(undated, t.ID)
+		}
+	}
+	err := inBatches(s.timeout, dated, SavedBatchSize, func(ctx context.Context, batch []spotify.SavedTrack) error {
+		return s.client.SaveTracksAt(ctx, batch)
+	})
+	if err != nil {
+		return err
+	}
+	return inBatches(s.timeout, undated, SavedBatchSize, func(ctx context.Context, batch []string) error {
+		return s.client.SaveTracks(ctx, batch)
+	})
+}
+
+// SaveAlbums adds albums to the user's library.
+func (s *PlaylistService) SaveAlbums(ids []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.SaveAlbums(ctx, ids)
+}
+
+// SaveShows follows podcasts.
+func (s *PlaylistService) SaveShows(ids []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.SaveShows(ctx, ids)
+}
+
+// FollowArtists follows artists.
+func (s *PlaylistService) FollowArtists(ids []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.FollowArtists(ctx, ids)
+}
+
+// FollowPlaylist adds another user's playlist to the library.
+func (s *PlaylistService) FollowPlaylist(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.FollowPlaylist(ctx, id)
+}
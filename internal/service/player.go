@@ -9,12 +9,14 @@ import (
 )
 
 type PlaybackService struct {
-	client *spotify.Client
+	client  *spotify.Client
+	timeout time.Duration
 }
 
-func NewPlaybackService(client *spotify.Client) PlaybackService {
+func NewPlaybackService(client *spotify.Client, timeout time.Duration) PlaybackService {
 	return PlaybackService{
-		client: client,
+		client:  client,
+		timeout: timeout,
 	}
 }
 
@@ -23,12 +25,12 @@ func (s *PlaybackService) GetCurrentPlayback(ctx context.Context) (*entities.Pla
 }
 
 func (s *PlaybackService) GetCurrentPlaybackState() (*entities.PlaybackState, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	return s.client.GetCurrentPlayback(ctx)
 }
 func (s *PlaybackService) Play(trackURI string, playlistURI string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	var body map[string]interface{}
 
@@ -46,7 +48,7 @@ func (s *PlaybackService) Play(trackURI string, playlistURI string) error {
 			"position_ms": 0,
 		}
 	}
-		
+
 	_, err := s.client.Put(ctx, "/me/player/play", nil, body)
 	return err
 }
@@ -67,8 +69,38 @@ func (s *PlaybackService) Previous(ctx context.Context) error {
 }
 
 func (s *PlaybackService) Seek(ctx context.Context, positionMs int) error {
-	_, err := s.client.Put(ctx, "/me/player/seek", nil, map[string]interface{}{
+	_, err := s.client.Put(ctx, "/me/player/seek", map[string]interface{}{
 		"position_ms": positionMs,
+	}, nil)
+	return err
+}
+
+func (s *PlaybackService) SeekTo(positionMs int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.Seek(ctx, positionMs)
+}
+
+// Devices lists the Spotify Connect devices playback can be moved to.
+func (s *PlaybackService) Devices() ([]entities.Device, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.GetDevices(ctx)
+}
+
+// UpNext returns the tracks queued after the current one.
+func (s *PlaybackService) UpNext() ([]entities.Track, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.GetQueue(ctx)
+}
+
+// TransferPlayback moves playback to another device, keeping its state.
+func (s *PlaybackService) TransferPlayback(deviceID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	_, err := s.client.Put(ctx, "/me/player", nil, map[string]interface{}{
+		"device_ids": []string{deviceID},
 	})
 	return err
 }
@@ -81,7 +113,7 @@ func (s *PlaybackService) ToggleRepeat(ctx context.Context, state string) error
 }
 
 func (s *PlaybackService) TogglePlay() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	if true {
 		_, err := s.client.Put(ctx, "/me/player/pause", nil, nil)
@@ -91,35 +123,44 @@ func (s *PlaybackService) TogglePlay() error {
 }
 
 func (s *PlaybackService) PausePlayback() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	_, err := s.client.Put(ctx, "/me/player/pause", nil, nil)
 	return err
 }
 
 func (s *PlaybackService) ResumePlayback() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	_, err := s.client.Put(ctx, "/me/player/play", nil, nil)
 	return err
 }
 
 func (s *PlaybackService) NextTrack() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	_, err := s.client.Post(ctx, "/me/player/next", nil, nil)
 	return err
 }
 
 func (s *PlaybackService) PreviousTrack() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	_, err := s.client.Post(ctx, "/me/player/previous", nil, nil)
 	return err
 }
 
+// AddToQueue appends tracks, given as Spotify URIs, to the playback queue
+// in order. Spotify takes them one at a time, each within its own timeout.
+func (s *PlaybackService) AddToQueue(uris []string) error {
+	return inBatches(s.timeout, uris, 1, func(ctx context.Context, uri []string) error {
+		_, err := s.client.Post(ctx, "/me/player/queue", map[string]interface{}{"uri": uri[0]}, nil)
+		return err
+	})
+}
+
 func (s *PlaybackService) ToggleShufflePlayback(state bool) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	_, err := s.client.Put(ctx, "/me/player/shuffle", map[string]interface{}{
 		"state": state,
@@ -128,7 +169,7 @@ func (s *PlaybackService) ToggleShufflePlayback(state bool) error {
 }
 
 func (s *PlaybackService) ToggleRepeatPlayback(state string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	_, err := s.client.Put(ctx, "/me/player/repeat", nil, map[string]interface{}{
 		"state": state,
@@ -137,7 +178,7 @@ func (s *PlaybackService) ToggleRepeatPlayback(state string) error {
 }
 
 func (s *PlaybackService) SetVolume(percent int) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	_, err := s.client.Put(ctx, "/me/player/volume", nil, map[string]interface{}{
 		"volume_percent": percent,
@@ -178,3 +219,11 @@ func (s *PlaybackService) GetQueue(ctx context.Context) ([]entities.Track, error
 	}
 	return resp.Tracks, nil
 }
+
+// RecentlyPlayed returns Spotify's record of the last 50 plays, newest
+// first.
+func (s *PlaybackService) RecentlyPlayed() ([]entities.PlayHistory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.GetRecentlyPlayed(ctx)
+}
@@ -2,24 +2,52 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/thomassbooth/spotify-tui/internal/client/spotify"
+	"github.com/thomassbooth/spotify-tui/internal/client/spotify/response"
 	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+	"github.com/thomassbooth/spotify-tui/internal/repository"
 )
 
 type PlaylistService struct {
-	client *spotify.Client
+	client  *spotify.Client
+	library *repository.LibraryRepository
+	timeout time.Duration
 }
 
-func NewPlaylistService(client *spotify.Client) PlaylistService {
+// NewPlaylistService creates the service. library may be nil to disable
+// caching.
+func NewPlaylistService(client *spotify.Client, library *repository.LibraryRepository, timeout time.Duration) PlaylistService {
 	return PlaylistService{
-		client: client,
+		client:  client,
+		library: library,
+		timeout: timeout,
 	}
 }
 
+// CachedPlaylists returns the playlists from the last successful fetch
+// without touching the network.
+func (s *PlaylistService) CachedPlaylists() ([]entities.Playlist, bool) {
+	if s.library == nil {
+		return nil, false
+	}
+	return s.library.Playlists()
+}
+
+// CachedPlaylistTracks returns the stored tracks of a playlist. fresh is true
+// when they match snapshotID and need no refetch.
+func (s *PlaylistService) CachedPlaylistTracks(id, snapshotID string) (tracks []entities.Track, fresh bool, ok bool) {
+	if s.library == nil {
+		return nil, false, false
+	}
+	return s.library.PlaylistTracks(id, snapshotID)
+}
+
 func (s *PlaylistService) GetPlaylists() ([]entities.Playlist, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 
 	resp, err := s.client.GetPlaylists(ctx)
@@ -30,64 +58,263 @@ func (s *PlaylistService) GetPlaylists() ([]entities.Playlist, error) {
 	// 2. Map raw → public model
 	out := make([]entities.Playlist, 0, len(resp.Items))
 	for _, item := range resp.Items {
-		p := entities.Playlist{
-			ID:          item.ID,
-			Name:        item.Name,
-			Description: item.Description,
-			OwnerName:   item.Owner.DisplayName,
-			TrackCount:  item.Tracks.Total,
-			URI:         item.URI,
-			Type:        item.Type,
+		out = append(out, playlistEntity(item))
+	}
+
+	if s.library != nil {
+		// The cache only speeds up the next start; failing to write it is not
+		// worth failing the fetch over.
+		_ = s.library.SavePlaylists(out)
+	}
+
+	return out, nil
+}
+
+// Playlist fetches one playlist by ID, whether or not it is in the
+// library.
+func (s *PlaylistService) Playlist(id string) (entities.Playlist, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	resp, err := s.client.GetPlaylist(ctx, id)
+	if err != nil {
+		return entities.Playlist{}, err
+	}
+	return playlistEntity(*resp), nil
+}
+
+func playlistEntity(item response.PlaylistItem) entities.Playlist {
+	p := entities.Playlist{
+		ID:          item.ID,
+		Name:        item.Name,
+		Description: item.Description,
+		OwnerName:   item.Owner.DisplayName,
+		OwnerID:     item.Owner.ID,
+		Public:      item.Public,
+		TrackCount:  item.Tracks.Total,
+		URI:         item.URI,
+		Type:        item.Type,
+		SnapshotID:  item.SnapshotID,
+	}
+
+	// Pick the *first* image (Spotify usually sends a few sizes)
+	if len(item.Images) > 0 {
+		p.ImageURL = item.Images[0].URL
+	}
+	return p
+}
+
+// GetPlaylistTracks fetches the tracks of a playlist and caches them under
+// snapshotID.
+func (s *PlaylistService) GetPlaylistTracks(id, snapshotID string) ([]entities.Track, error) {
+	return s.playlistTracks(id, snapshotID)
+}
+
+// ExportPlaylist fetches every track of p, however many pages that takes,
+// and writes the playlist to w in format.
+func (s *PlaylistService) ExportPlaylist(p entities.Playlist, format playlistfile.Format, w io.Writer) error {
+	tracks, err := s.PlaylistTracks(p)
+	if err != nil {
+		return err
+	}
+	return playlistfile.Write(w, format, playlistfile.New(p, tracks))
+}
+
+// playlistTracks reads every page of a playlist. Each page is a request of
+// its own and gets the whole timeout, so long playlists load as surely as
+// short ones.
+func (s *PlaylistService) playlistTracks(id, snapshotID string) ([]entities.Track, error) {
+	out := []entities.Track{}
+	for offset := 0; ; {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		page, err := s.client.GetPlaylistItems(ctx, id, offset)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			track := trackEntity(item.Track)
+			track.AddedAt = item.AddedAt
+			out = append(out, track)
 		}
+		if page.Next == "" || len(page.Items) == 0 {
+			break
+		}
+		offset += len(page.Items)
+	}
+
+	if s.library != nil {
+		_ = s.library.SavePlaylistTracks(id, snapshotID, out)
+	}
+
+	return out, nil
+}
+
+func trackEntity(t response.Track) entities.Track {
+	// Convert response artists
+	artists := make([]entities.Artist, len(t.Artists))
+	for i, a := range t.Artists {
+		artists[i] = entities.Artist{
+			ID:   a.ID,
+			Name: a.Name,
+			URI:  a.URI,
+		}
+	}
 
-		// Pick the *first* image (Spotify usually sends a few sizes)
-		if len(item.Images) > 0 {
-			p.ImageURL = item.Images[0].URL
+	// Convert response images
+	images := make([]entities.Image, len(t.Album.Images))
+	for i, img := range t.Album.Images {
+		images[i] = entities.Image{
+			URL:    img.URL,
+			Height: img.Height,
+			Width:  img.Width,
 		}
+	}
 
-		out = append(out, p)
+	return entities.Track{
+		ID:         t.ID,
+		Name:       t.Name,
+		DurationMs: t.DurationMs,
+		Popularity: t.Popularity,
+		Artists:    artists,
+		Album: entities.Album{
+			ID:     t.Album.ID,
+			Name:   t.Album.Name,
+			Images: images,
+		},
+		URI:  t.URI,
+		ISRC: t.ExternalIDs.ISRC,
 	}
+}
+
+// SearchTracks returns up to limit tracks from Spotify's catalog matching
+// query.
+func (s *PlaylistService) SearchTracks(query string, limit int) ([]entities.Track, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
 
+	resp, err := s.client.SearchTracks(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]entities.Track, 0, len(resp))
+	for _, t := range resp {
+		out = append(out, trackEntity(t))
+	}
 	return out, nil
+}
+
+// CreatePlaylist creates a playlist owned by the user.
+func (s *PlaylistService) CreatePlaylist(name, description string, public bool) (entities.Playlist, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
 
+	resp, err := s.client.CreatePlaylist(ctx, name, description, public)
+	if err != nil {
+		return entities.Playlist{}, err
+	}
+	return playlistEntity(*resp), nil
 }
 
-func (s *PlaylistService) GetPlaylistTracks(id string) ([]entities.Track, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+// SavedTracks reports which of the given tracks are in the user's Liked
+// Songs.
+func (s *PlaylistService) SavedTracks(ids []string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
-	resp, err := s.client.GetPlaylistItems(ctx, id)
+
+	saved, err := s.client.ContainsSavedTracks(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
 
-	out := make([]entities.Track, 0, len(resp.Items))
-	for _, item := range resp.Items {
-		// Convert response artists
-		artists := make([]entities.Artist, len(item.Track.Artists))
-		for i, a := range item.Track.Artists {
-			artists[i] = entities.Artist{
-				ID:   a.ID,
-				Name: a.Name,
-				URI:  a.URI,
-			}
+	out := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		out[id] = i < len(saved) && saved[i]
+	}
+	return out, nil
+}
+
+// The most items Spotify takes in one request to edit a playlist or Liked
+// Songs.
+const (
+	PlaylistBatchSize = 100
+	SavedBatchSize    = 50
+)
+
+// inBatches passes items to send size at a time, each batch within its own
+// timeout, so a large selection does not run out of time partway through.
+func inBatches[T any](timeout time.Duration, items []T, size int, send func(ctx context.Context, batch []T) error) error {
+	for start := 0; start < len(items); start += size {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := send(ctx, items[start:min(start+size, len(items))])
+		cancel()
+		if err != nil {
+			return err
 		}
+	}
+	return nil
+}
+
+// AddTracks appends tracks, given as Spotify URIs, to a playlist.
+func (s *PlaylistService) AddTracks(playlistID string, uris []string) error {
+	return inBatches(s.timeout, uris, PlaylistBatchSize, func(ctx context.Context, batch []string) error {
+		return s.client.AddPlaylistItems(ctx, playlistID, batch)
+	})
+}
+
+// RemoveTracks removes every copy of the given tracks from a playlist and
+// returns its new snapshot ID.
+func (s *PlaylistService) RemoveTracks(playlistID, snapshotID string, uris []string) (string, error) {
+	err := inBatches(s.timeout, uris, PlaylistBatchSize, func(ctx context.Context, batch []string) (err error) {
+		snapshotID, err = s.client.RemovePlaylistItems(ctx, playlistID, snapshotID, batch)
+		return err
+	})
+	return snapshotID, err
+}
 
-		// Convert response images
-
-		// Build track entity
-		track := entities.Track{
-			ID:         item.Track.ID,
-			Name:       item.Track.Name,
-			DurationMs: item.Track.DurationMs,
-			Artists:    artists,
-			Album: entities.Album{
-				ID:   item.Track.Album.ID,
-				Name: item.Track.Album.Name,
-			},
+// SetSaved adds tracks to, or removes them from, the user's Liked Songs.
+func (s *PlaylistService) SetSaved(ids []string, saved bool) error {
+	return inBatches(s.timeout, ids, SavedBatchSize, func(ctx context.Context, batch []string) error {
+		if saved {
+			return s.client.SaveTracks(ctx, batch)
 		}
+		return s.client.RemoveSavedTracks(ctx, batch)
+	})
+}
+
+// TopArtists returns the user's most listened artists over timeRange, one
+// of Spotify's "short_term", "medium_term" and "long_term".
+func (s *PlaylistService) TopArtists(timeRange string) ([]entities.Artist, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.GetTopArtists(ctx, timeRange)
+}
 
-		out = append(out, track)
+// TopTracks returns the user's most listened tracks over timeRange.
+func (s *PlaylistService) TopTracks(timeRange string) ([]entities.Track, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	return s.client.GetTopTracks(ctx, timeRange)
+}
+
+// Artists returns the full artists for ids, with their genres, taking
+// those it can from the cache. They come in no particular order.
+func (s *PlaylistService) Artists(ids []string) ([]entities.Artist, error) {
+	var cached []entities.Artist
+	if s.library != nil {
+		if cached, ids = s.library.Artists(ids); len(ids) == 0 {
+			return cached, nil
+		}
 	}
 
-	return out, nil
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	artists, err := s.client.GetArtists(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if s.library != nil {
+		_ = s.library.SaveArtists(artists)
+	}
+	return append(cached, artists...), nil
 }
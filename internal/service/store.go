@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// maxProgressDrift is how far the reported position may stray from where
+// steady playback would have put it before it counts as a seek.
+const maxProgressDrift = 2 * time.Second
+
+// PlaybackUpdate is a change seen by a PlaybackStore. State is nil when
+// nothing is playing; Err is set when fetching the state failed.
+type PlaybackUpdate struct {
+	State *entities.PlaybackState
+	Err   error
+}
+
+// PlaybackStore keeps the latest playback state, polling Spotify on an
+// interval, and tells its subscribers whenever it changes. It lets any
+// number of consumers follow playback with a single poller.
+type PlaybackStore struct {
+	service  *PlaybackService
+	interval time.Duration
+
+	mu      sync.Mutex
+	last    PlaybackUpdate
+	fetched time.Time
+	subs    map[chan PlaybackUpdate]struct{}
+}
+
+func NewPlaybackStore(service *PlaybackService, interval time.Duration) *PlaybackStore {
+	return &PlaybackStore{
+		service:  service,
+		interval: interval,
+		subs:     make(map[chan PlaybackUpdate]struct{}),
+	}
+}
+
+// Run polls until ctx is cancelled.
+func (s *PlaybackStore) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh fetches the state now, notifies subscribers if it changed and
+// returns it.
+func (s *PlaybackStore) Refresh(ctx context.Context) (*entities.PlaybackState, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, s.service.timeout)
+	defer cancel()
+	state, err := s.service.GetCurrentPlayback(fetchCtx)
+	if ctx.Err() != nil && err != nil {
+		// Shutting down, not a failure worth reporting.
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update := PlaybackUpdate{State: state, Err: err}
+	if playbackChanged(s.last, update, time.Since(s.fetched)) {
+		for ch := range s.subs {
+			send(ch, update)
+		}
+	}
+	if err == nil {
+		s.fetched = time.Now()
+	}
+	s.last = update
+	return state, err
+}
+
+// Current returns the state from the last fetch without touching the
+// network, its position moved on by the time playback has run since.
+func (s *PlaybackStore) Current() (*entities.PlaybackState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.last.State
+	if state == nil || !state.IsPlaying {
+		return state, s.last.Err
+	}
+	moved := *state
+	moved.ProgressMs = min(state.ProgressMs+int(time.Since(s.fetched).Milliseconds()), state.Track.DurationMs)
+	return &moved, s.last.Err
+}
+
+// Subscribe returns a channel receiving every change from now on, and a
+// function that closes it. A slow subscriber only misses intermediate
+// states; the latest one is always delivered.
+func (s *PlaybackStore) Subscribe() (<-chan PlaybackUpdate, func()) {
+	ch := make(chan PlaybackUpdate, 1)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, ch)
+			s.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// send delivers u without blocking, replacing an update the subscriber has
+// not read yet.
+func send(ch chan PlaybackUpdate, u PlaybackUpdate) {
+	select {
+	case ch <- u:
+		return
+	default:
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- u
+}
+
+// playbackChanged reports whether next differs from prev, fetched elapsed
+// ago, in anything but the position moving on as it plays.
+func playbackChanged(prev, next PlaybackUpdate, elapsed time.Duration) bool {
+	if (prev.Err == nil) != (next.Err == nil) {
+		return true
+	}
+	if next.Err != nil {
+		return prev.Err.Error() != next.Err.Error()
+	}
+	a, b := prev.State, next.State
+	if a == nil || b == nil {
+		return a != b
+	}
+	if a.Track.ID != b.Track.ID ||
+		a.IsPlaying != b.IsPlaying ||
+		a.ShuffleState != b.ShuffleState ||
+		a.RepeatState != b.RepeatState ||
+		a.Device != b.Device ||
+		contextURI(a) != contextURI(b) {
+		return true
+	}
+
+	expected := time.Duration(a.ProgressMs) * time.Millisecond
+	if a.IsPlaying {
+		expected += elapsed
+	}
+	drift := time.Duration(b.ProgressMs)*time.Millisecond - expected
+	return drift > maxProgressDrift || drift < -maxProgressDrift
+}
+
+func contextURI(s *entities.PlaybackState) string {
+	if s.Context == nil {
+		return ""
+	}
+	return s.Context.URI
+}
+
+// Playback returns the service with state reads going through the store,
+// so a fetch made after a command reaches every subscriber, and changes
+// streamed to the caller instead of polled.
+func (s *PlaybackStore) Playback() *StoredPlayback {
+	return &StoredPlayback{PlaybackService: s.service, store: s}
+}
+
+// StoredPlayback is a PlaybackService backed by a PlaybackStore.
+type StoredPlayback struct {
+	*PlaybackService
+	store *PlaybackStore
+}
+
+func (p *StoredPlayback) GetCurrentPlaybackState() (*entities.PlaybackState, error) {
+	return p.store.Refresh(context.Background())
+}
+
+// WatchPlayback subscribes to the store for as long as the process runs.
+func (p *StoredPlayback) WatchPlayback() <-chan PlaybackUpdate {
+	updates, _ := p.store.Subscribe()
+	return updates
+}
@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Characters for the charts, from empty to full.
+var (
+	eighths = []rune(" ▁▂▃▄▅▆▇█")
+	shades  = []rune(" ░▒▓█")
+)
+
+// weekdays label the heatmap rows.
+var weekdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// Columns draws periods as a bar chart height rows tall, one column per
+// period, with the most recent fitting in width. Below it runs a line of
+// labels from label, written under the first column of each.
+func Columns(periods []Period, width, height int, label func(time.Time) string) []string {
+	if width <= 0 || height <= 0 || len(periods) == 0 {
+		return nil
+	}
+	periods = periods[max(len(periods)-width, 0):]
+	peak := 0
+	for _, p := range periods {
+		peak = max(peak, p.ListenedMs)
+	}
+
+	rows := make([][]rune, height)
+	for i := range rows {
+		rows[i] = []rune(strings.Repeat(" ", len(periods)))
+	}
+	for x, p := range periods {
+		filled := 0
+		if peak > 0 {
+			filled = (p.ListenedMs*height*8 + peak - 1) / peak
+		}
+		for y := range height {
+			level := min(max(filled-y*8, 0), 8)
+			rows[height-1-y][x] = eighths[level]
+		}
+	}
+
+	out := make([]string, 0, height+1)
+	for _, row := range rows {
+		out = append(out, string(row))
+	}
+	out = append(out, axis(periods, label))
+	return out
+}
+
+// axis writes labels under the chart, skipping any that would run into the
+// one before.
+func axis(periods []Period, label func(time.Time) string) string {
+	line := []rune(strings.Repeat(" ", len(periods)))
+	next := 0
+	for x, p := range periods {
+		text := []rune(label(p.Start))
+		if x < next || x+len(text) > len(line) {
+			continue
+		}
+		copy(line[x:], text)
+		next = x + len(text) + 1
+	}
+	return strings.TrimRight(string(line), " ")
+}
+
+// Heatmap draws hours as seven rows of 24 two-cell squares, shaded by time
+// listened, under a line of hour labels.
+func Heatmap(hours [7][24]int) []string {
+	peak := 0
+	for _, day := range hours {
+		for _, ms := range day {
+			peak = max(peak, ms)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("    ")
+	for h := 0; h < 24; h += 3 {
+		fmt.Fprintf(&b, "%-6d", h)
+	}
+	out := []string{strings.TrimRight(b.String(), " ")}
+	for d, day := range hours {
+		b.Reset()
+		b.WriteString(weekdays[d] + " ")
+		for _, ms := range day {
+			level := 0
+			if ms > 0 {
+				level = 1 + ms*(len(shades)-2)/peak
+			}
+			b.WriteString(strings.Repeat(string(shades[level]), 2))
+		}
+		out = append(out, b.String())
+	}
+	return out
+}
+
+// Duration formats a time listened as hours and minutes, or minutes alone
+// under an hour.
+func Duration(ms int) string {
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteJSON writes the whole report as one JSON object.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// csvHeader are the columns of WriteCSV. Every aggregate is a section of
+// rows: summary, day, week and hour keyed by when, artist, album and genre
+// keyed by rank, and spotify_artists_<range> and spotify_tracks_<range>
+// keyed by Spotify's rank, with an artist's genres in by.
+var csvHeader = []string{"section", "key", "name", "by", "plays", "listened_ms"}
+
+// WriteCSV writes the report as one table, a section at a time.
+func (r *Report) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	rows := [][]string{csvHeader,
+		{"summary", "total", "", "", strconv.Itoa(r.Plays), strconv.Itoa(r.ListenedMs)},
+		{"summary", "skipped", "", "", strconv.Itoa(r.Skips), ""},
+	}
+	for _, d := range r.Days {
+		rows = append(rows, []string{"day", d.Start.Format("2006-01-02"), "", "", strconv.Itoa(d.Plays), strconv.Itoa(d.ListenedMs)})
+	}
+	for _, wk := range r.Weeks {
+		rows = append(rows, []string{"week", wk.Start.Format("2006-01-02"), "", "", strconv.Itoa(wk.Plays), strconv.Itoa(wk.ListenedMs)})
+	}
+	for d, day := range r.Hours {
+		for h, ms := range day {
+			rows = append(rows, []string{"hour", fmt.Sprintf("%s %02d:00", strings.ToLower(weekdays[d]), h), "", "", "", strconv.Itoa(ms)})
+		}
+	}
+	for _, section := range []struct {
+		name   string
+		counts []Count
+	}{{"artist", r.TopArtists}, {"album", r.TopAlbums}, {"genre", r.TopGenres}} {
+		for i, c := range section.counts {
+			rows = append(rows, []string{section.name, strconv.Itoa(i + 1), c.Name, c.By, strconv.Itoa(c.Plays), strconv.Itoa(c.ListenedMs)})
+		}
+	}
+	for _, rng := range Ranges {
+		top := r.Spotify[rng]
+		if top == nil {
+			continue
+		}
+		for _, a := range top.Artists {
+			rows = append(rows, []string{"spotify_artists_" + string(rng), strconv.Itoa(a.Rank), a.Name, strings.Join(a.Genres, "; "), "", ""})
+		}
+		for _, t := range top.Tracks {
+			rows = append(rows, []string{"spotify_tracks_" + string(rng), strconv.Itoa(t.Rank), t.Name, t.By, "", ""})
+		}
+	}
+	if err := out.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write stats: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+// Package stats sums up listening: from the local history, how long was
+// spent listening per day and week, at what hours, to what and how often
+// tracks were skipped; from Spotify, the top artists and tracks over each
+// of its time ranges.
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/history"
+)
+
+// maxTop is how many artists, albums and genres a report ranks.
+const maxTop = 50
+
+// Range is one of Spotify's windows for top artists and tracks.
+type Range string
+
+const (
+	ShortTerm  Range = "short_term"
+	MediumTerm Range = "medium_term"
+	LongTerm   Range = "long_term"
+)
+
+// Ranges lists every range, shortest first.
+var Ranges = []Range{ShortTerm, MediumTerm, LongTerm}
+
+// Label is how long a range roughly covers.
+func (r Range) Label() string {
+	switch r {
+	case ShortTerm:
+		return "4 weeks"
+	case MediumTerm:
+		return "6 months"
+	case LongTerm:
+		return "1 year"
+	}
+	return string(r)
+}
+
+// Days is about how many days a range covers, to sum up the same stretch
+// of the local history.
+func (r Range) Days() int {
+	switch r {
+	case ShortTerm:
+		return 28
+	case MediumTerm:
+		return 182
+	}
+	return 365
+}
+
+// ParseRange accepts a range by its name or by "short", "medium" and "long".
+func ParseRange(s string) (Range, bool) {
+	for _, r := range Ranges {
+		if s == string(r) || s+"_term" == string(r) {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// Period is the listening in one day or week, starting at Start.
+type Period struct {
+	Start      time.Time `json:"start"`
+	Plays      int       `json:"plays"`
+	ListenedMs int       `json:"listened_ms"`
+}
+
+// Count is the listening to one artist, album or genre.
+type Count struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name"`
+	By         string `json:"by,omitempty"` // an album's artist
+	Plays      int    `json:"plays"`
+	ListenedMs int    `json:"listened_ms"`
+}
+
+// TopItem is a ranked artist or track from Spotify.
+type TopItem struct {
+	Rank   int      `json:"rank"`
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	By     string   `json:"by,omitempty"` // a track's artists
+	Genres []string `json:"genres,omitempty"`
+}
+
+// Top is Spotify's ranking over one range.
+type Top struct {
+	Artists []TopItem `json:"artists"`
+	Tracks  []TopItem `json:"tracks"`
+}
+
+// Report is everything the stats view and export show.
+type Report struct {
+	From       time.Time `json:"from"` // zero for the whole history
+	To         time.Time `json:"to"`
+	Plays      int       `json:"plays"`
+	ListenedMs int       `json:"listened_ms"`
+	// Skips and SkipRate only count recorded plays: imported ones do not
+	// say whether they were skipped.
+	Skips    int      `json:"skips"`
+	SkipRate float64  `json:"skip_rate"`
+	Days     []Period `json:"days"`
+	Weeks    []Period `json:"weeks"`
+	// Hours is the time listened by weekday, Monday first, and hour of the
+	// day the play started, local time.
+	Hours      [7][24]int     `json:"hours_ms"`
+	TopArtists []Count        `json:"top_artists"`
+	TopAlbums  []Count        `json:"top_albums"`
+	TopGenres  []Count        `json:"top_genres"`
+	Spotify    map[Range]*Top `json:"spotify,omitempty"`
+}
+
+// Compute sums up the history entries of the last days days up to now, or
+// of all of them when days is 0.
+func Compute(entries []history.Entry, days int, now time.Time) *Report {
+	now = now.Local()
+	r := &Report{To: now}
+	if days > 0 {
+		r.From = startOfDay(now).AddDate(0, 0, -(days - 1))
+	}
+
+	var (
+		recorded int
+		artists  = map[string]*Count{}
+		albums   = map[string]*Count{}
+		dayIndex = map[time.Time]int{}
+		first    time.Time
+	)
+	for _, e := range entries {
+		started := e.StartedAt.Local()
+		if started.Before(r.From) || started.After(now) {
+			continue
+		}
+		if first.IsZero() || started.Before(first) {
+			first = started
+		}
+		r.Plays++
+		r.ListenedMs += e.ListenedMs
+		if !e.Imported {
+			recorded++
+			if e.Skipped {
+				r.Skips++
+			}
+		}
+		r.Hours[weekday(started)][started.Hour()] += e.ListenedMs
+
+		day := startOfDay(started)
+		i, ok := dayIndex[day]
+		if !ok {
+			i = len(r.Days)
+			dayIndex[day] = i
+			r.Days = append(r.Days, Period{Start: day})
+		}
+		r.Days[i].Plays++
+		r.Days[i].ListenedMs += e.ListenedMs
+
+		for _, a := range e.Track.Artists {
+			add(artists, a.ID, a.Name, "", e.ListenedMs)
+		}
+		if album := e.Track.Album; album.ID != "" {
+			by := ""
+			if len(e.Track.Artists) > 0 {
+				by = e.Track.Artists[0].Name
+			}
+			add(albums, album.ID, album.Name, by, e.ListenedMs)
+		}
+	}
+	if recorded > 0 {
+		r.SkipRate = float64(r.Skips) / float64(recorded)
+	}
+
+	start := r.From
+	if start.IsZero() && !first.IsZero() {
+		start = startOfDay(first)
+	}
+	r.Days = fillDays(r.Days, start, now)
+	r.Weeks = weeks(r.Days)
+	r.TopArtists = ranked(artists)
+	r.TopAlbums = ranked(albums)
+	return r
+}
+
+func add(counts map[string]*Count, id, name, by string, ms int) {
+	key := id
+	if key == "" {
+		key = name
+	}
+	c, ok := counts[key]
+	if !ok {
+		c = &Count{ID: id, Name: name, By: by}
+		counts[key] = c
+	}
+	c.Plays++
+	c.ListenedMs += ms
+}
+
+// ranked orders counts by time listened, then plays, and keeps the top.
+func ranked(counts map[string]*Count) []Count {
+	out := make([]Count, 0, len(counts))
+	for _, c := range counts {
+		out = append(out, *c)
+	}
+	slices.SortFunc(out, func(a, b Count) int {
+		return cmp.Or(
+			cmp.Compare(b.ListenedMs, a.ListenedMs),
+			cmp.Compare(b.Plays, a.Plays),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return out[:min(len(out), maxTop)]
+}
+
+// fillDays returns one period for every day from start to now, including
+// days with no listening.
+func fillDays(days []Period, start, now time.Time) []Period {
+	if start.IsZero() {
+		return nil
+	}
+	byDay := make(map[time.Time]Period, len(days))
+	for _, d := range days {
+		byDay[d.Start] = d
+	}
+	var out []Period
+	for day := start; !day.After(now); day = day.AddDate(0, 0, 1) {
+		p, ok := byDay[day]
+		if !ok {
+			p = Period{Start: day}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// weeks adds consecutive days up into weeks starting on Monday.
+func weeks(days []Period) []Period {
+	var out []Period
+	for _, d := range days {
+		start := d.Start.AddDate(0, 0, -weekday(d.Start))
+		if len(out) == 0 || !out[len(out)-1].Start.Equal(start) {
+			out = append(out, Period{Start: start})
+		}
+		w := &out[len(out)-1]
+		w.Plays += d.Plays
+		w.ListenedMs += d.ListenedMs
+	}
+	return out
+}
+
+// AddGenres ranks genres by the listening to the top artists that have
+// them, given the full artists for TopArtists. An artist's time counts
+// towards each of its genres.
+func (r *Report) AddGenres(artists []entities.Artist) {
+	genres := make(map[string][]string, len(artists))
+	for _, a := range artists {
+		genres[a.ID] = a.Genres
+	}
+	counts := map[string]*Count{}
+	for _, a := range r.TopArtists {
+		for _, g := range genres[a.ID] {
+			c, ok := counts[g]
+			if !ok {
+				c = &Count{Name: g}
+				counts[g] = c
+			}
+			c.Plays += a.Plays
+			c.ListenedMs += a.ListenedMs
+		}
+	}
+	r.TopGenres = ranked(counts)
+}
+
+// ArtistIDs returns the IDs of TopArtists, to look their genres up.
+func (r *Report) ArtistIDs() []string {
+	var ids []string
+	for _, a := range r.TopArtists {
+		if a.ID != "" {
+			ids = append(ids, a.ID)
+		}
+	}
+	return ids
+}
+
+// SetTop records Spotify's ranking over rng.
+func (r *Report) SetTop(rng Range, artists []entities.Artist, tracks []entities.Track) {
+	top := &Top{}
+	for i, a := range artists {
+		top.Artists = append(top.Artists, TopItem{Rank: i + 1, ID: a.ID, Name: a.Name, Genres: a.Genres})
+	}
+	for i, t := range tracks {
+		top.Tracks = append(top.Tracks, TopItem{Rank: i + 1, ID: t.ID, Name: t.Name, By: artistNames(t.Artists)})
+	}
+	if r.Spotify == nil {
+		r.Spotify = map[Range]*Top{}
+	}
+	r.Spotify[rng] = top
+}
+
+func artistNames(artists []entities.Artist) string {
+	var out string
+	for i, a := range artists {
+		if i > 0 {
+			out += ", "
+		}
+		out += a.Name
+	}
+	return out
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekday numbers the days from Monday, 0, to Sunday, 6.
+func weekday(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
+
+// Source is where Spotify's side of a report comes from, such as
+// service.Playlists.
+type Source interface {
+	TopArtists(timeRange string) ([]entities.Artist, error)
+	TopTracks(timeRange string) ([]entities.Track, error)
+	Artists(ids []string) ([]entities.Artist, error)
+}
+
+// FetchSpotify looks up the genres of the top artists and Spotify's top
+// artists and tracks over ranges. It keeps whatever it got when a request
+// fails, and returns the first error.
+func (r *Report) FetchSpotify(src Source, ranges ...Range) error {
+	var errs []error
+	if ids := r.ArtistIDs(); len(ids) > 0 {
+		artists, err := src.Artists(ids)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		r.AddGenres(artists)
+	}
+	for _, rng := range ranges {
+		artists, err := src.TopArtists(string(rng))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tracks, err := src.TopTracks(string(rng))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.SetTop(rng, artists, tracks)
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
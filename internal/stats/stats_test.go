@@ -0,0 +1,136 @@
+package stats
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/history"
+)
+
+// inLondon runs the test with the local time zone set to one that moves its
+// clocks forward on 29 March 2026.
+func inLondon(t *testing.T) *time.Location {
+	t.Helper()
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := time.Local
+	time.Local = london
+	t.Cleanup(func() { time.Local = local })
+	return london
+}
+
+func play(artist string, at time.Time, minutes int) history.Entry {
+	return history.Entry{
+		Track:      entities.Track{Name: "song", Artists: []entities.Artist{{ID: artist, Name: artist}}},
+		StartedAt:  at.UTC(),
+		ListenedMs: minutes * 60_000,
+	}
+}
+
+func TestComputeAcrossDST(t *testing.T) {
+	london := inLondon(t)
+	at := func(day, hour, min int) time.Time { return time.Date(2026, 3, day, hour, min, 0, 0, london) }
+
+	entries := []history.Entry{
+		play("a", at(24, 12, 0), 3), // before the range
+		play("a", at(28, 23, 30), 3),
+		play("a", at(29, 0, 30), 3),
+		play("a", at(29, 23, 30), 3), // after the clocks went forward
+		play("a", at(30, 0, 30), 3),
+		play("a", at(31, 13, 0), 3), // after now
+	}
+	r := Compute(entries, 7, at(31, 12, 0))
+
+	if r.Plays != 4 || r.ListenedMs != 12*60_000 {
+		t.Errorf("Plays = %d, ListenedMs = %d, want 4 plays of 12 minutes", r.Plays, r.ListenedMs)
+	}
+	if !r.From.Equal(at(25, 0, 0)) {
+		t.Errorf("From = %v, want midnight on the 25th", r.From)
+	}
+
+	wantDays := []int{0, 0, 0, 1, 2, 1, 0} // 25th to 31st
+	if len(r.Days) != len(wantDays) {
+		t.Fatalf("got %d days, want %d", len(r.Days), len(wantDays))
+	}
+	for i, d := range r.Days {
+		if want := at(25+i, 0, 0); !d.Start.Equal(want) {
+			t.Errorf("day %d starts %v, want %v", i, d.Start, want)
+		}
+		if d.Plays != wantDays[i] {
+			t.Errorf("%s: %d plays, want %d", d.Start.Format("Jan 2"), d.Plays, wantDays[i])
+		}
+	}
+
+	if len(r.Weeks) != 2 {
+		t.Fatalf("got %d weeks, want 2", len(r.Weeks))
+	}
+	if !r.Weeks[0].Start.Equal(at(23, 0, 0)) || r.Weeks[0].Plays != 3 {
+		t.Errorf("first week = %+v, want 3 plays from Monday the 23rd", r.Weeks[0])
+	}
+	if !r.Weeks[1].Start.Equal(at(30, 0, 0)) || r.Weeks[1].Plays != 1 {
+		t.Errorf("second week = %+v, want 1 play from Monday the 30th", r.Weeks[1])
+	}
+
+	// Saturday 23:00, Sunday 00:00 and 23:00, Monday 00:00.
+	for _, h := range []struct{ day, hour int }{{5, 23}, {6, 0}, {6, 23}, {0, 0}} {
+		if got := r.Hours[h.day][h.hour]; got != 3*60_000 {
+			t.Errorf("Hours[%d][%d] = %d, want 3 minutes", h.day, h.hour, got)
+		}
+	}
+}
+
+func TestComputeSkipRate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := []history.Entry{
+		play("a", now.Add(-4*time.Hour), 3),
+		play("a", now.Add(-3*time.Hour), 3),
+		play("a", now.Add(-2*time.Hour), 3),
+		play("a", now.Add(-time.Hour), 1),
+		play("a", now.Add(-90*time.Minute), 3),
+		play("a", now.Add(-30*time.Minute), 3),
+	}
+	entries[3].Skipped = true
+	// Imported plays say nothing about skipping, whatever they carry.
+	entries[4].Imported, entries[4].Skipped = true, true
+	entries[5].Imported = true
+
+	r := Compute(entries, 0, now)
+	if r.Plays != 6 {
+		t.Errorf("Plays = %d, want 6: imported plays still count", r.Plays)
+	}
+	if r.Skips != 1 || r.SkipRate != 0.25 {
+		t.Errorf("Skips = %d, SkipRate = %v, want 1 of the 4 recorded", r.Skips, r.SkipRate)
+	}
+
+	if r := Compute(entries[4:], 0, now); r.SkipRate != 0 || r.Skips != 0 {
+		t.Errorf("only imported plays: Skips = %d, SkipRate = %v, want none", r.Skips, r.SkipRate)
+	}
+}
+
+func TestComputeRanking(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := []history.Entry{
+		play("most", now.Add(-time.Hour), 10),
+		// Tied on time: more plays first, then by name.
+		play("tied-fewer", now.Add(-time.Hour), 6),
+		play("tied-more", now.Add(-time.Hour), 3),
+		play("tied-more", now.Add(-time.Hour), 3),
+		play("b-tied", now.Add(-time.Hour), 2),
+		play("a-tied", now.Add(-time.Hour), 2),
+	}
+	r := Compute(entries, 0, now)
+
+	want := []string{"most", "tied-more", "tied-fewer", "a-tied", "b-tied"}
+	if len(r.TopArtists) != len(want) {
+		t.Fatalf("ranked %d artists, want %d", len(r.TopArtists), len(want))
+	}
+	for i, c := range r.TopArtists {
+		if c.Name != want[i] {
+			t.Errorf("rank %d = %s, want %s", i+1, c.Name, want[i])
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package status renders the playback state as a single line for status
+// bars such as tmux, polybar and i3blocks.
+package status
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// scrollGap separates the end of scrolling text from its start coming round
+// again.
+const scrollGap = "   "
+
+// Fields are the values a format template can use.
+type Fields struct {
+	Track   string
+	Artist  string // the first artist
+	Artists string // every artist, comma separated
+	Album   string
+	Device  string
+	Context string // URI of the playlist or album being played
+
+	State   string // "playing" or "paused"
+	Playing bool
+	Shuffle bool
+	Repeat  string // "off", "context" or "track"
+	Volume  int
+
+	Progress   string // elapsed time, e.g. "1:23"
+	Duration   string
+	Remaining  string
+	ProgressMs int
+	DurationMs int
+	Percent    int // how far through the track, 0 to 100
+
+	StatusIcon  string // ▶ or ⏸
+	ShuffleIcon string // 🔀 when shuffle is on, otherwise empty
+	RepeatIcon  string // 🔁 or 🔂 when repeat is on, otherwise empty
+}
+
+// Formatter turns playback states into lines. It is not safe for
+// concurrent use.
+type Formatter struct {
+	tmpl  *template.Template
+	idle  string
+	width int
+	now   time.Time // of the line being rendered, for scroll
+}
+
+// New parses format, a text/template over Fields. idle is printed when
+// nothing is playing. Lines wider than width cells are cut short; 0 means
+// no limit.
+//
+// Besides the usual template functions, "truncate N" shortens a value to N
+// cells and "scroll N" scrolls it through N cells one step a second, e.g.
+// {{.Track | scroll 20}}.
+func New(format, idle string, width int) (*Formatter, error) {
+	f := &Formatter{idle: idle, width: width}
+	tmpl, err := template.New("status").Funcs(template.FuncMap{
+		"truncate": truncate,
+		"scroll": func(n int, s string) string {
+			return scroll(n, s, int(f.now.Unix()))
+		},
+	}).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status format: %w", err)
+	}
+	f.tmpl = tmpl
+	return f, nil
+}
+
+// Line renders state, fetched at the given time, as of now. The position
+// is moved on by the time since it was fetched.
+func (f *Formatter) Line(state *entities.PlaybackState, fetched, now time.Time) (string, error) {
+	if state == nil || state.Track.ID == "" {
+		return f.idle, nil
+	}
+	f.now = now
+	var buf bytes.Buffer
+	if err := f.tmpl.Execute(&buf, newFields(state, now.Sub(fetched))); err != nil {
+		return "", fmt.Errorf("invalid status format: %w", err)
+	}
+	line := strings.ReplaceAll(buf.String(), "\n", " ")
+	if f.width > 0 {
+		line = truncate(f.width, line)
+	}
+	return line, nil
+}
+
+// newFields describes state, moving the position on by elapsed if it is
+// playing.
+func newFields(state *entities.PlaybackState, elapsed time.Duration) Fields {
+	t := state.Track
+	artists := make([]string, len(t.Artists))
+	for i, a := range t.Artists {
+		artists[i] = a.Name
+	}
+
+	progress := state.ProgressMs
+	if state.IsPlaying {
+		progress += int(elapsed.Milliseconds())
+	}
+	progress = min(max(progress, 0), t.DurationMs)
+
+	f := Fields{
+		Track:      t.Name,
+		Artists:    strings.Join(artists, ", "),
+		Album:      t.Album.Name,
+		Device:     state.Device.Name,
+		State:      "paused",
+		Playing:    state.IsPlaying,
+		Shuffle:    state.ShuffleState,
+		Repeat:     state.RepeatState,
+		Volume:     state.Device.VolumePercent,
+		Progress:   formatDuration(progress),
+		Duration:   formatDuration(t.DurationMs),
+		Remaining:  formatDuration(t.DurationMs - progress),
+		ProgressMs: progress,
+		DurationMs: t.DurationMs,
+		StatusIcon: "⏸",
+	}
+	if len(artists) > 0 {
+		f.Artist = artists[0]
+	}
+	if state.Context != nil {
+		f.Context = state.Context.URI
+	}
+	if t.DurationMs > 0 {
+		f.Percent = progress * 100 / t.DurationMs
+	}
+	if state.IsPlaying {
+		f.State, f.StatusIcon = "playing", "▶"
+	}
+	if state.ShuffleState {
+		f.ShuffleIcon = "🔀"
+	}
+	switch state.RepeatState {
+	case "context":
+		f.RepeatIcon = "🔁"
+	case "track":
+		f.RepeatIcon = "🔂"
+	}
+	return f
+}
+
+func truncate(n int, s string) string {
+	return ansi.Truncate(s, max(n, 0), "…")
+}
+
+// scroll shows n cells of s starting step cells in, wrapping round. Text
+// that already fits is returned as it is.
+func scroll(n int, s string, step int) string {
+	if n <= 0 || ansi.StringWidth(s) <= n {
+		return s
+	}
+	loop := s + scrollGap
+	offset := step % ansi.StringWidth(loop)
+	return ansi.Cut(loop+loop, offset, offset+n)
+}
+
+func formatDuration(ms int) string {
+	seconds := ms / 1000
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
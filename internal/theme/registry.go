@@ -0,0 +1,149 @@
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+)
+
+// Registry holds the built-in themes plus any loaded from the user's theme
+// directory, in the order they are cycled through.
+type Registry struct {
+	themes map[string]Theme
+	names  []string
+}
+
+func NewRegistry() *Registry {
+	r := &Registry{themes: map[string]Theme{}}
+	for _, t := range Builtin() {
+		r.add(t)
+	}
+	return r
+}
+
+// Load builds a registry from the config: built-ins, then every *.toml file
+// in cfg.Dir, then the colour overrides applied to the selected theme. It
+// returns the registry and the theme to start with.
+func Load(cfg config.ThemeConfig) (*Registry, Theme, error) {
+	r := NewRegistry()
+	if err := r.LoadDir(cfg.Dir); err != nil {
+		return nil, Theme{}, err
+	}
+
+	t, ok := r.Get(cfg.Name)
+	if !ok {
+		return nil, Theme{}, fmt.Errorf("unknown theme %q (available: %s)", cfg.Name, strings.Join(r.Names(), ", "))
+	}
+
+	t = t.merge(Theme{
+		Accent:  lipgloss.Color(cfg.Accent),
+		Text:    lipgloss.Color(cfg.Text),
+		Subtext: lipgloss.Color(cfg.Subtext),
+		Muted:   lipgloss.Color(cfg.Muted),
+		Dim:     lipgloss.Color(cfg.Dim),
+		Border:  lipgloss.Color(cfg.Border),
+	})
+	r.add(t)
+
+	return r, t, nil
+}
+
+// themeFile is the on-disk format. Colours left out are taken from base,
+// which defaults to the default theme.
+type themeFile struct {
+	Theme
+	Base string `toml:"base"`
+}
+
+// LoadDir adds every *.toml theme in dir. A missing directory is not an
+// error.
+func (r *Registry) LoadDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return fmt.Errorf("failed to list themes: %w", err)
+	}
+
+	var errs []error
+	for _, path := range paths {
+		t, err := r.loadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		r.add(t)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) loadFile(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, fmt.Errorf("failed to read theme: %w", err)
+	}
+
+	var f themeFile
+	md, err := toml.Decode(string(data), &f)
+	if err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme: %w", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return Theme{}, fmt.Errorf("unknown theme key %s", undecoded[0])
+	}
+
+	if f.Name == "" {
+		f.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if f.Base == "" {
+		f.Base = DefaultName
+	}
+	base, ok := r.Get(f.Base)
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown base theme %q", f.Base)
+	}
+
+	t := base.merge(f.Theme)
+	t.Name = f.Name
+	for _, c := range []lipgloss.Color{t.Accent, t.Text, t.Subtext, t.Muted, t.Dim, t.Border} {
+		if !config.ValidColour(string(c)) {
+			return Theme{}, fmt.Errorf("%q is not a hex colour or ANSI number", c)
+		}
+	}
+	return t, nil
+}
+
+func (r *Registry) add(t Theme) {
+	if _, ok := r.themes[t.Name]; !ok {
+		r.names = append(r.names, t.Name)
+	}
+	r.themes[t.Name] = t
+}
+
+func (r *Registry) Get(name string) (Theme, bool) {
+	t, ok := r.themes[name]
+	return t, ok
+}
+
+// Names returns every theme name in cycling order.
+func (r *Registry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Next returns the theme after name, wrapping around.
+func (r *Registry) Next(name string) Theme {
+	for i, n := range r.names {
+		if n == name {
+			return r.themes[r.names[(i+1)%len(r.names)]]
+		}
+	}
+	return r.themes[r.names[0]]
+}
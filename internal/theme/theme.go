@@ -0,0 +1,77 @@
+package theme
+
+import "github.com/charmbracelet/lipgloss"
+
+// Theme is the colour palette every view component renders with.
+type Theme struct {
+	Name    string         `toml:"name"`
+	Accent  lipgloss.Color `toml:"accent"`
+	Text    lipgloss.Color `toml:"text"`
+	Subtext lipgloss.Color `toml:"subtext"`
+	Muted   lipgloss.Color `toml:"muted"`
+	Dim     lipgloss.Color `toml:"dim"`
+	Border  lipgloss.Color `toml:"border"`
+}
+
+const DefaultName = "spotify"
+
+// Builtin returns the themes shipped with the app, default first.
+func Builtin() []Theme {
+	return []Theme{
+		{
+			Name:    DefaultName,
+			Accent:  "#1db954",
+			Text:    "#FAFAFA",
+			Subtext: "#b3b3b3",
+			Muted:   "#888888",
+			Dim:     "#535353",
+			Border:  "#626262",
+		},
+		{
+			Name:    "light",
+			Accent:  "#168d40",
+			Text:    "#191414",
+			Subtext: "#3e3e3e",
+			Muted:   "#6a6a6a",
+			Dim:     "#9e9e9e",
+			Border:  "#b3b3b3",
+		},
+		{
+			Name:    "high-contrast",
+			Accent:  "#00ff5f",
+			Text:    "#ffffff",
+			Subtext: "#ffffff",
+			Muted:   "#e4e4e4",
+			Dim:     "#c6c6c6",
+			Border:  "#ffffff",
+		},
+		{
+			// Only uses the 16 basic ANSI colours so it renders the same on
+			// terminals without 256-colour or truecolor support.
+			Name:    "monochrome",
+			Accent:  "15",
+			Text:    "7",
+			Subtext: "7",
+			Muted:   "8",
+			Dim:     "8",
+			Border:  "8",
+		},
+	}
+}
+
+// merge returns t with every non-empty colour of o applied on top.
+func (t Theme) merge(o Theme) Theme {
+	pick := func(base, over lipgloss.Color) lipgloss.Color {
+		if over != "" {
+			return over
+		}
+		return base
+	}
+	t.Accent = pick(t.Accent, o.Accent)
+	t.Text = pick(t.Text, o.Text)
+	t.Subtext = pick(t.Subtext, o.Subtext)
+	t.Muted = pick(t.Muted, o.Muted)
+	t.Dim = pick(t.Dim, o.Dim)
+	t.Border = pick(t.Border, o.Border)
+	return t
+}
@@ -0,0 +1,104 @@
+package view
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thomassbooth/spotify-tui/internal/art"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+)
+
+const maxRenderedArt = 64
+
+type artLoadedMsg struct {
+	url string
+	err error
+}
+
+// coverArt loads cover images in the background and renders them for any
+// component that asks. Rendered blocks are memoised per size since View runs
+// on every frame.
+type coverArt struct {
+	enabled  bool
+	protocol art.Protocol
+	store    *art.Store
+	timeout  time.Duration
+
+	mu        sync.Mutex
+	requested map[string]bool
+	rendered  map[string]string
+}
+
+func newCoverArt(cfg config.ArtConfig, timeout time.Duration) *coverArt {
+	protocol := art.Protocol(cfg.Protocol)
+	if protocol == art.ProtocolAuto {
+		protocol = art.Detect()
+	}
+	return &coverArt{
+		enabled:   cfg.Enabled,
+		protocol:  protocol,
+		store:     art.NewStore(cfg.CacheDir, timeout),
+		timeout:   timeout,
+		requested: make(map[string]bool),
+		rendered:  make(map[string]string),
+	}
+}
+
+// Load starts fetching url unless it has already been requested.
+func (c *coverArt) Load(url string) tea.Cmd {
+	if c == nil || !c.enabled || url == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.requested[url] {
+		return nil
+	}
+	c.requested[url] = true
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+		_, err := c.store.Get(ctx, url)
+		return artLoadedMsg{url: url, err: err}
+	}
+}
+
+// View renders url into a cols×rows block, or a blank block of the same size
+// while the image is still loading.
+func (c *coverArt) View(url string, cols, rows int) string {
+	if url == "" {
+		return art.Placeholder(cols, rows)
+	}
+
+	key := fmt.Sprintf("%s@%dx%d", url, cols, rows)
+	c.mu.Lock()
+	if s, ok := c.rendered[key]; ok {
+		c.mu.Unlock()
+		return s
+	}
+	c.mu.Unlock()
+
+	img, ok := c.store.Cached(url)
+	if !ok {
+		return art.Placeholder(cols, rows)
+	}
+
+	d := art.Render(img, cols, rows, c.protocol)
+	c.mu.Lock()
+	if len(c.rendered) >= maxRenderedArt {
+		clear(c.rendered)
+	}
+	c.rendered[key] = d.Again
+	c.mu.Unlock()
+	return d.First
+}
+
+// Enabled reports whether cover art should take up space in the layout.
+func (c *coverArt) Enabled() bool {
+	return c != nil && c.enabled
+}
@@ -0,0 +1,276 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const statusDuration = 4 * time.Second
+
+// selectionMark renders the column that flags selected rows.
+func selectionMark(sel *selection, m list.Model, index int, i playlistItem) string {
+	if sel != nil && sel.Has(m, index, i) {
+		return lipgloss.NewStyle().Foreground(theme.Accent).Render("●")
+	}
+	return " "
+}
+
+// targets returns the tracks a bulk action applies to: the selection, or
+// the track under the cursor when nothing is selected. Albums and playlists
+// in search results are skipped, and so are local files, which have no ID
+// Spotify knows.
+func (s *PlaylistTracks) targets() []playlistItem {
+	items := s.sel.Items(s.tracks)
+	if len(items) == 0 {
+		if it, ok := s.tracks.SelectedItem().(playlistItem); ok {
+			items = []playlistItem{it}
+		}
+	}
+
+	var out []playlistItem
+	for _, it := range items {
+		if it.id != "" && (it.resultType == "" || it.resultType == "track") {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+func trackURIs(items []playlistItem) []string {
+	uris := make([]string, len(items))
+	for i, it := range items {
+		uris[i] = "spotify:track:" + it.id
+	}
+	return uris
+}
+
+// markCursor selects the row under the cursor.
+func (s *PlaylistTracks) markCursor() {
+	if it, ok := s.tracks.SelectedItem().(playlistItem); ok {
+		s.sel.marked[it.position] = true
+	}
+}
+
+// flash shows status in the title bar for a few seconds.
+func (s *PlaylistTracks) flash(status string) tea.Cmd {
+	s.status = status
+	s.statusID++
+	id := s.statusID
+	return tea.Tick(statusDuration, func(time.Time) tea.Msg {
+		return statusExpiredMsg{id: id}
+	})
+}
+
+func (s *PlaylistTracks) queueTargets() tea.Cmd {
+	uris := trackURIs(s.targets())
+	if len(uris) == 0 {
+		return nil
+	}
+	s.sel.Clear()
+	return func() tea.Msg {
+		if err := s.playbackService.AddToQueue(uris); err != nil {
+			return bulkActionMsg{err: err}
+		}
+		return bulkActionMsg{status: fmt.Sprintf("Queued %d %s", len(uris), plural(len(uris), "track"))}
+	}
+}
+
+// openPicker asks which playlist to add the targets to, listing cached
+// playlists straight away and fetching them when there are none.
+func (s *PlaylistTracks) openPicker() tea.Cmd {
+	uris := trackURIs(s.targets())
+	if len(uris) == 0 {
+		return nil
+	}
+
+	playlists, _ := s.playlistService.CachedPlaylists()
+	s.picker = newPlaylistPicker(playlists, uris, s.listKeys)
+	if len(playlists) > 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		playlists, err := s.playlistService.GetPlaylists()
+		return pickerPlaylistsMsg{playlists: playlists, err: err}
+	}
+}
+
+func (s *PlaylistTracks) updatePicker(msg tea.Msg) tea.Cmd {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok || s.picker.list.SettingFilter() {
+		return s.picker.Update(msg)
+	}
+
+	switch {
+	case key.Matches(km, s.keys.Play):
+		item, ok := s.picker.Selected()
+		uris := s.picker.uris
+		s.picker = nil
+		if !ok {
+			return nil
+		}
+		s.sel.Clear()
+		return func() tea.Msg {
+			if err := s.playlistService.AddTracks(item.id, uris); err != nil {
+				return bulkActionMsg{err: err}
+			}
+			return bulkActionMsg{status: fmt.Sprintf("Added %d %s to %s", len(uris), plural(len(uris), "track"), item.name)}
+		}
+
+	case key.Matches(km, s.listKeys.ClearFilter) && s.picker.list.FilterState() == list.Unfiltered:
+		s.picker = nil
+		return nil
+	}
+	return s.picker.Update(msg)
+}
+
+// confirmRemove asks before removing the targets from the open playlist.
+func (s *PlaylistTracks) confirmRemove() tea.Cmd {
+	if s.showingQueue || s.showingHistory || s.search.active || s.lastPlaylist.ID == "" {
+		return s.flash("Only tracks of a playlist can be removed")
+	}
+	targets := s.targets()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	// Spotify removes a track by URI, which takes every copy of it in the
+	// playlist, marked or not, so the prompt counts the rows that go.
+	marked := map[string]bool{}
+	var uris []string
+	for _, uri := range trackURIs(targets) {
+		if !marked[uri] {
+			marked[uri] = true
+			uris = append(uris, uri)
+		}
+	}
+	rows := 0
+	for _, it := range s.tracks.Items() {
+		if pi, ok := it.(playlistItem); ok && marked["spotify:track:"+pi.id] {
+			rows++
+		}
+	}
+	prompt := fmt.Sprintf("Remove %d %s from", rows, plural(rows, "track"))
+	if rows > len(targets) {
+		prompt = fmt.Sprintf("Remove every copy of %d %s, %d rows, from", len(uris), plural(len(uris), "track"), rows)
+	}
+
+	pl := s.lastPlaylist
+	s.confirm = &confirmation{
+		prompt: fmt.Sprintf("%s %s? (%s to confirm)", prompt, pl.Name, s.keys.Confirm.Help().Key),
+		run: func() tea.Cmd {
+			s.sel.Clear()
+			return func() tea.Msg {
+				snapshotID, err := s.playlistService.RemoveTracks(pl.ID, pl.SnapshotID, uris)
+				if err != nil {
+					return bulkActionMsg{err: err}
+				}
+				return tracksRemovedMsg{playlistID: pl.ID, snapshotID: snapshotID, count: rows}
+			}
+		},
+	}
+	return nil
+}
+
+// toggleLike likes the targets, or unlikes them when all are liked already.
+func (s *PlaylistTracks) toggleLike() tea.Cmd {
+	targets := s.targets()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	like := false
+	ids := make([]string, len(targets))
+	for i, it := range targets {
+		ids[i] = it.id
+		if !s.liked[it.id] {
+			like = true
+		}
+	}
+	s.sel.Clear()
+
+	return func() tea.Msg {
+		if err := s.playlistService.SetSaved(ids, like); err != nil {
+			return bulkActionMsg{err: err}
+		}
+		liked := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			liked[id] = like
+		}
+		verb := "Liked"
+		if !like {
+			verb = "Unliked"
+		}
+		return bulkActionMsg{status: fmt.Sprintf("%s %d %s", verb, len(ids), plural(len(ids), "track")), liked: liked}
+	}
+}
+
+// copyTargets puts the targets' URIs, or their open.spotify.com links, on
+// the clipboard, one per line.
+func (s *PlaylistTracks) copyTargets(links bool) tea.Cmd {
+	targets := s.targets()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	lines := trackURIs(targets)
+	what := "URI"
+	if links {
+		what = "link"
+		for i, it := range targets {
+			lines[i] = "https://open.spotify.com/track/" + it.id
+		}
+	}
+	copyToClipboard(strings.Join(lines, "\n"))
+	s.sel.Clear()
+	return s.flash(fmt.Sprintf("Copied %d %s", len(lines), plural(len(lines), what)))
+}
+
+// applyLiked records Liked Songs state and refreshes the rows showing it.
+func (s *PlaylistTracks) applyLiked(liked map[string]bool) tea.Cmd {
+	if len(liked) == 0 {
+		return nil
+	}
+	for id, l := range liked {
+		s.liked[id] = l
+	}
+	if s.search.active {
+		return nil
+	}
+
+	items := s.tracks.Items()
+	for i, it := range items {
+		if pi, ok := it.(playlistItem); ok {
+			pi.liked = s.liked[pi.id]
+			items[i] = pi
+		}
+	}
+	return s.tracks.SetItems(s.sortItems(items))
+}
+
+// title is the breadcrumb followed by the selection count and any pending
+// question or status.
+func (s *PlaylistTracks) title() string {
+	parts := []string{s.history.Breadcrumb()}
+	if parts[0] == "" {
+		parts[0] = "Playlist Tracks"
+	}
+	if n := s.sel.Len(s.tracks); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d selected", n))
+	}
+	if s.sel.visual {
+		parts = append(parts, "VISUAL")
+	}
+	switch {
+	case s.confirm != nil:
+		parts = append(parts, s.confirm.prompt)
+	case s.status != "":
+		parts = append(parts, s.status)
+	}
+	return strings.Join(parts, " · ")
+}
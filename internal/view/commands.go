@@ -0,0 +1,134 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Command is an action that can be run from the command palette, e.g.
+// "volume 40". Components register theirs with a CommandRegistry.
+type Command struct {
+	Name        string
+	Group       string // shown next to the description, e.g. "playback"
+	Description string
+	// Args describes the argument, e.g. "<0-100>". Commands with Args are
+	// not run until an argument has been typed.
+	Args string
+	// Complete optionally lists argument values to suggest.
+	Complete func() []string
+	Run      func(args string) (tea.Cmd, error)
+}
+
+// CommandRegistry holds every command the palette offers.
+type CommandRegistry struct {
+	commands map[string]Command
+}
+
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{commands: map[string]Command{}}
+}
+
+// commandRegistrar is a component that contributes commands.
+type commandRegistrar interface {
+	RegisterCommands(r *CommandRegistry)
+}
+
+// Register adds commands, replacing any with the same name.
+func (r *CommandRegistry) Register(cmds ...Command) {
+	for _, c := range cmds {
+		r.commands[c.Name] = c
+	}
+}
+
+func (r *CommandRegistry) Lookup(name string) (Command, bool) {
+	c, ok := r.commands[name]
+	return c, ok
+}
+
+// Commands returns every command sorted by group, then name.
+func (r *CommandRegistry) Commands() []Command {
+	out := make([]Command, 0, len(r.commands))
+	for _, c := range r.commands {
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Group != out[j].Group {
+			return out[i].Group < out[j].Group
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Run parses and runs a command line such as "seek 1:30".
+func (r *CommandRegistry) Run(line string) (tea.Cmd, error) {
+	name, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	c, ok := r.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q", name)
+	}
+	args = strings.TrimSpace(args)
+	if c.Args != "" && args == "" {
+		return nil, fmt.Errorf("%s needs an argument: %s", c.Name, c.Args)
+	}
+	return c.Run(args)
+}
+
+// noArgs adapts a command without arguments.
+func noArgs(run func() tea.Cmd) func(string) (tea.Cmd, error) {
+	return func(string) (tea.Cmd, error) {
+		return run(), nil
+	}
+}
+
+// oneOf checks an argument against its allowed values, case-insensitively.
+func oneOf(arg string, values ...string) (string, error) {
+	for _, v := range values {
+		if strings.EqualFold(arg, v) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not one of %s", arg, strings.Join(values, ", "))
+}
+
+// parsePosition reads a track position as "m:ss" or plain seconds.
+func parsePosition(s string) (time.Duration, error) {
+	if m, sec, ok := strings.Cut(s, ":"); ok {
+		minutes, err1 := strconv.Atoi(m)
+		seconds, err2 := strconv.Atoi(sec)
+		if err1 != nil || err2 != nil || minutes < 0 || seconds < 0 || seconds >= 60 {
+			return 0, fmt.Errorf("%q is not a position like 1:30", s)
+		}
+		return time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second, nil
+	}
+	seconds, err := strconv.Atoi(s)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("%q is not a position like 1:30", s)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+var errNoMatch = errors.New("nothing matches")
+
+// bestMatch picks the candidate equal to arg, ignoring case, or else the
+// first one containing it.
+func bestMatch(arg string, candidates []string) (string, error) {
+	for _, c := range candidates {
+		if strings.EqualFold(c, arg) {
+			return c, nil
+		}
+	}
+	lower := strings.ToLower(arg)
+	for _, c := range candidates {
+		if strings.Contains(strings.ToLower(c), lower) {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", errNoMatch, arg)
+}
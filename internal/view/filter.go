@@ -0,0 +1,79 @@
+package view
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inputCapturer is a component that can temporarily take over the
+// keyboard, e.g. while a list filter is being typed. The page then sends it
+// every key, global bindings included.
+type inputCapturer interface {
+	Component
+	capturingInput() bool
+}
+
+// filterSeparator joins the searchable fields of an item into its filter
+// value; the fuzzy matcher scores the start of each field as a word start.
+const filterSeparator = " "
+
+func filterValue(fields ...string) string {
+	return strings.Join(fields, filterSeparator)
+}
+
+// fieldMatches splits the filter matches of the item at index into rune
+// positions within each of its fields. The list reports matches as byte
+// offsets into the joined filter value.
+func fieldMatches(m list.Model, index int, fields ...string) [][]int {
+	out := make([][]int, len(fields))
+	matches := m.MatchesForItem(index)
+	if len(matches) == 0 {
+		return out
+	}
+
+	start := 0
+	for i, field := range fields {
+		end := start + len(field)
+		var inField []int
+		for _, b := range matches {
+			if b >= start && b < end {
+				inField = append(inField, b-start)
+			}
+		}
+		out[i] = runeIndexes(field, inField)
+		start = end + len(filterSeparator)
+	}
+	return out
+}
+
+// runeIndexes converts fuzzy-match byte offsets in s to rune positions.
+func runeIndexes(s string, offsets []int) []int {
+	if len(offsets) == 0 {
+		return nil
+	}
+	out := make([]int, len(offsets))
+	for i, b := range offsets {
+		out[i] = utf8.RuneCountInString(s[:b])
+	}
+	return out
+}
+
+// highlight renders s in style with the matched runes underlined in the
+// accent colour.
+func highlight(s string, runes []int, style lipgloss.Style) string {
+	if len(runes) == 0 {
+		return style.Render(s)
+	}
+	matched := style.Foreground(theme.Accent).Bold(true).Underline(true)
+	return lipgloss.StyleRunes(s, runes, matched, style)
+}
+
+// styleFilterInput applies the current theme to a list's filter prompt.
+func styleFilterInput(m *list.Model) {
+	m.FilterInput.PromptStyle = lipgloss.NewStyle().Foreground(theme.Accent)
+	m.FilterInput.TextStyle = lipgloss.NewStyle().Foreground(theme.Text)
+	m.FilterInput.Cursor.Style = lipgloss.NewStyle().Foreground(theme.Accent)
+}
@@ -0,0 +1,546 @@
+package view
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/importer"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+	themes "github.com/thomassbooth/spotify-tui/internal/theme"
+)
+
+type importStage int
+
+const (
+	importMatching importStage = iota
+	importReviewing
+	importAdding
+	importDone
+)
+
+// Progress and results of an import, tagged with the run they belong to
+// so a cancelled one cannot leak into the next.
+type importProgressMsg struct {
+	run         int
+	done, total int
+}
+
+type importMatchedMsg struct {
+	run     int
+	matches []importer.Match
+	err     error
+}
+
+type importAddedMsg struct {
+	run      int
+	playlist entities.Playlist
+	added    int
+	err      error
+}
+
+// ImportView is the full-screen import of a playlist file: it matches
+// every entry against the catalog, lists the uncertain ones for review and
+// adds the tracks picked to a new playlist, or to one chosen with the
+// add-to-playlist key.
+type ImportView struct {
+	playlists service.Playlists
+	list      listKeyMap
+	tracks    tracksKeyMap
+	open      bool
+
+	run     int
+	cancel  context.CancelFunc
+	events  chan tea.Msg
+	file    playlistfile.Playlist
+	target  *entities.Playlist // nil to create a playlist named after the file
+	picker  *playlistPicker
+	stage   importStage
+	done    int // entries matched or tracks added so far
+	total   int
+	matches []importer.Match
+	showAll bool  // list every entry, not only those to review
+	rows    []int // indexes into matches listed
+	cursor  int
+	offset  int
+	result  string
+	err     error
+}
+
+func NewImportView(playlists service.Playlists, list listKeyMap, tracks tracksKeyMap) *ImportView {
+	return &ImportView{playlists: playlists, list: list, tracks: tracks}
+}
+
+// Open reads the file at path and starts matching it.
+func (v *ImportView) Open(path string) tea.Cmd {
+	v.Close()
+	file, err := playlistfile.ReadFile(path)
+	*v = ImportView{playlists: v.playlists, list: v.list, tracks: v.tracks, run: v.run + 1, open: true}
+	if err != nil {
+		v.stage, v.err = importDone, err
+		return nil
+	}
+	if len(file.Entries) == 0 {
+		v.stage, v.err = importDone, fmt.Errorf("%s has no tracks", path)
+		return nil
+	}
+	v.file, v.total = file, len(file.Entries)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	v.cancel, v.events = cancel, make(chan tea.Msg, 1)
+	run, events := v.run, v.events
+	go func() {
+		defer close(events)
+		matches, err := importer.Find(ctx, v.playlists, file.Entries, func(done, total int) {
+			progress(events, importProgressMsg{run: run, done: done, total: total})
+		})
+		send(ctx, events, importMatchedMsg{run: run, matches: matches, err: err})
+	}()
+	return v.wait()
+}
+
+// Close stops an import in progress and hides the view.
+func (v *ImportView) Close() {
+	if v.cancel != nil {
+		v.cancel()
+	}
+	v.open = false
+}
+
+func (v *ImportView) Opened() bool {
+	return v.open
+}
+
+// progress passes a progress update on unless the last one has not been
+// shown yet.
+func progress(events chan tea.Msg, msg tea.Msg) {
+	select {
+	case events <- msg:
+	default:
+	}
+}
+
+// send passes the outcome on once there is room, unless the import was
+// cancelled.
+func send(ctx context.Context, events chan tea.Msg, msg tea.Msg) {
+	select {
+	case events <- msg:
+	case <-ctx.Done():
+	}
+}
+
+// wait delivers the next message from the running import, or nothing
+// once it has finished.
+func (v *ImportView) wait() tea.Cmd {
+	events := v.events
+	return func() tea.Msg {
+		return <-events
+	}
+}
+
+func (v *ImportView) Update(msg tea.Msg) tea.Cmd {
+	switch m := msg.(type) {
+	case importProgressMsg:
+		if m.run != v.run {
+			return nil
+		}
+		v.done, v.total = m.done, m.total
+		return v.wait()
+
+	case importMatchedMsg:
+		if m.run != v.run {
+			return nil
+		}
+		v.matches, v.err = m.matches, m.err
+		if m.err != nil {
+			v.stage = importDone
+			return nil
+		}
+		v.stage = importReviewing
+		v.refreshRows()
+		return nil
+
+	case importAddedMsg:
+		if m.run != v.run {
+			return nil
+		}
+		v.stage, v.err = importDone, m.err
+		v.result = fmt.Sprintf("Added %d %s to %s", m.added, plural(m.added, "track"), m.playlist.Name)
+		if left := len(v.matches) - len(importer.URIs(v.matches)); left > 0 {
+			v.result += fmt.Sprintf(", left out %d", left)
+		}
+		return nil
+
+	case pickerPlaylistsMsg:
+		if v.picker != nil {
+			return v.picker.SetPlaylists(m.playlists)
+		}
+	}
+	return nil
+}
+
+// refreshRows lists the matches to review, or all of them, keeping the
+// cursor on the same match where it can.
+func (v *ImportView) refreshRows() {
+	current := -1
+	if v.cursor < len(v.rows) {
+		current = v.rows[v.cursor]
+	}
+	v.rows = v.rows[:0]
+	for i, m := range v.matches {
+		if v.showAll || m.NeedsReview() {
+			v.rows = append(v.rows, i)
+		}
+	}
+	v.cursor = 0
+	for i, row := range v.rows {
+		if row == current {
+			v.cursor = i
+		}
+	}
+}
+
+// HandleKey moves between entries, cycles the candidate picked for one
+// and starts adding the tracks once reviewed.
+func (v *ImportView) HandleKey(m tea.KeyMsg) tea.Cmd {
+	if v.picker != nil {
+		return v.updatePicker(m)
+	}
+	if v.stage != importReviewing {
+		return nil
+	}
+	switch {
+	case key.Matches(m, v.list.Up):
+		v.cursor = max(v.cursor-1, 0)
+	case key.Matches(m, v.list.Down):
+		v.cursor = min(v.cursor+1, max(len(v.rows)-1, 0))
+	case key.Matches(m, v.list.PageUp):
+		v.cursor = max(v.cursor-10, 0)
+	case key.Matches(m, v.list.PageDown):
+		v.cursor = min(v.cursor+10, max(len(v.rows)-1, 0))
+	case key.Matches(m, v.list.Top):
+		v.cursor = 0
+	case key.Matches(m, v.list.Bottom):
+		v.cursor = max(len(v.rows)-1, 0)
+	case key.Matches(m, v.tracks.FilterPrev):
+		v.choose(-1)
+	case key.Matches(m, v.tracks.FilterNext):
+		v.choose(1)
+	case key.Matches(m, v.tracks.ToggleSelect):
+		if match := v.current(); match != nil {
+			if match.Choice >= 0 {
+				match.Choice = -1
+			} else if len(match.Candidates) > 0 {
+				match.Choice = 0
+			}
+		}
+	case key.Matches(m, v.tracks.ToggleView):
+		v.showAll = !v.showAll
+		v.refreshRows()
+	case key.Matches(m, v.tracks.AddToPlaylist):
+		return v.openPicker()
+	case key.Matches(m, v.tracks.Play):
+		return v.add()
+	}
+	return nil
+}
+
+func (v *ImportView) current() *importer.Match {
+	if v.cursor >= len(v.rows) {
+		return nil
+	}
+	return &v.matches[v.rows[v.cursor]]
+}
+
+// choose steps through the candidates of the current entry and, past the
+// last one, leaving it out.
+func (v *ImportView) choose(step int) {
+	match := v.current()
+	if match == nil || len(match.Candidates) == 0 {
+		return
+	}
+	options := len(match.Candidates) + 1 // the last is "leave out"
+	i := match.Choice
+	if i < 0 {
+		i = len(match.Candidates)
+	}
+	i = (i + step + options) % options
+	if i == len(match.Candidates) {
+		i = -1
+	}
+	match.Choice = i
+}
+
+func (v *ImportView) openPicker() tea.Cmd {
+	playlists, _ := v.playlists.CachedPlaylists()
+	v.picker = newPlaylistPicker(playlists, nil, v.list)
+	v.picker.list.Title = "Import into…"
+	return func() tea.Msg {
+		playlists, err := v.playlists.GetPlaylists()
+		if err != nil {
+			return nil
+		}
+		return pickerPlaylistsMsg{playlists: playlists}
+	}
+}
+
+// updatePicker picks the playlist to add to. Leaving the picker goes back
+// to creating a playlist.
+func (v *ImportView) updatePicker(m tea.KeyMsg) tea.Cmd {
+	if v.picker.list.SettingFilter() {
+		return v.picker.Update(m)
+	}
+	switch {
+	case key.Matches(m, v.tracks.Play):
+		if item, ok := v.picker.Selected(); ok {
+			v.target = &entities.Playlist{ID: item.id, Name: item.name, URI: item.uri}
+		}
+		v.picker = nil
+		return nil
+	case key.Matches(m, v.list.ClearFilter) && v.picker.list.FilterState() == list.Unfiltered:
+		v.target, v.picker = nil, nil
+		return nil
+	}
+	return v.picker.Update(m)
+}
+
+// add creates or fills the playlist in the background.
+func (v *ImportView) add() tea.Cmd {
+	uris := importer.URIs(v.matches)
+	if len(uris) == 0 {
+		v.err = fmt.Errorf("no tracks picked")
+		return nil
+	}
+	v.stage, v.done, v.total, v.err = importAdding, 0, len(uris), nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	v.cancel, v.events = cancel, make(chan tea.Msg, 1)
+	run, events, target, matches, name := v.run, v.events, v.target, v.matches, v.file.Name
+	go func() {
+		defer close(events)
+		p, added, err := importer.Into(v.playlists, target, name, matches, func(done, total int) {
+			progress(events, importProgressMsg{run: run, done: done, total: total})
+		})
+		send(ctx, events, importAddedMsg{run: run, playlist: p, added: added, err: err})
+	}()
+	return v.wait()
+}
+
+// Busy reports whether tracks are being added, which closing the view
+// would not stop.
+func (v *ImportView) Busy() bool {
+	return v.stage == importAdding
+}
+
+// FullView renders the import: progress while matching and adding, then
+// the entries to review with the candidate picked for each. closeHint is a
+// key that closes it besides esc, if any.
+func (v *ImportView) FullView(width, height int, closeHint string) string {
+	const margin = 2
+	inner := max(width-2*margin, 0)
+	accent := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	muted := lipgloss.NewStyle().Foreground(theme.Muted)
+	text := lipgloss.NewStyle().Foreground(theme.Text)
+
+	destination := fmt.Sprintf("a new playlist, %s", v.file.Name)
+	if v.target != nil {
+		destination = v.target.Name
+	}
+	header := []string{
+		accent.Render("Import " + ansi.Truncate(v.file.Name, max(inner-7, 0), "…")),
+		text.Render(ansi.Truncate("Into "+destination, inner, "…")),
+	}
+
+	var body []string
+	hint := "esc to close"
+	if closeHint != "" {
+		hint = closeHint + " or " + hint
+	}
+	switch v.stage {
+	case importMatching:
+		body = []string{muted.Render(fmt.Sprintf("Matching %d of %d…", v.done, v.total))}
+		hint = "esc to cancel"
+	case importAdding:
+		body = []string{muted.Render(fmt.Sprintf("Adding %d of %d…", v.done, v.total))}
+		hint = ""
+	case importDone:
+		if v.result != "" {
+			body = append(body, text.Render(v.result))
+		}
+		if v.err != nil {
+			body = append(body, lipgloss.NewStyle().Foreground(theme.Subtext).Render(ansi.Truncate("Failed: "+v.err.Error(), inner, "…")))
+		}
+	case importReviewing:
+		header = append(header, text.Render(v.summary()))
+		if v.err != nil {
+			header = append(header, lipgloss.NewStyle().Foreground(theme.Subtext).Render(v.err.Error()))
+		}
+		body = v.rowsView(inner, max(height-len(header)-3, 0))
+		hint = fmt.Sprintf("%s/%s pick · %s leave out · %s show %s · %s choose playlist · %s import · esc cancel",
+			v.tracks.FilterPrev.Help().Key, v.tracks.FilterNext.Help().Key, v.tracks.ToggleSelect.Help().Key,
+			v.tracks.ToggleView.Help().Key, map[bool]string{true: "to review", false: "all"}[v.showAll],
+			v.tracks.AddToPlaylist.Help().Key, v.tracks.Play.Help().Key)
+	}
+
+	bodyHeight := max(height-len(header)-3, 0)
+	if v.picker != nil {
+		body = strings.Split(v.picker.View(min(inner, 60), bodyHeight), "\n")
+		hint = "enter to pick · esc for a new playlist"
+	}
+
+	view := lipgloss.JoinVertical(lipgloss.Left,
+		strings.Join(header, "\n"),
+		"",
+		lipgloss.NewStyle().Height(bodyHeight).MaxHeight(bodyHeight).Render(strings.Join(body, "\n")),
+		"",
+		muted.Render(ansi.Truncate(hint, inner, "…")),
+	)
+	return lipgloss.NewStyle().Padding(0, margin).MaxHeight(height).Render(view)
+}
+
+func (v *ImportView) summary() string {
+	var sure, review, out int
+	for _, m := range v.matches {
+		switch {
+		case m.Choice < 0:
+			out++
+		case m.NeedsReview():
+			review++
+		default:
+			sure++
+		}
+	}
+	return fmt.Sprintf("%d matched · %d to check · %d left out", sure, review, out)
+}
+
+// rowsView lists the entries with the candidate picked next to each,
+// scrolled to keep the cursor in view.
+func (v *ImportView) rowsView(width, height int) []string {
+	if len(v.rows) == 0 {
+		return []string{lipgloss.NewStyle().Foreground(theme.Muted).Render("Every track matched. Press enter to import.")}
+	}
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+height {
+		v.offset = v.cursor - height + 1
+	}
+	v.offset = clamp(v.offset, 0, max(len(v.rows)-height, 0))
+
+	half := max((width-12)/2, 0)
+	var out []string
+	for i := v.offset; i < min(v.offset+height, len(v.rows)); i++ {
+		m := v.matches[v.rows[i]]
+		mark, score, picked := "✗", "    ", "left out"
+		if c, ok := m.Chosen(); ok {
+			mark = "✓"
+			if m.NeedsReview() {
+				mark = "?"
+			}
+			score = fmt.Sprintf("%3.0f%%", c.Score*100)
+			picked = describeTrack(c.Track)
+			if len(m.Candidates) > 1 {
+				picked += fmt.Sprintf(" (%d/%d)", m.Choice+1, len(m.Candidates))
+			}
+		} else if len(m.Candidates) == 0 {
+			picked = "not found"
+		}
+		entry := importer.Describe(m.Entry)
+		if m.Entry.DurationMs > 0 {
+			entry += " · " + formatDuration(m.Entry.DurationMs)
+		}
+		entry = ansi.Truncate(entry, half, "…")
+		line := fmt.Sprintf("%s %s %s%s  → %s", mark, score, entry, strings.Repeat(" ", max(half-ansi.StringWidth(entry), 0)), ansi.Truncate(picked, half, "…"))
+
+		style := lipgloss.NewStyle().Foreground(theme.Text)
+		switch {
+		case i == v.cursor:
+			style = lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+		case m.Choice < 0:
+			style = lipgloss.NewStyle().Foreground(theme.Muted)
+		}
+		out = append(out, style.Render(line))
+	}
+	return out
+}
+
+func describeTrack(t entities.Track) string {
+	names := make([]string, len(t.Artists))
+	for i, a := range t.Artists {
+		names[i] = a.Name
+	}
+	s := strings.Join(names, ", ") + " - " + t.Name
+	if t.DurationMs > 0 {
+		s += " · " + formatDuration(t.DurationMs)
+	}
+	return s
+}
+
+// importProgram runs an ImportView on its own, for the import command.
+type importProgram struct {
+	view          *ImportView
+	start         tea.Cmd
+	width, height int
+	quit          key.Binding
+}
+
+func (p *importProgram) Init() tea.Cmd {
+	return p.start
+}
+
+func (p *importProgram) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch m := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width, p.height = m.Width, m.Height
+		return p, nil
+	case tea.KeyMsg:
+		closing := m.String() == "esc" && p.view.picker == nil
+		if (closing || key.Matches(m, p.quit)) && !p.view.Busy() {
+			p.view.Close()
+			return p, tea.Quit
+		}
+		if p.view.stage == importDone && key.Matches(m, p.view.tracks.Play) {
+			p.view.Close()
+			return p, tea.Quit
+		}
+		return p, p.view.HandleKey(m)
+	}
+	return p, p.view.Update(msg)
+}
+
+func (p *importProgram) View() string {
+	if p.width == 0 {
+		return ""
+	}
+	return p.view.FullView(p.width, p.height, p.quit.Help().Key)
+}
+
+// RunImport imports the playlist file at path on a screen of its own,
+// the same one the command palette opens, and returns what it did. The
+// tracks go to target or, when it is nil, to a new playlist called name,
+// or after the file when name is empty.
+func RunImport(cfg *config.Config, themeRegistry *themes.Registry, playlists service.Playlists, path string, target *entities.Playlist, name string) (string, error) {
+	if t, ok := themeRegistry.Get(cfg.Theme.Name); ok {
+		theme = t
+	}
+	keys := NewKeyMap(cfg.Keymap)
+	view := NewImportView(playlists, keys.List, keys.Tracks)
+	program := &importProgram{view: view, start: view.Open(path), quit: keys.Global.Quit}
+	view.target = target
+	if name != "" {
+		view.file.Name = name
+	}
+
+	if _, err := tea.NewProgram(program, tea.WithAltScreen()).Run(); err != nil {
+		return "", err
+	}
+	return view.result, view.err
+}
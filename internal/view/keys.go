@@ -0,0 +1,277 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+)
+
+// KeyMap holds every binding in the app, one group per component. It is
+// built once from the config so remapped keys apply everywhere, including
+// the help overlay.
+type KeyMap struct {
+	Global     globalKeyMap
+	List       listKeyMap
+	Sidebar    sidebarKeyMap
+	Tracks     tracksKeyMap
+	Playbar    playbarKeyMap
+	Navigation navigationKeyMap
+	Palette    paletteKeyMap
+}
+
+type globalKeyMap struct {
+	Quit           key.Binding
+	Search         key.Binding
+	CycleFocus     key.Binding
+	CycleFocusBack key.Binding
+	ToggleQueue    key.Binding
+	ToggleHistory  key.Binding
+	ToggleShuffle  key.Binding
+	CycleTheme     key.Binding
+	Help           key.Binding
+	Back           key.Binding
+	Forward        key.Binding
+	CommandPalette key.Binding
+	NarrowSidebar  key.Binding
+	WidenSidebar   key.Binding
+	ToggleSidebar  key.Binding
+	ToggleLogo     key.Binding
+	NowPlaying     key.Binding
+	Lyrics         key.Binding
+	Stats          key.Binding
+}
+
+type listKeyMap struct {
+	Up          key.Binding
+	Down        key.Binding
+	PageUp      key.Binding
+	PageDown    key.Binding
+	Top         key.Binding
+	Bottom      key.Binding
+	Filter      key.Binding
+	ClearFilter key.Binding
+}
+
+type sidebarKeyMap struct {
+	Select key.Binding
+	Retry  key.Binding
+	Export key.Binding
+}
+
+type tracksKeyMap struct {
+	Play            key.Binding
+	Retry           key.Binding
+	FilterPrev      key.Binding
+	FilterNext      key.Binding
+	FilterAll       key.Binding
+	FilterPlaylists key.Binding
+	FilterAlbums    key.Binding
+	FilterSongs     key.Binding
+	ToggleView      key.Binding
+	Sort            key.Binding
+	SortReverse     key.Binding
+	ToggleSelect    key.Binding
+	Visual          key.Binding
+	ExtendUp        key.Binding
+	ExtendDown      key.Binding
+	SelectAll       key.Binding
+	Queue           key.Binding
+	AddToPlaylist   key.Binding
+	Remove          key.Binding
+	Like            key.Binding
+	CopyURIs        key.Binding
+	CopyLinks       key.Binding
+	Confirm         key.Binding // answers a yes/no question; any other key says no
+}
+
+type playbarKeyMap struct {
+	PlayPause  key.Binding
+	Next       key.Binding
+	Previous   key.Binding
+	VolumeUp   key.Binding
+	VolumeDown key.Binding
+}
+
+type navigationKeyMap struct {
+	Submit key.Binding
+	Cancel key.Binding
+}
+
+type paletteKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	Complete key.Binding
+	Run      key.Binding
+	Close    key.Binding
+}
+
+func NewKeyMap(cfg config.KeymapConfig) KeyMap {
+	b := func(section, action, help string) key.Binding {
+		keys := cfg.Keys(section, action)
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(helpKeys(keys), help))
+	}
+
+	return KeyMap{
+		Global: globalKeyMap{
+			Quit:           b(config.KeymapGlobal, "quit", "quit"),
+			Search:         b(config.KeymapGlobal, "search", "search"),
+			CycleFocus:     b(config.KeymapGlobal, "cycle_focus", "next panel"),
+			CycleFocusBack: b(config.KeymapGlobal, "cycle_focus_back", "previous panel"),
+			ToggleQueue:    b(config.KeymapGlobal, "toggle_queue", "toggle queue"),
+			ToggleHistory:  b(config.KeymapGlobal, "toggle_history", "toggle listening history"),
+			ToggleShuffle:  b(config.KeymapGlobal, "toggle_shuffle", "toggle shuffle"),
+			CycleTheme:     b(config.KeymapGlobal, "cycle_theme", "next theme"),
+			Help:           b(config.KeymapGlobal, "help", "toggle help"),
+			Back:           b(config.KeymapGlobal, "back", "go back"),
+			Forward:        b(config.KeymapGlobal, "forward", "go forward"),
+			CommandPalette: b(config.KeymapGlobal, "command_palette", "command palette"),
+			NarrowSidebar:  b(config.KeymapGlobal, "sidebar_narrower", "narrower sidebar"),
+			WidenSidebar:   b(config.KeymapGlobal, "sidebar_wider", "wider sidebar"),
+			ToggleSidebar:  b(config.KeymapGlobal, "toggle_sidebar", "show/hide sidebar"),
+			ToggleLogo:     b(config.KeymapGlobal, "toggle_logo", "show/hide logo"),
+			NowPlaying:     b(config.KeymapGlobal, "now_playing", "full-screen now playing"),
+			Lyrics:         b(config.KeymapGlobal, "lyrics", "show/hide lyrics"),
+			Stats:          b(config.KeymapGlobal, "stats", "listening stats"),
+		},
+		List: listKeyMap{
+			Up:          b(config.KeymapList, "up", "up"),
+			Down:        b(config.KeymapList, "down", "down"),
+			PageUp:      b(config.KeymapList, "page_up", "page up"),
+			PageDown:    b(config.KeymapList, "page_down", "page down"),
+			Top:         b(config.KeymapList, "top", "go to top"),
+			Bottom:      b(config.KeymapList, "bottom", "go to bottom"),
+			Filter:      b(config.KeymapList, "filter", "filter list"),
+			ClearFilter: b(config.KeymapList, "clear_filter", "clear filter"),
+		},
+		Sidebar: sidebarKeyMap{
+			Select: b(config.KeymapSidebar, "select", "open playlist"),
+			Retry:  b(config.KeymapSidebar, "retry", "retry loading"),
+			Export: b(config.KeymapSidebar, "export", "export playlist"),
+		},
+		Tracks: tracksKeyMap{
+			Play:            b(config.KeymapTracks, "play", "play / apply filter"),
+			Retry:           b(config.KeymapTracks, "retry", "retry loading"),
+			FilterPrev:      b(config.KeymapTracks, "filter_prev", "previous filter"),
+			FilterNext:      b(config.KeymapTracks, "filter_next", "next filter"),
+			FilterAll:       b(config.KeymapTracks, "filter_all", "show all results"),
+			FilterPlaylists: b(config.KeymapTracks, "filter_playlists", "show playlists"),
+			FilterAlbums:    b(config.KeymapTracks, "filter_albums", "show albums"),
+			FilterSongs:     b(config.KeymapTracks, "filter_songs", "show songs"),
+			ToggleView:      b(config.KeymapTracks, "toggle_view", "table / compact view"),
+			Sort:            b(config.KeymapTracks, "sort", "sort by next column"),
+			SortReverse:     b(config.KeymapTracks, "sort_reverse", "reverse sort order"),
+			ToggleSelect:    b(config.KeymapTracks, "toggle_select", "select track"),
+			Visual:          b(config.KeymapTracks, "visual", "select a range"),
+			ExtendUp:        b(config.KeymapTracks, "extend_up", "extend selection up"),
+			ExtendDown:      b(config.KeymapTracks, "extend_down", "extend selection down"),
+			SelectAll:       b(config.KeymapTracks, "select_all", "select all / none"),
+			Queue:           b(config.KeymapTracks, "queue", "add to queue"),
+			AddToPlaylist:   b(config.KeymapTracks, "add_to_playlist", "add to playlist"),
+			Remove:          b(config.KeymapTracks, "remove", "remove from playlist"),
+			Like:            b(config.KeymapTracks, "like", "like / unlike"),
+			CopyURIs:        b(config.KeymapTracks, "copy_uris", "copy URIs"),
+			CopyLinks:       b(config.KeymapTracks, "copy_links", "copy links"),
+			Confirm:         b(config.KeymapConfirm, "yes", "confirm"),
+		},
+		Playbar: playbarKeyMap{
+			PlayPause:  b(config.KeymapPlaybar, "play_pause", "play / pause"),
+			Next:       b(config.KeymapPlaybar, "next", "next track"),
+			Previous:   b(config.KeymapPlaybar, "previous", "previous track"),
+			VolumeUp:   b(config.KeymapPlaybar, "volume_up", "volume up"),
+			VolumeDown: b(config.KeymapPlaybar, "volume_down", "volume down"),
+		},
+		Navigation: navigationKeyMap{
+			Submit: b(config.KeymapNavigation, "submit", "search"),
+			Cancel: b(config.KeymapNavigation, "cancel", "cancel"),
+		},
+		Palette: paletteKeyMap{
+			Up:       b(config.KeymapPalette, "up", "previous suggestion"),
+			Down:     b(config.KeymapPalette, "down", "next suggestion"),
+			Complete: b(config.KeymapPalette, "complete", "complete"),
+			Run:      b(config.KeymapPalette, "run", "run command"),
+			Close:    b(config.KeymapPalette, "close", "close"),
+		},
+	}
+}
+
+func (k globalKeyMap) Bindings() []key.Binding {
+	return []key.Binding{k.Help, k.Quit, k.Search, k.CycleFocus, k.CycleFocusBack, k.ToggleQueue, k.ToggleHistory, k.ToggleShuffle, k.CycleTheme, k.Back, k.Forward, k.CommandPalette, k.NarrowSidebar, k.WidenSidebar, k.ToggleSidebar, k.ToggleLogo, k.NowPlaying, k.Lyrics, k.Stats}
+}
+
+func (k listKeyMap) Bindings() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom, k.Filter, k.ClearFilter}
+}
+
+func (k sidebarKeyMap) Bindings() []key.Binding {
+	return []key.Binding{k.Select, k.Retry, k.Export}
+}
+
+func (k tracksKeyMap) Bindings() []key.Binding {
+	return []key.Binding{
+		k.Play, k.Retry,
+		k.FilterPrev, k.FilterNext, k.FilterAll, k.FilterPlaylists, k.FilterAlbums, k.FilterSongs,
+		k.ToggleView, k.Sort, k.SortReverse,
+		k.ToggleSelect, k.Visual, k.ExtendUp, k.ExtendDown, k.SelectAll,
+		k.Queue, k.AddToPlaylist, k.Remove, k.Like, k.CopyURIs, k.CopyLinks,
+	}
+}
+
+func (k playbarKeyMap) Bindings() []key.Binding {
+	return []key.Binding{k.PlayPause, k.Next, k.Previous, k.VolumeUp, k.VolumeDown}
+}
+
+func (k navigationKeyMap) Bindings() []key.Binding {
+	return []key.Binding{k.Submit, k.Cancel}
+}
+
+func (k paletteKeyMap) Bindings() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Complete, k.Run, k.Close}
+}
+
+// listModelKeyMap adapts our list bindings to bubbles/list. The built-in
+// help and the list's own quit key are disabled since the page handles
+// those.
+func (k listKeyMap) listModelKeyMap() list.KeyMap {
+	km := list.DefaultKeyMap()
+	km.CursorUp = k.Up
+	km.CursorDown = k.Down
+	km.PrevPage = k.PageUp
+	km.NextPage = k.PageDown
+	km.GoToStart = k.Top
+	km.GoToEnd = k.Bottom
+	km.Filter = k.Filter
+	km.ClearFilter = k.ClearFilter
+	km.ShowFullHelp.SetEnabled(false)
+	km.CloseFullHelp.SetEnabled(false)
+	km.Quit.SetEnabled(false)
+	km.ForceQuit.SetEnabled(false)
+	return km
+}
+
+// helpKeys renders a key list for the help overlay, e.g. "↑/k".
+func helpKeys(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		switch k {
+		case " ":
+			names[i] = "space"
+		case "up":
+			names[i] = "↑"
+		case "down":
+			names[i] = "↓"
+		case "left":
+			names[i] = "←"
+		case "right":
+			names[i] = "→"
+		case "shift+up":
+			names[i] = "shift+↑"
+		case "shift+down":
+			names[i] = "shift+↓"
+		default:
+			names[i] = k
+		}
+	}
+	return strings.Join(names, "/")
+}
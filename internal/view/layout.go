@@ -0,0 +1,150 @@
+package view
+
+// Breakpoints between the arrangements, in terminal columns.
+const (
+	twoColumnMinWidth   = 80
+	threeColumnMinWidth = 150
+)
+
+// Sizes the arrangements try to keep. They give way before anything is
+// allowed to shrink below zero.
+const (
+	minSidebarWidth    = 20
+	minTracksWidth     = 40
+	minNowPlayingWidth = 30
+	maxNowPlayingWidth = 50
+	maxLyricsWidth     = 60
+	searchBarHeight    = 5 // the search input plus the border around it
+	playbarHeight      = 5
+	// The logo is only shown when the content area keeps at least this
+	// many rows.
+	minContentWithLogo = 20
+)
+
+// Resizing the sidebar moves its share of the width in steps, within the
+// range the config accepts.
+const (
+	sidebarRatioStep = 0.05
+	minSidebarRatio  = 0.1
+	maxSidebarRatio  = 0.9
+)
+
+type layoutMode int
+
+const (
+	// layoutSingle shows one of the sidebar and the tracks at a time, the
+	// focused one.
+	layoutSingle layoutMode = iota
+	layoutTwoColumn
+	// layoutThreeColumn adds a now-playing or lyrics pane to the right.
+	layoutThreeColumn
+)
+
+// layoutOptions are the user's preferences; computeLayout honours them as
+// far as the terminal allows.
+type layoutOptions struct {
+	sidebarRatio   float64
+	showSidebar    bool
+	showLogo       bool
+	logoHeight     int // lines of the logo art
+	nowPlayingPane bool
+	lyrics         bool // the lyrics pane is open
+	sidebarFocused bool // picks the pane shown in single-column mode
+}
+
+// rect is the outer size of a pane, borders included. A zero rect means the
+// pane is hidden.
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) visible() bool {
+	return r.w > 0 && r.h > 0
+}
+
+// inner is the size a bordered pane has for its content.
+func (r rect) inner() (int, int) {
+	return max(r.w-2, 0), max(r.h-2, 0)
+}
+
+type layout struct {
+	mode       layoutMode
+	showLogo   bool
+	nav        rect
+	sidebar    rect
+	tracks     rect
+	nowPlaying rect
+	lyrics     rect
+	playbar    rect
+}
+
+// computeLayout arranges the panes in a width×height terminal. Every size it
+// returns is zero or positive, however small the terminal.
+func computeLayout(width, height int, o layoutOptions) layout {
+	width, height = max(width, 0), max(height, 0)
+	l := layout{mode: layoutSingle}
+	switch {
+	case width >= threeColumnMinWidth && o.nowPlayingPane,
+		width >= twoColumnMinWidth && o.lyrics:
+		l.mode = layoutThreeColumn
+	case width >= twoColumnMinWidth:
+		l.mode = layoutTwoColumn
+	}
+
+	// Rows: search bar, content, playbar. When rows run out the content
+	// gives way first, then the playbar.
+	navHeight := searchBarHeight
+	if o.showLogo && height-(o.logoHeight+4)-playbarHeight >= minContentWithLogo {
+		navHeight = o.logoHeight + 4 // the logo has a blank line above and below
+		l.showLogo = true
+	}
+	navHeight = min(navHeight, height)
+	barHeight := min(playbarHeight, height-navHeight)
+	contentHeight := height - navHeight - barHeight
+
+	l.nav = rect{0, 0, width, navHeight}
+	l.playbar = rect{0, navHeight + contentHeight, width, barHeight}
+	if contentHeight == 0 {
+		return l
+	}
+
+	// Columns: sidebar, tracks, now playing.
+	y := navHeight
+	switch l.mode {
+	case layoutSingle:
+		switch {
+		case o.lyrics:
+			l.lyrics = rect{0, y, width, contentHeight}
+		case o.showSidebar && o.sidebarFocused:
+			l.sidebar = rect{0, y, width, contentHeight}
+		default:
+			l.tracks = rect{0, y, width, contentHeight}
+		}
+		return l
+
+	case layoutThreeColumn:
+		if o.lyrics {
+			lyricsWidth := clamp(width*30/100, minNowPlayingWidth, maxLyricsWidth)
+			l.lyrics = rect{width - lyricsWidth, y, lyricsWidth, contentHeight}
+			width -= lyricsWidth
+			break
+		}
+		npWidth := clamp(width*22/100, minNowPlayingWidth, maxNowPlayingWidth)
+		l.nowPlaying = rect{width - npWidth, y, npWidth, contentHeight}
+		width -= npWidth
+	}
+
+	sidebarWidth := 0
+	if o.showSidebar {
+		sidebarWidth = clamp(int(float64(width)*o.sidebarRatio), minSidebarWidth, width-minTracksWidth)
+		sidebarWidth = max(sidebarWidth, 0)
+		l.sidebar = rect{0, y, sidebarWidth, contentHeight}
+	}
+	l.tracks = rect{sidebarWidth, y, width - sidebarWidth, contentHeight}
+	return l
+}
+
+// clamp limits v to [lo, hi], preferring lo when the range is empty.
+func clamp(v, lo, hi int) int {
+	return max(min(v, hi), lo)
+}
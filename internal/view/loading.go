@@ -0,0 +1,105 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// loader tracks an in-flight fetch for a component and renders its loading
+// and error states: a spinner over skeleton rows while waiting, and the error
+// with a retry hint when the fetch failed.
+type loader struct {
+	spinner spinner.Model
+	loading bool
+	err     error
+}
+
+func newLoader() loader {
+	return loader{spinner: spinner.New(spinner.WithSpinner(spinner.Dot))}
+}
+
+// Start marks the fetch as in flight and starts the spinner.
+func (l *loader) Start() tea.Cmd {
+	l.err = nil
+	if l.loading {
+		return nil
+	}
+	l.loading = true
+	return l.spinner.Tick
+}
+
+// Done records the outcome of the fetch.
+func (l *loader) Done(err error) {
+	l.loading = false
+	l.err = err
+}
+
+// Update advances the spinner while loading. Ticks are dropped once the
+// fetch is done, which stops the animation.
+func (l *loader) Update(msg tea.Msg) tea.Cmd {
+	tick, ok := msg.(spinner.TickMsg)
+	if !ok || !l.loading {
+		return nil
+	}
+	var cmd tea.Cmd
+	l.spinner, cmd = l.spinner.Update(tick)
+	return cmd
+}
+
+// Active reports whether there is a loading or error state to show.
+func (l *loader) Active() bool {
+	return l.loading || l.err != nil
+}
+
+// View renders the loading or error state in a width×height box. rowHeight
+// matches the list delegate so the skeleton looks like the content it
+// stands in for.
+func (l *loader) View(label string, retry key.Binding, width, height, rowHeight int) string {
+	pad := lipgloss.NewStyle().Padding(0, 0, 0, 2)
+
+	if l.err != nil {
+		msg := lipgloss.NewStyle().Foreground(theme.Text).Render("Couldn't load " + label)
+		detail := lipgloss.NewStyle().Foreground(theme.Muted).Width(max(width-4, 1)).Render(l.err.Error())
+		hint := lipgloss.NewStyle().Foreground(theme.Accent).Render("press " + retry.Help().Key + " to retry")
+		return pad.Render(lipgloss.JoinVertical(lipgloss.Left, "", msg, detail, "", hint))
+	}
+
+	l.spinner.Style = lipgloss.NewStyle().Foreground(theme.Accent)
+	header := l.spinner.View() + lipgloss.NewStyle().Foreground(theme.Muted).Render(" Loading "+label+"…")
+	return pad.Render(lipgloss.JoinVertical(lipgloss.Left, "", header, "", skeleton(width-4, height-3, rowHeight)))
+}
+
+// skeleton draws placeholder rows of varying length.
+func skeleton(width, height, rowHeight int) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	style := lipgloss.NewStyle().Foreground(theme.Dim)
+	widths := []int{70, 45, 60, 35, 80, 50}
+
+	var lines []string
+	for i := 0; len(lines)+rowHeight <= height; i++ {
+		w := max(width*widths[i%len(widths)]/100, 1)
+		lines = append(lines, style.Render(strings.Repeat("░", w)))
+		for j := 1; j < rowHeight; j++ {
+			lines = append(lines, style.Render(strings.Repeat("░", max(w/2, 1))))
+		}
+		if len(lines) < height {
+			lines = append(lines, "")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// emptyState renders a centred hint for a list with nothing in it.
+func emptyState(text string, width int) string {
+	return lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Padding(1, 2).
+		Width(max(width, 1)).
+		Render(text)
+}
@@ -0,0 +1,157 @@
+package view
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/lyrics"
+)
+
+type lyricsLoadedMsg struct {
+	trackID string
+	lyrics  *lyrics.Lyrics
+	err     error
+}
+
+// LyricsPane shows the lyrics of the current track, keeping the line being
+// sung highlighted in the middle of the pane. Like NowPlaying it follows
+// the playbar's state.
+type LyricsPane struct {
+	playbar *Playbar
+	finder  *lyrics.Finder
+	dir     string // where local lyrics are read from, for the empty state
+	timeout time.Duration
+	open    bool
+
+	trackID string // the track lyrics were requested for
+	lyrics  *lyrics.Lyrics
+	err     error
+	loading bool
+}
+
+func NewLyricsPane(playbar *Playbar, finder *lyrics.Finder, dir string, timeout time.Duration) *LyricsPane {
+	return &LyricsPane{playbar: playbar, finder: finder, dir: dir, timeout: timeout}
+}
+
+// Toggle shows or hides the pane, looking the lyrics up when it opens.
+func (l *LyricsPane) Toggle() tea.Cmd {
+	if l.finder == nil {
+		return nil
+	}
+	l.open = !l.open
+	return l.fetch()
+}
+
+func (l *LyricsPane) Opened() bool {
+	return l.open
+}
+
+// Update stores looked up lyrics and, while open, starts a lookup whenever
+// the track changes.
+func (l *LyricsPane) Update(msg tea.Msg) tea.Cmd {
+	if m, ok := msg.(lyricsLoadedMsg); ok {
+		if m.trackID == l.trackID {
+			l.lyrics, l.err, l.loading = m.lyrics, m.err, false
+		}
+		return nil
+	}
+	return l.fetch()
+}
+
+func (l *LyricsPane) fetch() tea.Cmd {
+	state, _ := l.playbar.current()
+	if !l.open || state == nil || state.Track.ID == "" || state.Track.ID == l.trackID {
+		return nil
+	}
+
+	track := state.Track
+	l.trackID, l.lyrics, l.err, l.loading = track.ID, nil, nil, true
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+		defer cancel()
+		found, err := l.finder.Find(ctx, track)
+		return lyricsLoadedMsg{trackID: track.ID, lyrics: found, err: err}
+	}
+}
+
+func (l *LyricsPane) View(width, height int) string {
+	box := borderStyle().Width(width).Height(height)
+	state, elapsed := l.playbar.current()
+
+	title := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render("Lyrics")
+	bodyHeight := max(height-2, 0)
+	var body string
+	switch {
+	case state == nil || state.Track.ID == "":
+		body = l.message("Nothing playing", width, bodyHeight)
+	case l.loading:
+		body = l.message("Looking for lyrics…", width, bodyHeight)
+	case errors.Is(l.err, lyrics.ErrNotFound):
+		body = l.message("No lyrics for this track.\nAdd an .lrc file to "+l.dir, width, bodyHeight)
+	case l.err != nil:
+		body = l.message("Couldn't load lyrics: "+l.err.Error(), width, bodyHeight)
+	case l.lyrics != nil:
+		body = l.lines(state, elapsed, width, bodyHeight)
+	}
+	return box.Render(lipgloss.JoinVertical(lipgloss.Left, " "+title, "", body))
+}
+
+func (l *LyricsPane) message(text string, width, height int) string {
+	text = lipgloss.NewStyle().Width(max(width-4, 1)).Align(lipgloss.Center).Foreground(theme.Muted).Render(text)
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, text)
+}
+
+// lines renders the lyrics with the current line centred. Unsynced lyrics
+// scroll along with the track's progress instead.
+func (l *LyricsPane) lines(state *entities.PlaybackState, elapsed, width, height int) string {
+	textWidth := max(width-4, 1)
+	current := l.lyrics.LineAt(time.Duration(elapsed) * time.Millisecond)
+
+	// Wrap every line, remembering where the current one starts.
+	var rows []string
+	currentRow := 0
+	for i, line := range l.lyrics.Lines {
+		style := lipgloss.NewStyle().Foreground(theme.Text)
+		switch {
+		case !l.lyrics.Synced:
+		case i == current:
+			style = lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+			currentRow = len(rows)
+		case i < current:
+			style = lipgloss.NewStyle().Foreground(theme.Muted)
+		default:
+			style = lipgloss.NewStyle().Foreground(theme.Subtext)
+		}
+		text := line.Text
+		if text == "" {
+			text = "♪"
+		}
+		for _, row := range strings.Split(ansi.Wordwrap(text, textWidth, ""), "\n") {
+			rows = append(rows, style.Render(row))
+		}
+	}
+
+	var top int
+	if l.lyrics.Synced {
+		// Pad the start so even the first lines sit in the middle.
+		pad := max(height/2-currentRow, 0)
+		rows = append(make([]string, pad), rows...)
+		top = currentRow + pad - height/2
+	} else if state.Track.DurationMs > 0 {
+		top = (len(rows) - height) * elapsed / state.Track.DurationMs
+	}
+	top = clamp(top, 0, max(len(rows)-height, 0))
+	rows = rows[top:min(top+height, len(rows))]
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		Align(lipgloss.Center).
+		Render(strings.Join(rows, "\n"))
+}
@@ -2,6 +2,7 @@ package view
 
 import (
 	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/history"
 )
 
 type MsgType string
@@ -25,20 +26,22 @@ const (
 	MsgPlaybackUpdate   MsgType = "playback.update"
 	MsgQueueUpdate      MsgType = "queue.update"
 	MsgToggleQueue      MsgType = "toggle.queue"
+	MsgToggleHistory    MsgType = "toggle.history"
 	MsgToggleShuffle    MsgType = "toggle.shuffle"
-	MsgSearch			MsgType = "search"
+	MsgSearch           MsgType = "search"
 	MsgFocusSearch      MsgType = "focus.search"
+	MsgNavigateBack     MsgType = "navigate.back"
+	MsgNavigateForward  MsgType = "navigate.forward"
 )
 
-
 // Actual message structs
 
 type SearchResultsMsg struct {
-	Tracks  []entities.Track
-	Albums  []entities.Album
-	Artists []entities.Artist
+	Tracks    []entities.Track
+	Albums    []entities.Album
+	Artists   []entities.Artist
 	Playlists []entities.Playlist
-	Query string
+	Query     string
 }
 type TabChangedMsg struct {
 	Tab int // 0=Search, 1=Home, 2=Browse
@@ -46,9 +49,13 @@ type TabChangedMsg struct {
 
 type FocusSearchMsg struct{}
 type PlaylistSelectedMsg struct {
-	ID   string
-	Name string
-	URI  string
+	ID         string
+	Name       string
+	URI        string
+	ImageURL   string
+	Owner      string
+	TrackCount int
+	SnapshotID string
 }
 
 type SearchMsg struct {
@@ -64,7 +71,7 @@ type SearchQueryMsg struct {
 }
 
 type PlayTrackMsg struct {
-	TrackURI     string
+	TrackURI    string
 	PlaylistURI string // If playing from a playlist, the playlist URI for context
 }
 
@@ -78,16 +85,75 @@ type QueueUpdateMsg struct {
 
 type ToggleQueueMsg struct{}
 
+type ToggleHistoryMsg struct{}
+
 type ToggleShuffleMsg struct{}
+
+type NavigateMsg struct{}
+
 // Internal messages for async operations
 type tracksLoadedMsg struct {
-	tracks []entities.Track
+	playlistID string
+	tracks     []entities.Track
+}
+
+type playlistsLoadedMsg struct {
+	playlists []entities.Playlist
+}
+
+type playlistsFailedMsg struct {
+	err error
+}
+
+type tracksFailedMsg struct {
+	playlistID string
+	err        error
 }
 
 type queueLoadedMsg struct {
 	tracks []entities.Track
 }
 
+type likedLoadedMsg struct {
+	liked map[string]bool
+}
+
+// bulkActionMsg reports the outcome of a bulk action on selected tracks,
+// or of a playlist export, in the track list's title bar. liked carries
+// Liked Songs changes made by the action.
+type bulkActionMsg struct {
+	status string
+	liked  map[string]bool
+	err    error
+}
+
+type tracksRemovedMsg struct {
+	playlistID string
+	snapshotID string
+	count      int
+}
+
+type pickerPlaylistsMsg struct {
+	playlists []entities.Playlist
+	err       error
+}
+
+type statusExpiredMsg struct {
+	id int
+}
+
+type queueFailedMsg struct {
+	err error
+}
+
+type historyLoadedMsg struct {
+	entries []history.Entry
+}
+
+type historyFailedMsg struct {
+	err error
+}
+
 type errMsg struct {
 	Err error
 }
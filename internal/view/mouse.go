@@ -0,0 +1,94 @@
+package view
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const doubleClickInterval = 400 * time.Millisecond
+
+// region is the area of the screen a component was last drawn in.
+type region struct {
+	x, y, w, h int
+}
+
+func (r region) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+// local translates a mouse event into coordinates relative to r.
+func (r region) local(m tea.MouseMsg) tea.MouseMsg {
+	m.X -= r.x
+	m.Y -= r.y
+	return m
+}
+
+// span is a horizontal run of cells on one line, e.g. a clickable label.
+type span struct {
+	y, x, w int
+}
+
+func (s span) contains(x, y int) bool {
+	return y == s.y && x >= s.x && x < s.x+s.w
+}
+
+// clickTracker turns consecutive clicks on the same row into double-clicks.
+type clickTracker struct {
+	index int
+	at    time.Time
+}
+
+// Click records a click on index and reports whether it completes a
+// double-click.
+func (c *clickTracker) Click(index int) bool {
+	now := time.Now()
+	double := index == c.index && now.Sub(c.at) < doubleClickInterval
+	c.index, c.at = index, now
+	if double {
+		// A third click starts over rather than double-clicking again.
+		c.at = time.Time{}
+	}
+	return double
+}
+
+func isWheel(m tea.MouseMsg) bool {
+	return m.Button == tea.MouseButtonWheelUp || m.Button == tea.MouseButtonWheelDown
+}
+
+// listRowAt maps a line, counted from the first row of the list, to the
+// index of the visible item drawn there. Clicks on the spacing between
+// items miss.
+func listRowAt(m list.Model, line, itemHeight, spacing int) (int, bool) {
+	if line < 0 {
+		return 0, false
+	}
+	row, offset := line/(itemHeight+spacing), line%(itemHeight+spacing)
+	if offset >= itemHeight || row >= m.Paginator.PerPage {
+		return 0, false
+	}
+	index := m.Paginator.Page*m.Paginator.PerPage + row
+	if index >= len(m.VisibleItems()) {
+		return 0, false
+	}
+	return index, true
+}
+
+// scrollList moves the cursor for a wheel event, a few lines at a time.
+func scrollList(m *list.Model, wheel tea.MouseMsg, itemHeight, spacing int) {
+	const wheelLines = 3
+	for range max(wheelLines/(itemHeight+spacing), 1) {
+		if wheel.Button == tea.MouseButtonWheelUp {
+			m.CursorUp()
+		} else {
+			m.CursorDown()
+		}
+	}
+}
+
+// listRowsTop is the number of lines a list draws above its first row.
+func listRowsTop(m list.Model) int {
+	return lipgloss.Height(m.Styles.TitleBar.Render(m.Styles.Title.Render(m.Title)))
+}
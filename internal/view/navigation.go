@@ -1,6 +1,7 @@
 package view
 
 import (
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -12,9 +13,11 @@ type Navigation struct {
 	searching   bool
 	searchInput textinput.Model
 	bus         *MessageBus
+	showLogo    bool
+	keys        navigationKeyMap
 }
 
-func NewNavigation(bus *MessageBus) *Navigation {
+func NewNavigation(bus *MessageBus, showLogo bool, keys navigationKeyMap) *Navigation {
 	ti := textinput.New()
 	ti.Placeholder = "Search songs, artists..."
 	ti.CharLimit = 100
@@ -22,13 +25,15 @@ func NewNavigation(bus *MessageBus) *Navigation {
 	self := &Navigation{
 		searchInput: ti,
 		bus:         bus,
+		showLogo:    showLogo,
+		keys:        keys,
 	}
 	bus.Subscribe(MsgFocusSearch, self)
 	return self
 }
 
 func (n *Navigation) OnMessage(t MsgType, msg tea.Msg) tea.Cmd {
-	
+
 	if t == MsgFocusSearch {
 		return func() tea.Msg {
 			return FocusSearchMsg{}
@@ -43,20 +48,20 @@ func (n *Navigation) Update(msg tea.Msg) (Component, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case FocusSearchMsg:
-        n.searching = true
-        n.searchInput.Focus()
-        return n, textinput.Blink
+		n.searching = true
+		n.searchInput.Focus()
+		return n, textinput.Blink
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, n.keys.Cancel):
 			if n.searching {
 				n.searching = false
 				n.searchInput.Blur()
 				n.searchInput.SetValue("")
 				return n, nil
 			}
-		case "enter":
+		case key.Matches(msg, n.keys.Submit):
 			if n.searching {
 				query := n.searchInput.Value()
 				if query != "" {
@@ -81,6 +86,10 @@ func (n *Navigation) Update(msg tea.Msg) (Component, tea.Cmd) {
 	return n, cmd
 }
 
+func (n *Navigation) KeyBindings() []key.Binding {
+	return n.keys.Bindings()
+}
+
 func (n *Navigation) Blur() {
 	n.focused = false
 	if n.searching {
@@ -94,13 +103,21 @@ func (n *Navigation) Focus() {
 	n.focused = true
 }
 
+// SetShowLogo shows or hides the logo next to the search bar.
+func (n *Navigation) SetShowLogo(show bool) {
+	n.showLogo = show
+}
+
 func (n *Navigation) Focused() bool {
 	return n.focused
 }
 func (n *Navigation) View(width, height int) string {
 	halfWidth := width / 2
+	if !n.showLogo {
+		halfWidth = width
+	}
 
-	logoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#1db954"))
+	logoStyle := lipgloss.NewStyle().Foreground(theme.Accent)
 	logo := logoStyle.Width(halfWidth).Align(lipgloss.Right).PaddingRight(1).Render(assets.SpotifyLogo)
 
 	inputStyle := lipgloss.NewStyle().
@@ -109,9 +126,9 @@ func (n *Navigation) View(width, height int) string {
 		Width(halfWidth - 4)
 
 	if n.searching {
-		inputStyle = inputStyle.BorderForeground(lipgloss.Color("#1db954"))
+		inputStyle = inputStyle.BorderForeground(theme.Accent)
 	} else {
-		inputStyle = inputStyle.BorderForeground(lipgloss.Color("#535353"))
+		inputStyle = inputStyle.BorderForeground(theme.Dim)
 	}
 
 	searchInput := inputStyle.Render(n.searchInput.View())
@@ -124,15 +141,18 @@ func (n *Navigation) View(width, height int) string {
 		Width(halfWidth).
 		Render(searchInput)
 
-	content := lipgloss.JoinHorizontal(lipgloss.Top, left, logo)
+	content := left
+	if n.showLogo {
+		content = lipgloss.JoinHorizontal(lipgloss.Top, left, logo)
+	}
 
-	border := borderStyle.Copy().
+	border := borderStyle().
 		Width(width).
 		Height(height)
 
 	if n.Focused() {
-		border = border.BorderForeground(lipgloss.Color("#1db954"))
+		border = border.BorderForeground(theme.Accent)
 	}
 
 	return border.Render(content)
-}
\ No newline at end of file
+}
@@ -0,0 +1,318 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/thomassbooth/spotify-tui/internal/art"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+)
+
+// nowPlayingArtSize is the smallest image worth scaling up to a large cover.
+const nowPlayingArtSize = 300
+
+// maxUpNext is how many queued tracks the full-screen view lists.
+const maxUpNext = 5
+
+type upNextLoadedMsg struct {
+	trackID string // the track playing when the queue was fetched
+	tracks  []entities.Track
+}
+
+// NowPlaying shows the current track with large cover art, either as a
+// third column on wide terminals or full screen. It reads the playback
+// state kept by the playbar rather than polling on its own.
+type NowPlaying struct {
+	playbar         *Playbar
+	playbackService service.Playback
+	playlistService service.Playlists
+	art             *coverArt
+	open            bool // shown full screen
+	upNext          []entities.Track
+	upNextFor       string // track ID upNext was fetched for
+}
+
+func NewNowPlaying(playbar *Playbar, playbackService service.Playback, playlistService service.Playlists, art *coverArt) *NowPlaying {
+	return &NowPlaying{
+		playbar:         playbar,
+		playbackService: playbackService,
+		playlistService: playlistService,
+		art:             art,
+	}
+}
+
+// Open switches to the full-screen view.
+func (n *NowPlaying) Open() tea.Cmd {
+	n.open = true
+	return n.fetchUpNext()
+}
+
+func (n *NowPlaying) Close() {
+	n.open = false
+}
+
+func (n *NowPlaying) Opened() bool {
+	return n.open
+}
+
+// Update loads the large cover once the track is known and, while full
+// screen, refreshes the queue whenever the track changes.
+func (n *NowPlaying) Update(msg tea.Msg) tea.Cmd {
+	if m, ok := msg.(upNextLoadedMsg); ok {
+		n.upNext, n.upNextFor = m.tracks, m.trackID
+		return nil
+	}
+
+	state, _ := n.playbar.current()
+	if state == nil {
+		return nil
+	}
+	cmd := n.art.Load(art.PickImage(state.Track.Album.Images, nowPlayingArtSize))
+	if n.open && state.Track.ID != n.upNextFor {
+		n.upNextFor = state.Track.ID
+		cmd = tea.Batch(cmd, n.fetchUpNext())
+	}
+	return cmd
+}
+
+// fetchUpNext loads the queue. Failures leave the last list in place.
+func (n *NowPlaying) fetchUpNext() tea.Cmd {
+	state, _ := n.playbar.current()
+	if state == nil || state.Track.ID == "" {
+		return nil
+	}
+	trackID := state.Track.ID
+	return func() tea.Msg {
+		tracks, err := n.playbackService.UpNext()
+		if err != nil {
+			return nil
+		}
+		return upNextLoadedMsg{trackID: trackID, tracks: tracks}
+	}
+}
+
+// View renders the side pane.
+func (n *NowPlaying) View(width, height int) string {
+	state, elapsed := n.playbar.current()
+	box := borderStyle().Width(width).Height(height)
+	if state == nil || state.Track.ID == "" {
+		return box.Render(nothingPlaying(width, height))
+	}
+
+	textWidth := max(width-2, 0)
+	info := nowPlayingInfo(state, elapsed, textWidth)
+
+	var cover string
+	if n.art.Enabled() {
+		// Square cells are twice as tall as wide; leave room for the text.
+		rows := min(textWidth/2, height-lipgloss.Height(info)-1)
+		if rows >= 4 {
+			cover = n.art.View(art.PickImage(state.Track.Album.Images, nowPlayingArtSize), rows*2, rows)
+		}
+	}
+
+	body := info
+	if cover != "" {
+		body = lipgloss.JoinVertical(lipgloss.Center, cover, "", info)
+	}
+	return box.Render(lipgloss.Place(width, height, lipgloss.Center, lipgloss.Top, body))
+}
+
+// FullView renders the full-screen view: the cover next to the track,
+// context, device and queue, with a wide progress bar underneath. Narrow
+// terminals get the cover above the details instead.
+func (n *NowPlaying) FullView(width, height int, closeHint string) string {
+	state, elapsed := n.playbar.current()
+	hint := lipgloss.NewStyle().Foreground(theme.Muted).Render("press " + closeHint + " or esc to close")
+	if state == nil || state.Track.ID == "" {
+		return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center,
+			lipgloss.JoinVertical(lipgloss.Center, nothingPlaying(width, 1), "", hint))
+	}
+
+	const margin = 2
+	inner := max(width-2*margin, 0)
+	progress := n.fullProgress(state, elapsed, inner)
+	bodyHeight := max(height-lipgloss.Height(progress)-3, 0)
+
+	stacked := width < 80
+	detailsWidth := inner
+	var cover string
+	if n.art.Enabled() {
+		rows := min(bodyHeight, inner/4)
+		if stacked {
+			rows = min(bodyHeight/2, inner/2)
+		}
+		if rows >= 4 {
+			cover = n.art.View(art.PickImage(state.Track.Album.Images, nowPlayingArtSize), rows*2, rows)
+		}
+	}
+	if cover != "" && !stacked {
+		detailsWidth = max(inner-lipgloss.Width(cover)-4, 0)
+	}
+	details := n.details(state, detailsWidth)
+
+	var body string
+	switch {
+	case cover == "":
+		body = details
+	case stacked:
+		body = lipgloss.JoinVertical(lipgloss.Left, cover, "", details)
+	default:
+		body = lipgloss.JoinHorizontal(lipgloss.Center, cover, "    ", details)
+	}
+	body = lipgloss.NewStyle().MaxHeight(bodyHeight).Render(body)
+
+	view := lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.Place(inner, bodyHeight, lipgloss.Left, lipgloss.Center, body),
+		"",
+		progress,
+		"",
+		lipgloss.PlaceHorizontal(inner, lipgloss.Center, hint),
+	)
+	return lipgloss.NewStyle().Padding(0, margin).MaxHeight(height).Render(view)
+}
+
+// details lists what is playing, where from, on which device, and what
+// comes next.
+func (n *NowPlaying) details(state *entities.PlaybackState, width int) string {
+	fit := func(s string) string { return ansi.Truncate(s, width, "…") }
+	label := lipgloss.NewStyle().Foreground(theme.Muted).Bold(true)
+	muted := lipgloss.NewStyle().Foreground(theme.Muted)
+	track := state.Track
+
+	var lines []string
+	if kind, name := n.contextName(state); name != "" {
+		lines = append(lines,
+			label.Render(fit("PLAYING FROM "+strings.ToUpper(kind))),
+			lipgloss.NewStyle().Foreground(theme.Subtext).Render(fit(name)),
+			"")
+	}
+	lines = append(lines,
+		lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render(fit(track.Name)),
+		lipgloss.NewStyle().Foreground(theme.Text).Render(fit(trackArtists(track))),
+		muted.Render(fit(track.Album.Name)),
+		"")
+
+	device := state.Device.Name
+	if device == "" {
+		device = "an unknown device"
+	}
+	if state.Device.Type != "" {
+		device += " (" + strings.ToLower(state.Device.Type) + ")"
+	}
+	status := "Paused"
+	if state.IsPlaying {
+		status = "Playing"
+	}
+	volume := fmt.Sprintf("volume %d%%", state.Device.VolumePercent)
+	if state.Device.IsMuted {
+		volume = "muted"
+	}
+	lines = append(lines,
+		lipgloss.NewStyle().Foreground(theme.Text).Render(fit(status+" on "+device)),
+		muted.Render(fit(volume+" · "+shuffleLabel(state.ShuffleState)+" · "+repeatLabel(state.RepeatState))),
+	)
+
+	if len(n.upNext) > 0 && n.upNextFor == track.ID {
+		lines = append(lines, "", label.Render("NEXT UP"))
+		for i, t := range n.upNext[:min(len(n.upNext), maxUpNext)] {
+			lines = append(lines, muted.Render(fit(fmt.Sprintf("%d. %s — %s", i+1, t.Name, trackArtists(t)))))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// contextName names the playlist, album or artist being played, if any.
+func (n *NowPlaying) contextName(state *entities.PlaybackState) (kind, name string) {
+	ctx := state.Context
+	if ctx == nil {
+		return "", ""
+	}
+	switch {
+	case ctx.Type == "album":
+		return "album", state.Track.Album.Name
+	case ctx.Type == "artist" && len(state.Track.Artists) > 0:
+		return "artist", state.Track.Artists[0].Name
+	case strings.HasSuffix(ctx.URI, ":collection"):
+		return "library", "Liked Songs"
+	case ctx.Type == "playlist":
+		playlists, _ := n.playlistService.CachedPlaylists()
+		for _, pl := range playlists {
+			if pl.URI == ctx.URI {
+				return "playlist", pl.Name
+			}
+		}
+		return "playlist", "a playlist"
+	}
+	return "", ""
+}
+
+// fullProgress renders a wide bar with the elapsed time on the left and the
+// remaining time on the right.
+func (n *NowPlaying) fullProgress(state *entities.PlaybackState, elapsed, width int) string {
+	total := state.Track.DurationMs
+	elapsed = min(elapsed, total)
+	filled := 0
+	if total > 0 {
+		filled = min(elapsed*width/total, width)
+	}
+	bar := lipgloss.NewStyle().Foreground(theme.Accent).Render(strings.Repeat("━", filled)) +
+		lipgloss.NewStyle().Foreground(theme.Dim).Render(strings.Repeat("─", width-filled))
+
+	left := formatDuration(elapsed)
+	right := "-" + formatDuration(total-elapsed)
+	times := left + strings.Repeat(" ", max(width-len(left)-len(right), 1)) + right
+	return lipgloss.JoinVertical(lipgloss.Left, bar, lipgloss.NewStyle().Foreground(theme.Muted).Render(times))
+}
+
+// nowPlayingInfo renders the track details and progress in width columns.
+func nowPlayingInfo(state *entities.PlaybackState, elapsed, width int) string {
+	track := state.Track
+	fit := func(s string) string { return ansi.Truncate(s, width, "…") }
+
+	times := fmt.Sprintf("%s / %s", formatDuration(elapsed), formatDuration(track.DurationMs))
+	lines := []string{
+		lipgloss.NewStyle().Foreground(theme.Text).Bold(true).Render(fit(track.Name)),
+		lipgloss.NewStyle().Foreground(theme.Subtext).Render(fit(trackArtists(track))),
+		lipgloss.NewStyle().Foreground(theme.Muted).Render(fit(track.Album.Name)),
+		"",
+		renderProgressBar(elapsed, track.DurationMs, width),
+		lipgloss.NewStyle().Foreground(theme.Muted).Render(times),
+	}
+	return lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(strings.Join(lines, "\n"))
+}
+
+func nothingPlaying(width, height int) string {
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center,
+		lipgloss.NewStyle().Foreground(theme.Muted).Render("Nothing playing"))
+}
+
+func trackArtists(t entities.Track) string {
+	names := make([]string, len(t.Artists))
+	for i, a := range t.Artists {
+		names[i] = a.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func shuffleLabel(on bool) string {
+	if on {
+		return "shuffled"
+	}
+	return "unshuffled"
+}
+
+// repeatLabel describes a Spotify repeat state.
+func repeatLabel(mode string) string {
+	switch mode {
+	case "context":
+		return "repeat all"
+	case "track":
+		return "repeat one"
+	}
+	return "no repeat"
+}
@@ -1,12 +1,20 @@
 package view
 
 import (
+	"errors"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/thomassbooth/spotify-tui/internal/assets"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/history"
+	"github.com/thomassbooth/spotify-tui/internal/lyrics"
 	"github.com/thomassbooth/spotify-tui/internal/service"
+	themes "github.com/thomassbooth/spotify-tui/internal/theme"
 )
 
 type Component interface {
@@ -15,36 +23,106 @@ type Component interface {
 	Focus()
 	Blur()
 	Focused() bool
+	KeyBindings() []key.Binding
 }
 
 type Page struct {
-	sidebar    Component
-	navigation Component
-	tracks     Component
-	playbar    Component
-	bus        *MessageBus
-	width      int
-	height     int
+	sidebar     Component
+	navigation  Component
+	tracks      Component
+	playbar     Component
+	nowPlaying  *NowPlaying
+	lyrics      *LyricsPane
+	stats       *StatsView
+	importer    *ImportView
+	bus         *MessageBus
+	keys        KeyMap
+	help        help.Model
+	showHelp    bool
+	palette     *palette
+	showPalette bool
+	starting    spinner.Model
+	themes      *themes.Registry
+	layout      config.LayoutConfig
+	width       int
+	height      int
+	regions     []componentRegion // where each component was last drawn
+	mode        layoutMode        // arrangement of the last frame
 }
 
-func NewPage(playlistService *service.PlaylistService, playbackService *service.PlaybackService) *Page {
+type componentRegion struct {
+	c Component
+	region
+}
+
+// NewPage builds the UI. listens is the listening history, nil when it is
+// turned off.
+func NewPage(cfg *config.Config, themeRegistry *themes.Registry, playlistService service.Playlists, playbackService service.Playback, listens *history.Store) *Page {
+	if t, ok := themeRegistry.Get(cfg.Theme.Name); ok {
+		theme = t
+	}
+
+	keys := NewKeyMap(cfg.Keymap)
+	art := newCoverArt(cfg.Art, cfg.Polling.RequestTimeout.Duration)
 	bus := NewMessageBus()
-	sidebar := NewSidebar(bus, playlistService)
+	sidebar := NewSidebar(bus, playlistService, keys.List, keys.Sidebar, cfg.Export)
 	sidebar.Focus()
-	tracks := NewPlaylistTracks(bus, playlistService, playbackService)
-	playbar := NewPlaybar(bus, playbackService)
-	nav := NewNavigation(bus)
-	return &Page{
+	tracks := NewPlaylistTracks(bus, playlistService, playbackService, listens, keys.List, keys.Tracks, art, cfg.Polling.RequestTimeout.Duration, cfg.Layout.TrackView == config.TrackViewTable)
+	playbar := NewPlaybar(bus, playbackService, cfg.Polling, keys.Playbar, art)
+	nav := NewNavigation(bus, cfg.Layout.ShowLogo, keys.Navigation)
+
+	var finder *lyrics.Finder
+	if cfg.Lyrics.Enabled {
+		finder = lyrics.NewFinder(lyrics.NewLocalProvider(cfg.Lyrics.Dir))
+	}
+
+	commands := NewCommandRegistry()
+	p := &Page{
 		sidebar:    sidebar,
 		navigation: nav,
 		tracks:     tracks,
 		playbar:    playbar,
+		nowPlaying: NewNowPlaying(playbar, playbackService, playlistService, art),
+		lyrics:     NewLyricsPane(playbar, finder, cfg.Lyrics.Dir, cfg.Polling.RequestTimeout.Duration),
+		stats:      NewStatsView(playlistService, listens, keys.List, keys.Tracks),
+		importer:   NewImportView(playlistService, keys.List, keys.Tracks),
 		bus:        bus,
+		keys:       keys,
+		help:       help.New(),
+		starting:   spinner.New(spinner.WithSpinner(spinner.Dot)),
+		themes:     themeRegistry,
+		layout:     cfg.Layout,
+		palette:    newPalette(commands, keys.Palette),
+	}
+	for _, c := range []Component{nav, sidebar, tracks, playbar} {
+		if r, ok := c.(commandRegistrar); ok {
+			r.RegisterCommands(commands)
+		}
+	}
+	p.RegisterCommands(commands)
+	bus.Subscribe(MsgPlaylistSelected, p)
+	return p
+}
+
+// Subscribe lets code outside the view follow its messages, e.g. to run
+// hooks when a playlist is opened.
+func (p *Page) Subscribe(t MsgType, sub Subscriber) {
+	p.bus.Subscribe(t, sub)
+}
+
+// OnMessage follows an opened playlist to the track list when only one of
+// them fits on screen.
+func (p *Page) OnMessage(t MsgType, msg tea.Msg) tea.Cmd {
+	if t == MsgPlaylistSelected && p.mode == layoutSingle && p.sidebar.Focused() {
+		p.focus(p.tracks)
 	}
+	return nil
 }
 
+// Init kicks off every initial fetch in the background; components show
+// their own loading state until the data arrives.
 func (p *Page) Init() tea.Cmd {
-	return p.playbar.(*Playbar).Init()
+	return tea.Batch(p.starting.Tick, p.sidebar.(*Sidebar).Init(), p.playbar.(*Playbar).Init())
 }
 
 func (p *Page) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -53,23 +131,112 @@ func (p *Page) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch m := msg.(type) {
 	case tea.KeyMsg:
-		if m.String() == "q" {
+		if p.showPalette {
+			cmd, done := p.palette.Update(msg)
+			if done {
+				p.showPalette = false
+			}
+			return p, cmd
+		}
+		if p.nowPlaying.Opened() {
+			return p, p.updateNowPlaying(m)
+		}
+		if p.stats.Opened() {
+			return p, p.updateStats(m)
+		}
+		if p.importer.Opened() {
+			return p, p.updateImport(m)
+		}
+		// While typing a search query every key belongs to the input.
+		if nav, ok := p.navigation.(*Navigation); ok && nav.searching {
+			p.navigation, cmd = p.navigation.Update(msg)
+			return p, cmd
+		}
+		// Likewise while a component is capturing input, e.g. a list filter.
+		if f, ok := p.focused().(inputCapturer); ok && f.capturingInput() {
+			_, cmd = f.Update(msg)
+			return p, cmd
+		}
+		if p.showHelp {
+			switch {
+			case key.Matches(m, p.keys.Global.Quit):
+				return p, tea.Quit
+			case key.Matches(m, p.keys.Global.Help), m.String() == "esc":
+				p.showHelp = false
+			}
+			return p, nil
+		}
+
+		if key.Matches(m, p.keys.Global.Help) {
+			p.showHelp = true
+			return p, nil
+		}
+		if key.Matches(m, p.keys.Global.Quit) {
 			return p, tea.Quit
 		}
-		if m.String() == "/" {
+		if key.Matches(m, p.keys.Global.CommandPalette) {
+			p.showPalette = true
+			return p, p.palette.Open()
+		}
+		if key.Matches(m, p.keys.Global.Search) {
 			p.focusNav()
 			cmds = append(cmds, p.bus.Publish(MsgFocusSearch, FocusSearchMsg{}))
 			return p, tea.Batch(cmds...)
 		}
-		if m.String() == "tab" {
-			p.cycleFocus()
+		if key.Matches(m, p.keys.Global.CycleFocus) {
+			p.cycleFocus(1)
 			return p, nil
 		}
-		if m.String() == "Q" {
+		if key.Matches(m, p.keys.Global.CycleFocusBack) {
+			p.cycleFocus(-1)
+			return p, nil
+		}
+		if key.Matches(m, p.keys.Global.ToggleQueue) {
 			cmds = append(cmds, p.bus.Publish(MsgToggleQueue, ToggleQueueMsg{}))
 			return p, tea.Batch(cmds...)
 		}
-		if m.String() == "S" {
+		if key.Matches(m, p.keys.Global.ToggleHistory) {
+			cmds = append(cmds, p.bus.Publish(MsgToggleHistory, ToggleHistoryMsg{}))
+			return p, tea.Batch(cmds...)
+		}
+		if key.Matches(m, p.keys.Global.Back) {
+			cmds = append(cmds, p.bus.Publish(MsgNavigateBack, NavigateMsg{}))
+			return p, tea.Batch(cmds...)
+		}
+		if key.Matches(m, p.keys.Global.Forward) {
+			cmds = append(cmds, p.bus.Publish(MsgNavigateForward, NavigateMsg{}))
+			return p, tea.Batch(cmds...)
+		}
+		if key.Matches(m, p.keys.Global.NarrowSidebar) {
+			p.layout.SidebarRatio = max(p.layout.SidebarRatio-sidebarRatioStep, minSidebarRatio)
+			return p, nil
+		}
+		if key.Matches(m, p.keys.Global.WidenSidebar) {
+			p.layout.SidebarRatio = min(p.layout.SidebarRatio+sidebarRatioStep, maxSidebarRatio)
+			return p, nil
+		}
+		if key.Matches(m, p.keys.Global.ToggleSidebar) {
+			p.toggleSidebar()
+			return p, nil
+		}
+		if key.Matches(m, p.keys.Global.NowPlaying) {
+			return p, p.nowPlaying.Open()
+		}
+		if key.Matches(m, p.keys.Global.Lyrics) {
+			return p, p.lyrics.Toggle()
+		}
+		if key.Matches(m, p.keys.Global.Stats) {
+			return p, p.stats.Open()
+		}
+		if key.Matches(m, p.keys.Global.ToggleLogo) {
+			p.layout.ShowLogo = !p.layout.ShowLogo
+			return p, nil
+		}
+		if key.Matches(m, p.keys.Global.CycleTheme) {
+			theme = p.themes.Next(theme.Name)
+			return p, nil
+		}
+		if key.Matches(m, p.keys.Global.ToggleShuffle) {
 			cmds = append(cmds, p.bus.Publish(MsgToggleShuffle, ToggleShuffleMsg{}))
 			return p, tea.Batch(cmds...)
 		}
@@ -87,7 +254,10 @@ func (p *Page) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			p.playbar, cmd = p.playbar.Update(msg)
 			cmds = append(cmds, cmd)
 		}
-	
+
+	case tea.MouseMsg:
+		return p, p.handleMouse(m)
+
 	case errMsg:
 		// TODO: surface errors to the user (status bar, modal, etc.)
 		_ = m
@@ -95,67 +265,383 @@ func (p *Page) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		p.width, p.height = m.Width, m.Height
 
-	default:
-		p.playbar, cmd = p.playbar.Update(msg)
-		if cmd != nil {
-			cmds = append(cmds, cmd)
-		}
-		p.tracks, cmd = p.tracks.Update(msg)
-		if cmd != nil {
-			cmds = append(cmds, cmd)
+	case spinner.TickMsg:
+		if m.ID == p.starting.ID() {
+			// Only animate until the first frame with a real layout.
+			if p.width == 0 {
+				p.starting, cmd = p.starting.Update(m)
+			}
+			return p, cmd
 		}
-		p.navigation, cmd = p.navigation.Update(msg)
-		if cmd != nil {
+		return p, p.broadcast(msg)
+
+	default:
+		if p.showPalette {
+			cmd, _ = p.palette.Update(msg)
 			cmds = append(cmds, cmd)
 		}
+		cmds = append(cmds, p.broadcast(msg))
 	}
 
 	return p, tea.Batch(cmds...)
 }
 
-func (p *Page) cycleFocus() {
+// broadcast hands a non-key message to every component.
+func (p *Page) broadcast(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+
+	p.sidebar, cmd = p.sidebar.Update(msg)
+	cmds = append(cmds, cmd)
+	p.playbar, cmd = p.playbar.Update(msg)
+	cmds = append(cmds, cmd)
+	p.tracks, cmd = p.tracks.Update(msg)
+	cmds = append(cmds, cmd)
+	p.navigation, cmd = p.navigation.Update(msg)
+	cmds = append(cmds, cmd)
+	cmds = append(cmds, p.nowPlaying.Update(msg))
+	cmds = append(cmds, p.lyrics.Update(msg))
+	cmds = append(cmds, p.stats.Update(msg))
+	cmds = append(cmds, p.importer.Update(msg))
+
+	return tea.Batch(cmds...)
+}
+
+// updateNowPlaying handles keys in the full-screen now-playing view. The
+// playbar's bindings keep working; the rest of the UI is out of reach
+// until the view is closed.
+func (p *Page) updateNowPlaying(m tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(m, p.keys.Global.NowPlaying), m.String() == "esc":
+		p.nowPlaying.Close()
+		return nil
+	case key.Matches(m, p.keys.Global.Quit):
+		return tea.Quit
+	case key.Matches(m, p.keys.Global.CommandPalette):
+		p.showPalette = true
+		return p.palette.Open()
+	case key.Matches(m, p.keys.Global.ToggleShuffle):
+		return p.bus.Publish(MsgToggleShuffle, ToggleShuffleMsg{})
+	}
+	return p.playbar.(*Playbar).handleKey(m)
+}
+
+// updateStats handles keys in the stats dashboard, which keeps them all
+// until it is closed.
+func (p *Page) updateStats(m tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(m, p.keys.Global.Stats), m.String() == "esc":
+		p.stats.Close()
+		return nil
+	case key.Matches(m, p.keys.Global.Quit):
+		return tea.Quit
+	case key.Matches(m, p.keys.Global.CommandPalette):
+		p.showPalette = true
+		return p.palette.Open()
+	}
+	return p.stats.HandleKey(m)
+}
+
+// updateImport handles keys in the import screen. Esc cancels the import,
+// except while tracks are being added, or leaves the playlist picker.
+func (p *Page) updateImport(m tea.KeyMsg) tea.Cmd {
+	switch {
+	case m.String() == "esc" && p.importer.picker == nil && !p.importer.Busy():
+		p.importer.Close()
+		return nil
+	case key.Matches(m, p.keys.Global.Quit) && !p.importer.Busy():
+		return tea.Quit
+	case key.Matches(m, p.keys.Global.CommandPalette):
+		p.showPalette = true
+		return p.palette.Open()
+	}
+	return p.importer.HandleKey(m)
+}
+
+// toggleSidebar hides or shows the sidebar, moving the focus off it when
+// it disappears.
+func (p *Page) toggleSidebar() {
+	p.layout.ShowSidebar = !p.layout.ShowSidebar
+	if !p.layout.ShowSidebar && p.sidebar.Focused() {
+		p.focus(p.tracks)
+	}
+}
+
+func (p *Page) cycleFocus(step int) {
 	components := []Component{p.navigation, p.sidebar, p.tracks, p.playbar}
+	if !p.layout.ShowSidebar {
+		components = []Component{p.navigation, p.tracks, p.playbar}
+	}
 
 	for i, c := range components {
 		if c.Focused() {
 			c.Blur()
-			components[(i+1)%len(components)].Focus()
+			next := (i + step + len(components)) % len(components)
+			components[next].Focus()
 			return
 		}
 	}
 }
 
+func (p *Page) focused() Component {
+	for _, c := range []Component{p.navigation, p.sidebar, p.tracks, p.playbar} {
+		if c.Focused() {
+			return c
+		}
+	}
+	return nil
+}
+
+// helpView renders the bindings for the focused component next to the
+// global ones, straight from the active keymap.
+func (p *Page) helpView(width, height int) string {
+	p.help.Styles.FullKey = lipgloss.NewStyle().Foreground(theme.Accent)
+	p.help.Styles.FullDesc = lipgloss.NewStyle().Foreground(theme.Text)
+	p.help.Styles.FullSeparator = lipgloss.NewStyle().Foreground(theme.Dim)
+	p.help.ShowAll = true
+
+	groups := [][]key.Binding{p.keys.Global.Bindings()}
+	if c := p.focused(); c != nil {
+		groups = append(groups, c.KeyBindings())
+	}
+
+	title := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render("Keyboard shortcuts")
+	hint := lipgloss.NewStyle().Foreground(theme.Muted).Render("press " + p.keys.Global.Help.Help().Key + " or esc to close")
+	body := lipgloss.JoinVertical(lipgloss.Left, title, "", p.help.FullHelpView(groups), "", hint)
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Accent).
+		Padding(1, 2).
+		Render(body)
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// RegisterCommands adds the page-wide commands: navigation, themes and
+// the app itself.
+func (p *Page) RegisterCommands(r *CommandRegistry) {
+	r.Register(
+		Command{Name: "search", Group: "navigation", Description: "search Spotify", Args: "<query>",
+			Run: func(args string) (tea.Cmd, error) {
+				return p.bus.Publish(MsgSearch, SearchResultsMsg{Query: args}), nil
+			}},
+		Command{Name: "back", Group: "navigation", Description: "go back",
+			Run: noArgs(func() tea.Cmd { return p.bus.Publish(MsgNavigateBack, NavigateMsg{}) })},
+		Command{Name: "forward", Group: "navigation", Description: "go forward",
+			Run: noArgs(func() tea.Cmd { return p.bus.Publish(MsgNavigateForward, NavigateMsg{}) })},
+		Command{Name: "queue", Group: "navigation", Description: "show or hide the queue",
+			Run: noArgs(func() tea.Cmd { return p.bus.Publish(MsgToggleQueue, ToggleQueueMsg{}) })},
+		Command{Name: "history", Group: "navigation", Description: "show or hide what you listened to recently",
+			Run: noArgs(func() tea.Cmd { return p.bus.Publish(MsgToggleHistory, ToggleHistoryMsg{}) })},
+		Command{Name: "focus", Group: "navigation", Description: "focus a panel", Args: "<panel>",
+			Complete: func() []string { return []string{"sidebar", "tracks", "playbar", "search"} },
+			Run: func(args string) (tea.Cmd, error) {
+				name, err := oneOf(args, "sidebar", "tracks", "playbar", "search")
+				if err != nil {
+					return nil, err
+				}
+				p.focus(map[string]Component{"sidebar": p.sidebar, "tracks": p.tracks, "playbar": p.playbar, "search": p.navigation}[name])
+				if name == "search" {
+					return p.bus.Publish(MsgFocusSearch, FocusSearchMsg{}), nil
+				}
+				return nil, nil
+			}},
+		Command{Name: "now-playing", Group: "navigation", Description: "show or hide the full-screen now playing view",
+			Run: noArgs(func() tea.Cmd {
+				if p.nowPlaying.Opened() {
+					p.nowPlaying.Close()
+					return nil
+				}
+				return p.nowPlaying.Open()
+			})},
+		Command{Name: "lyrics", Group: "navigation", Description: "show or hide the lyrics pane",
+			Run: noArgs(p.lyrics.Toggle)},
+		Command{Name: "stats", Group: "navigation", Description: "show or hide listening stats",
+			Run: noArgs(func() tea.Cmd {
+				if p.stats.Opened() {
+					p.stats.Close()
+					return nil
+				}
+				return p.stats.Open()
+			})},
+		Command{Name: "export-stats", Group: "navigation", Description: "save the stats on screen as JSON, or CSV for a .csv file", Args: "<file>",
+			Run: func(args string) (tea.Cmd, error) {
+				if args == "" {
+					return nil, errors.New("give a file to save to")
+				}
+				return nil, p.stats.Export(args)
+			}},
+		Command{Name: "import-playlist", Group: "playlists", Description: "import a CSV, JSON, M3U or XSPF playlist file", Args: "<file>",
+			Run: func(args string) (tea.Cmd, error) {
+				if args == "" {
+					return nil, errors.New("give a playlist file to import")
+				}
+				return p.importer.Open(args), nil
+			}},
+		Command{Name: "shuffle", Group: "playback", Description: "toggle shuffle",
+			Run: noArgs(func() tea.Cmd { return p.bus.Publish(MsgToggleShuffle, ToggleShuffleMsg{}) })},
+		Command{Name: "theme", Group: "appearance", Description: "switch theme", Args: "<name>",
+			Complete: p.themes.Names,
+			Run: func(args string) (tea.Cmd, error) {
+				name, err := bestMatch(args, p.themes.Names())
+				if err != nil {
+					return nil, err
+				}
+				theme, _ = p.themes.Get(name)
+				return nil, nil
+			}},
+		Command{Name: "help", Group: "app", Description: "show key bindings",
+			Run: noArgs(func() tea.Cmd {
+				p.showHelp = true
+				return nil
+			})},
+		Command{Name: "quit", Group: "app", Description: "quit spotify-tui",
+			Run: noArgs(func() tea.Cmd { return tea.Quit })},
+	)
+}
+
 func (p *Page) focusNav() {
-	p.navigation.Focus()
-	p.sidebar.Blur()
-	p.tracks.Blur()
-	p.playbar.Blur()
+	p.focus(p.navigation)
+}
+
+// focus moves the focus to c.
+func (p *Page) focus(c Component) {
+	for _, other := range []Component{p.navigation, p.sidebar, p.tracks, p.playbar} {
+		if other != c {
+			other.Blur()
+		}
+	}
+	c.Focus()
+}
+
+// handleMouse focuses the panel under a click or wheel event and hands the
+// event over in the panel's own coordinates. A click closes the help and
+// command palette overlays.
+func (p *Page) handleMouse(m tea.MouseMsg) tea.Cmd {
+	if m.Action != tea.MouseActionPress {
+		return nil
+	}
+	if p.showPalette || p.showHelp {
+		if m.Button == tea.MouseButtonLeft {
+			p.showPalette, p.showHelp = false, false
+		}
+		return nil
+	}
+
+	for _, r := range p.regions {
+		if !r.contains(m.X, m.Y) {
+			continue
+		}
+		if r.c == p.navigation {
+			if m.Button != tea.MouseButtonLeft {
+				return nil
+			}
+			p.focusNav()
+			return p.bus.Publish(MsgFocusSearch, FocusSearchMsg{})
+		}
+		if !r.c.Focused() {
+			p.focus(r.c)
+		}
+		_, cmd := r.c.Update(r.local(m))
+		return cmd
+	}
+	return nil
 }
 
 func (p *Page) View() string {
 	if p.width == 0 || p.height == 0 {
-		return "loading..."
+		return p.starting.View() + " Starting spotify-tui…"
 	}
 
-	height := p.height - 1
-	width := p.width - 5
-
-	logoLines := len(strings.Split(strings.Trim(assets.SpotifyLogo, "\n"), "\n"))
-	navHeight := logoLines + 2
-	const playbarHeight = 3
+	l := computeLayout(p.width, p.height, layoutOptions{
+		sidebarRatio:   p.layout.SidebarRatio,
+		showSidebar:    p.layout.ShowSidebar,
+		showLogo:       p.layout.ShowLogo,
+		logoHeight:     len(strings.Split(strings.Trim(assets.SpotifyLogo, "\n"), "\n")),
+		nowPlayingPane: p.layout.NowPlayingPane,
+		lyrics:         p.lyrics.Opened(),
+		sidebarFocused: p.sidebar.Focused(),
+	})
+	p.mode = l.mode
+	p.regions = p.regions[:0]
+	if p.nowPlaying.Opened() {
+		if p.showPalette {
+			return lipgloss.Place(p.width, p.height, lipgloss.Center, lipgloss.Top, "\n"+p.palette.View(min(72, max(p.width-4, 0))))
+		}
+		return fit(p.nowPlaying.FullView(p.width, p.height, p.keys.Global.NowPlaying.Help().Key), rect{w: p.width, h: p.height})
+	}
+	if p.stats.Opened() {
+		if p.showPalette {
+			return lipgloss.Place(p.width, p.height, lipgloss.Center, lipgloss.Top, "\n"+p.palette.View(min(72, max(p.width-4, 0))))
+		}
+		return fit(p.stats.FullView(p.width, p.height, p.keys.Global.Stats.Help().Key), rect{w: p.width, h: p.height})
+	}
+	if p.importer.Opened() {
+		if p.showPalette {
+			return lipgloss.Place(p.width, p.height, lipgloss.Center, lipgloss.Top, "\n"+p.palette.View(min(72, max(p.width-4, 0))))
+		}
+		return fit(p.importer.FullView(p.width, p.height, ""), rect{w: p.width, h: p.height})
+	}
+	if nav, ok := p.navigation.(*Navigation); ok {
+		nav.SetShowLogo(l.showLogo)
+	}
 
-	const sidebarRatio = 0.35
-	sidebarWidth := int(float64(width) * sidebarRatio)
-	tracksWidth := width - sidebarWidth
+	navBar := p.pane(p.navigation, l.nav)
+	var columns []string
+	for _, c := range []struct {
+		Component
+		rect
+	}{{p.sidebar, l.sidebar}, {p.tracks, l.tracks}} {
+		if c.visible() {
+			columns = append(columns, p.pane(c.Component, c.rect))
+		}
+	}
+	if l.nowPlaying.visible() {
+		w, h := l.nowPlaying.inner()
+		columns = append(columns, fit(p.nowPlaying.View(w, h), l.nowPlaying))
+	}
+	if l.lyrics.visible() {
+		w, h := l.lyrics.inner()
+		columns = append(columns, fit(p.lyrics.View(w, h), l.lyrics))
+	}
+	contentRow := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
 
-	navBar := p.navigation.View(width+2, navHeight)
-	contentHeight := height - navHeight - playbarHeight - 5
+	contentHeight := lipgloss.Height(contentRow)
+	if len(columns) == 0 {
+		contentHeight = 0
+	}
+	switch {
+	case contentHeight == 0:
+	case p.showPalette:
+		box := p.palette.View(min(72, max(l.nav.w-4, 0)))
+		contentRow = fit(lipgloss.Place(l.nav.w, contentHeight, lipgloss.Center, lipgloss.Top, "\n"+box), rect{w: l.nav.w, h: contentHeight})
+	case p.showHelp:
+		contentRow = fit(p.helpView(l.nav.w, contentHeight), rect{w: l.nav.w, h: contentHeight})
+	}
 
-	sidebarView := p.sidebar.View(sidebarWidth, contentHeight)
-	tracksView := p.tracks.View(tracksWidth, contentHeight)
+	rows := []string{navBar}
+	if len(columns) > 0 {
+		rows = append(rows, contentRow)
+	}
+	if l.playbar.visible() {
+		rows = append(rows, p.pane(p.playbar, l.playbar))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
 
-	contentRow := lipgloss.JoinHorizontal(lipgloss.Top, sidebarView, tracksView)
-	playbarView := p.playbar.View(width+2, playbarHeight)
+// pane renders c into r and records where it went for mouse events.
+func (p *Page) pane(c Component, r rect) string {
+	if !r.visible() {
+		return ""
+	}
+	p.regions = append(p.regions, componentRegion{c, region{r.x, r.y, r.w, r.h}})
+	w, h := r.inner()
+	return fit(c.View(w, h), r)
+}
 
-	return lipgloss.JoinVertical(lipgloss.Left, navBar, contentRow, playbarView)
-}
\ No newline at end of file
+// fit crops a rendered pane that overflowed r, which only happens in
+// terminals too small for its content.
+func fit(view string, r rect) string {
+	return lipgloss.NewStyle().MaxWidth(r.w).MaxHeight(r.h).Render(view)
+}
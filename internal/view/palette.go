@@ -0,0 +1,253 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	maxPaletteRows   = 10
+	maxRecentCommand = 10
+)
+
+// paletteRow is one suggestion: a command, one of its argument values or a
+// recently run line.
+type paletteRow struct {
+	line    string // what the row completes the input to
+	label   string
+	hint    string
+	matches []int // rune positions of label to highlight
+}
+
+// palette is the command palette overlay. The first word of the input is
+// fuzzy-matched against command names; after a space it completes the
+// command's argument instead.
+type palette struct {
+	registry *CommandRegistry
+	input    textinput.Model
+	rows     []paletteRow
+	cursor   int
+	recent   []string // most recent first
+	err      error
+	keys     paletteKeyMap
+}
+
+func newPalette(registry *CommandRegistry, keys paletteKeyMap) *palette {
+	ti := textinput.New()
+	ti.Prompt = ": "
+	ti.Placeholder = "Type a command…"
+	ti.CharLimit = 200
+
+	return &palette{registry: registry, input: ti, keys: keys}
+}
+
+// Open resets the palette for a new command.
+func (p *palette) Open() tea.Cmd {
+	p.input.SetValue("")
+	p.err = nil
+	p.refresh()
+	p.input.Focus()
+	return textinput.Blink
+}
+
+// Update handles a key. done reports that the palette should close, either
+// because a command ran or because it was dismissed.
+func (p *palette) Update(msg tea.Msg) (cmd tea.Cmd, done bool) {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		p.input, cmd = p.input.Update(msg)
+		return cmd, false
+	}
+
+	switch {
+	case key.Matches(km, p.keys.Close):
+		return nil, true
+
+	case key.Matches(km, p.keys.Up):
+		p.cursor = max(p.cursor-1, 0)
+		return nil, false
+
+	case key.Matches(km, p.keys.Down):
+		p.cursor = min(p.cursor+1, max(len(p.rows)-1, 0))
+		return nil, false
+
+	case key.Matches(km, p.keys.Complete):
+		p.complete()
+		return nil, false
+
+	case key.Matches(km, p.keys.Run):
+		return p.run()
+	}
+
+	p.input, cmd = p.input.Update(msg)
+	p.err = nil
+	p.refresh()
+	return cmd, false
+}
+
+// complete fills the input with the highlighted suggestion.
+func (p *palette) complete() {
+	if p.cursor >= len(p.rows) {
+		return
+	}
+	p.input.SetValue(p.rows[p.cursor].line)
+	p.input.CursorEnd()
+	p.refresh()
+}
+
+// run executes the typed line, or the highlighted suggestion when only part
+// of a command name or a completable argument was typed. A command still
+// missing its argument is completed instead so the argument can be typed.
+func (p *palette) run() (tea.Cmd, bool) {
+	line := strings.TrimSpace(p.input.Value())
+	name, _, hasArgs := strings.Cut(line, " ")
+	c, exact := p.registry.Lookup(name)
+	partial := (!exact && !hasArgs) || (exact && hasArgs && c.Complete != nil)
+	if partial && p.cursor < len(p.rows) {
+		line = strings.TrimSpace(p.rows[p.cursor].line)
+	}
+	if line == "" {
+		return nil, false
+	}
+
+	name, args, _ := strings.Cut(line, " ")
+	if c, ok := p.registry.Lookup(name); ok && c.Args != "" && strings.TrimSpace(args) == "" {
+		p.input.SetValue(name + " ")
+		p.input.CursorEnd()
+		p.refresh()
+		return nil, false
+	}
+
+	cmd, err := p.registry.Run(line)
+	if err != nil {
+		p.err = err
+		return nil, false
+	}
+	p.remember(line)
+	return cmd, true
+}
+
+func (p *palette) remember(line string) {
+	recent := []string{line}
+	for _, r := range p.recent {
+		if r != line && len(recent) < maxRecentCommand {
+			recent = append(recent, r)
+		}
+	}
+	p.recent = recent
+}
+
+// refresh recomputes the suggestions for the current input.
+func (p *palette) refresh() {
+	value := p.input.Value()
+	name, arg, hasArgs := strings.Cut(value, " ")
+	p.cursor = 0
+
+	if c, ok := p.registry.Lookup(name); ok && hasArgs {
+		p.rows = argumentRows(c, strings.TrimSpace(arg))
+		return
+	}
+
+	var rows []paletteRow
+	if value == "" {
+		for _, r := range p.recent {
+			rows = append(rows, paletteRow{line: r, label: r, hint: "recent"})
+		}
+	}
+
+	commands := p.registry.Commands()
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.Name
+	}
+	if value == "" {
+		for _, c := range commands {
+			rows = append(rows, commandRow(c, nil))
+		}
+	} else {
+		for _, rank := range list.DefaultFilter(name, names) {
+			rows = append(rows, commandRow(commands[rank.Index], runeIndexes(names[rank.Index], rank.MatchedIndexes)))
+		}
+	}
+	p.rows = rows
+}
+
+func commandRow(c Command, matches []int) paletteRow {
+	line := c.Name
+	label := c.Name
+	if c.Args != "" {
+		line += " "
+		label += " " + c.Args
+	}
+	hint := c.Description
+	if c.Group != "" {
+		hint = c.Group + " · " + hint
+	}
+	return paletteRow{line: line, label: label, hint: hint, matches: matches}
+}
+
+// argumentRows suggests values for a command's argument, or shows its
+// usage when it has no completions.
+func argumentRows(c Command, arg string) []paletteRow {
+	if c.Complete == nil {
+		return []paletteRow{{line: c.Name + " " + arg, label: c.Name + " " + c.Args, hint: c.Description}}
+	}
+
+	values := c.Complete()
+	var rows []paletteRow
+	if arg == "" {
+		for _, v := range values {
+			rows = append(rows, paletteRow{line: c.Name + " " + v, label: v})
+		}
+		return rows
+	}
+	for _, rank := range list.DefaultFilter(arg, values) {
+		v := values[rank.Index]
+		rows = append(rows, paletteRow{line: c.Name + " " + v, label: v, matches: runeIndexes(v, rank.MatchedIndexes)})
+	}
+	return rows
+}
+
+func (p *palette) View(width int) string {
+	p.input.PromptStyle = lipgloss.NewStyle().Foreground(theme.Accent)
+	p.input.TextStyle = lipgloss.NewStyle().Foreground(theme.Text)
+	p.input.Cursor.Style = lipgloss.NewStyle().Foreground(theme.Accent)
+	p.input.Width = max(width-8, 10)
+
+	lines := []string{p.input.View(), ""}
+
+	// Keep the highlighted row in view.
+	start := max(p.cursor-maxPaletteRows+1, 0)
+	end := min(start+maxPaletteRows, len(p.rows))
+	for i := start; i < end; i++ {
+		r := p.rows[i]
+		style := lipgloss.NewStyle().Foreground(theme.Text)
+		marker := "  "
+		if i == p.cursor {
+			style = style.Foreground(theme.Accent).Bold(true)
+			marker = "> "
+		}
+		label := highlight(r.label, r.matches, style)
+		hint := lipgloss.NewStyle().Foreground(theme.Muted).Render(r.hint)
+		lines = append(lines, marker+label+"  "+hint)
+	}
+	if len(p.rows) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(theme.Muted).Render("  No matching commands"))
+	}
+	if p.err != nil {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(theme.Accent).Render(p.err.Error()))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Accent).
+		Padding(0, 1).
+		Width(width).
+		MaxHeight(maxPaletteRows + 8).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,62 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+)
+
+// playlistPicker asks which playlist to add tracks to. It reuses the
+// sidebar's rendering so playlists look the same everywhere.
+type playlistPicker struct {
+	list list.Model
+	uris []string
+}
+
+func newPlaylistPicker(playlists []entities.Playlist, uris []string, listKeys listKeyMap) *playlistPicker {
+	l := list.New(sidebarItems(playlists), sidebarDelegate{list.NewDefaultDelegate()}, 0, 0)
+	l.Title = fmt.Sprintf("Add %d %s to…", len(uris), plural(len(uris), "track"))
+	l.SetShowHelp(false)
+	l.SetShowStatusBar(false)
+	l.KeyMap = listKeys.listModelKeyMap()
+
+	return &playlistPicker{list: l, uris: uris}
+}
+
+// SetPlaylists fills in the playlists once they have been fetched.
+func (p *playlistPicker) SetPlaylists(playlists []entities.Playlist) tea.Cmd {
+	return p.list.SetItems(sidebarItems(playlists))
+}
+
+func (p *playlistPicker) Selected() (sidebarItem, bool) {
+	item, ok := p.list.SelectedItem().(sidebarItem)
+	return item, ok
+}
+
+func (p *playlistPicker) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	p.list, cmd = p.list.Update(msg)
+	return cmd
+}
+
+func (p *playlistPicker) View(width, height int) string {
+	p.list.SetSize(width, height)
+	p.list.Styles.Title = listTitleStyle()
+	styleFilterInput(&p.list)
+
+	if len(p.list.Items()) == 0 {
+		title := p.list.Styles.TitleBar.Render(p.list.Styles.Title.Render(p.list.Title))
+		return lipgloss.JoinVertical(lipgloss.Left, title, emptyState("Loading playlists…", width))
+	}
+	return p.list.View()
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
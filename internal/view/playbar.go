@@ -2,12 +2,16 @@ package view
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/thomassbooth/spotify-tui/internal/art"
+	"github.com/thomassbooth/spotify-tui/internal/config"
 	"github.com/thomassbooth/spotify-tui/internal/entities"
 	"github.com/thomassbooth/spotify-tui/internal/service"
 )
@@ -18,23 +22,61 @@ type playbarSyncMsg struct {
 }
 type syncPollMsg struct {
 	state *entities.PlaybackState
+	err   error
+	// pushed is set for states streamed by a daemon, which only sends
+	// changes, so they are taken even when only the position moved.
+	pushed bool
+}
+
+// playbackStream is implemented by playback backed by a daemon, which
+// pushes changes instead of being polled.
+type playbackStream interface {
+	WatchPlayback() <-chan service.PlaybackUpdate
+}
+
+type playbackFailedMsg struct {
+	err error
+}
+
+// nothingPlayingMsg ends the initial load when no device is playing;
+// streamed playback only arrives once something changes.
+type nothingPlayingMsg struct{}
+
+type devicesLoadedMsg struct {
+	devices []entities.Device
 }
 
 type Playbar struct {
 	bus             *MessageBus
-	playbackService *service.PlaybackService
+	playbackService service.Playback
 	playbackState   *entities.PlaybackState
 	focused         bool
 	width           int
 	elapsedMs       int
 	mu              sync.Mutex
 	ticking         bool
+	pollInterval    time.Duration
+	volumeStep      int
+	keys            playbarKeyMap
+	art             *coverArt
+	loader          loader
+	devices         []entities.Device             // for completing the device command
+	updates         <-chan service.PlaybackUpdate // from the daemon, when attached
+	// Clickable parts of the last rendered view, relative to the playbar.
+	progressSpan span
+	shuffleSpan  span
+	repeatSpan   span
 }
 
-func NewPlaybar(bus *MessageBus, playbackService *service.PlaybackService) *Playbar {
+func NewPlaybar(bus *MessageBus, playbackService service.Playback, polling config.PollingConfig, keys playbarKeyMap, art *coverArt) *Playbar {
 	p := &Playbar{
 		bus:             bus,
 		playbackService: playbackService,
+		pollInterval:    polling.PlaybackInterval.Duration,
+		volumeStep:      polling.VolumeStep,
+		keys:            keys,
+		art:             art,
+		loader:          newLoader(),
 	}
 
 	bus.Subscribe(MsgPlaybackUpdate, p)
@@ -45,23 +87,72 @@ func NewPlaybar(bus *MessageBus, playbackService *service.PlaybackService) *Play
 }
 
 func (p *Playbar) Init() tea.Cmd {
-	return tea.Batch(p.fetchPlayback(), startSyncPoll(p.playbackService))
+	return tea.Batch(p.loader.Start(), p.fetchPlayback(), p.nextSync(), p.fetchDevices())
+}
+
+// nextSync waits for the next playback update: from the daemon when
+// attached to one, otherwise by polling Spotify.
+func (p *Playbar) nextSync() tea.Cmd {
+	stream, ok := p.playbackService.(playbackStream)
+	if !ok {
+		return startSyncPoll(p.playbackService, p.pollInterval)
+	}
+	if p.updates == nil {
+		p.updates = stream.WatchPlayback()
+	}
+	updates := p.updates
+	return func() tea.Msg {
+		u, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return syncPollMsg{state: u.State, err: u.Err, pushed: true}
+	}
+}
+
+// fetchDevices refreshes the device names offered by the device command.
+// Failures are ignored; the command fetches devices again when run.
+func (p *Playbar) fetchDevices() tea.Cmd {
+	return func() tea.Msg {
+		devices, err := p.playbackService.Devices()
+		if err != nil {
+			return nil
+		}
+		return devicesLoadedMsg{devices: devices}
+	}
 }
 
 func (p *Playbar) Update(msg tea.Msg) (Component, tea.Cmd) {
+	c, cmd := p.update(msg)
+	return c, tea.Batch(cmd, p.art.Load(p.coverURL()))
+}
+
+func (p *Playbar) update(msg tea.Msg) (Component, tea.Cmd) {
+	if cmd := p.loader.Update(msg); cmd != nil {
+		return p, cmd
+	}
+
 	switch m := msg.(type) {
+	case playbackFailedMsg:
+		p.loader.Done(m.err)
+		return p, nil
+
+	case nothingPlayingMsg:
+		p.loader.Done(nil)
+		return p, nil
+
+	case devicesLoadedMsg:
+		p.devices = m.devices
+		return p, nil
+
+	case tea.MouseMsg:
+		return p, p.handleMouse(m)
+
 	case tea.KeyMsg:
 		if !p.focused {
 			return p, nil
 		}
-		switch m.String() {
-		case " ", "enter":
-			return p, p.togglePlayCmd()
-		case "n", "l", "right":
-			return p, p.nextCmd()
-		case "p", "h", "left":
-			return p, p.previousCmd()
-		}
+		return p, p.handleKey(m)
 
 	case playbarTickMsg:
 		p.mu.Lock()
@@ -83,18 +174,22 @@ func (p *Playbar) Update(msg tea.Msg) (Component, tea.Cmd) {
 
 	case syncPollMsg:
 		var cmds []tea.Cmd
-		cmds = append(cmds, startSyncPoll(p.playbackService))
+		cmds = append(cmds, p.nextSync())
 
 		if m.state == nil {
+			p.loader.Done(m.err)
 			return p, tea.Batch(cmds...)
 		}
+		p.loader.Done(nil)
 
 		p.mu.Lock()
 		current := p.playbackState
-		changed := current == nil ||
+		changed := m.pushed || current == nil ||
 			current.Track.ID != m.state.Track.ID ||
 			current.IsPlaying != m.state.IsPlaying ||
-			current.ShuffleState != m.state.ShuffleState
+			current.ShuffleState != m.state.ShuffleState ||
+			current.RepeatState != m.state.RepeatState ||
+			current.Device != m.state.Device
 		if changed {
 			p.playbackState = m.state
 			p.elapsedMs = m.state.ProgressMs
@@ -108,6 +203,7 @@ func (p *Playbar) Update(msg tea.Msg) (Component, tea.Cmd) {
 		return p, tea.Batch(cmds...)
 
 	case playbarSyncMsg:
+		p.loader.Done(nil)
 		p.mu.Lock()
 		p.playbackState = &m.state
 		p.elapsedMs = m.state.ProgressMs
@@ -119,6 +215,7 @@ func (p *Playbar) Update(msg tea.Msg) (Component, tea.Cmd) {
 		return p, nil
 
 	case entities.PlaybackState:
+		p.loader.Done(nil)
 		p.mu.Lock()
 		p.playbackState = &m
 		p.elapsedMs = m.ProgressMs
@@ -133,6 +230,23 @@ func (p *Playbar) Update(msg tea.Msg) (Component, tea.Cmd) {
 	return p, nil
 }
 
+// current returns the playback state and the locally ticked position.
+func (p *Playbar) current() (*entities.PlaybackState, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.playbackState, p.elapsedMs
+}
+
+// coverURL returns the small album image of the current track.
+func (p *Playbar) coverURL() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.playbackState == nil {
+		return ""
+	}
+	return art.PickImage(p.playbackState.Track.Album.Images, 64)
+}
+
 func (p *Playbar) tickCmd() tea.Cmd {
 	return tea.Tick(1*time.Second, func(time.Time) tea.Msg {
 		return playbarTickMsg{}
@@ -142,8 +256,11 @@ func (p *Playbar) tickCmd() tea.Cmd {
 func (p *Playbar) fetchPlayback() tea.Cmd {
 	return func() tea.Msg {
 		state, err := p.playbackService.GetCurrentPlaybackState()
-		if err != nil || state == nil {
-			return playbarTickMsg{}
+		if err != nil {
+			return playbackFailedMsg{err: err}
+		}
+		if state == nil {
+			return nothingPlayingMsg{}
 		}
 		return *state
 	}
@@ -213,6 +330,35 @@ func (p *Playbar) previousCmd() tea.Cmd {
 	}
 }
 
+func (p *Playbar) volumeCmd(delta int) tea.Cmd {
+	return func() tea.Msg {
+		p.mu.Lock()
+		current := 0
+		if p.playbackState != nil {
+			current = p.playbackState.Device.VolumePercent
+		}
+		p.mu.Unlock()
+
+		var err error
+		if delta > 0 {
+			err = p.playbackService.VolumeUp(current, delta)
+		} else {
+			err = p.playbackService.VolumeDown(current, -delta)
+		}
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		state, err := p.playbackService.GetCurrentPlaybackState()
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		if state != nil {
+			return playbarSyncMsg{state: *state}
+		}
+		return nil
+	}
+}
+
 func (p *Playbar) OnMessage(t MsgType, msg tea.Msg) tea.Cmd {
 	if t == MsgPlayTrack {
 		if playTrackMsg, ok := msg.(PlayTrackMsg); ok {
@@ -236,7 +382,7 @@ func (p *Playbar) OnMessage(t MsgType, msg tea.Msg) tea.Cmd {
 			p.mu.Lock()
 			currentShuffle := p.playbackState != nil && p.playbackState.ShuffleState
 			p.mu.Unlock()
-			
+
 			err := p.playbackService.ToggleShufflePlayback(!currentShuffle)
 			if err != nil {
 				return errMsg{Err: err}
@@ -256,6 +402,171 @@ func (p *Playbar) OnMessage(t MsgType, msg tea.Msg) tea.Cmd {
 	return nil
 }
 
+// handleKey runs the playback binding matching m. The full-screen
+// now-playing view uses it too, without focusing the playbar.
+func (p *Playbar) handleKey(m tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(m, p.keys.PlayPause):
+		return p.togglePlayCmd()
+	case key.Matches(m, p.keys.Next):
+		return p.nextCmd()
+	case key.Matches(m, p.keys.Previous):
+		return p.previousCmd()
+	case key.Matches(m, p.keys.VolumeUp):
+		return p.volumeCmd(p.volumeStep)
+	case key.Matches(m, p.keys.VolumeDown):
+		return p.volumeCmd(-p.volumeStep)
+	}
+	return nil
+}
+
+// handleMouse seeks on a click in the progress bar, toggles shuffle and
+// cycles the repeat mode on a click on their indicators and changes the
+// volume with the wheel. m is relative to the playbar.
+func (p *Playbar) handleMouse(m tea.MouseMsg) tea.Cmd {
+	switch m.Button {
+	case tea.MouseButtonWheelUp:
+		return p.volumeCmd(p.volumeStep)
+	case tea.MouseButtonWheelDown:
+		return p.volumeCmd(-p.volumeStep)
+	case tea.MouseButtonLeft:
+	default:
+		return nil
+	}
+
+	p.mu.Lock()
+	state := p.playbackState
+	p.mu.Unlock()
+	if state == nil || state.Track.ID == "" {
+		return nil
+	}
+
+	switch {
+	case p.progressSpan.contains(m.X, m.Y):
+		fraction := float64(m.X-p.progressSpan.x) / float64(max(p.progressSpan.w-1, 1))
+		positionMs := int(fraction * float64(state.Track.DurationMs))
+		p.mu.Lock()
+		p.elapsedMs = positionMs
+		p.mu.Unlock()
+		return p.syncAfter(func() error { return p.playbackService.SeekTo(positionMs) })
+
+	case p.shuffleSpan.contains(m.X, m.Y):
+		return p.bus.Publish(MsgToggleShuffle, ToggleShuffleMsg{})
+
+	case p.repeatSpan.contains(m.X, m.Y):
+		next := nextRepeatMode(state.RepeatState)
+		return p.syncAfter(func() error { return p.playbackService.ToggleRepeatPlayback(next) })
+	}
+	return nil
+}
+
+// nextRepeatMode cycles off → context → track → off.
+func nextRepeatMode(mode string) string {
+	switch mode {
+	case "off":
+		return "context"
+	case "context":
+		return "track"
+	}
+	return "off"
+}
+
+// syncAfter runs a playback action in the background, then syncs the
+// playbar with the resulting state.
+func (p *Playbar) syncAfter(action func() error) tea.Cmd {
+	return func() tea.Msg {
+		if err := action(); err != nil {
+			return errMsg{Err: err}
+		}
+		time.Sleep(300 * time.Millisecond)
+		state, err := p.playbackService.GetCurrentPlaybackState()
+		if err != nil {
+			return errMsg{Err: err}
+		}
+		if state != nil {
+			return playbarSyncMsg{state: *state}
+		}
+		return nil
+	}
+}
+
+func (p *Playbar) RegisterCommands(r *CommandRegistry) {
+	r.Register(
+		Command{Name: "play-pause", Group: "playback", Description: "play or pause",
+			Run: noArgs(p.togglePlayCmd)},
+		Command{Name: "next", Group: "playback", Description: "skip to the next track",
+			Run: noArgs(p.nextCmd)},
+		Command{Name: "previous", Group: "playback", Description: "go to the previous track",
+			Run: noArgs(p.previousCmd)},
+		Command{Name: "volume", Group: "playback", Description: "set the volume", Args: "<0-100>",
+			Run: func(args string) (tea.Cmd, error) {
+				percent, err := strconv.Atoi(strings.TrimSuffix(args, "%"))
+				if err != nil || percent < 0 || percent > 100 {
+					return nil, fmt.Errorf("volume must be between 0 and 100, not %q", args)
+				}
+				return p.syncAfter(func() error { return p.playbackService.SetVolume(percent) }), nil
+			}},
+		Command{Name: "volume-up", Group: "playback", Description: "raise the volume",
+			Run: noArgs(func() tea.Cmd { return p.volumeCmd(p.volumeStep) })},
+		Command{Name: "volume-down", Group: "playback", Description: "lower the volume",
+			Run: noArgs(func() tea.Cmd { return p.volumeCmd(-p.volumeStep) })},
+		Command{Name: "seek", Group: "playback", Description: "jump to a position", Args: "<m:ss>",
+			Run: func(args string) (tea.Cmd, error) {
+				pos, err := parsePosition(args)
+				if err != nil {
+					return nil, err
+				}
+				return p.syncAfter(func() error { return p.playbackService.SeekTo(int(pos.Milliseconds())) }), nil
+			}},
+		Command{Name: "repeat", Group: "playback", Description: "set the repeat mode", Args: "<off|context|track>",
+			Complete: func() []string { return []string{"off", "context", "track"} },
+			Run: func(args string) (tea.Cmd, error) {
+				mode, err := oneOf(args, "off", "context", "track")
+				if err != nil {
+					return nil, err
+				}
+				return p.syncAfter(func() error { return p.playbackService.ToggleRepeatPlayback(mode) }), nil
+			}},
+		Command{Name: "device", Group: "devices", Description: "move playback to a device", Args: "<name>",
+			Complete: func() []string {
+				names := make([]string, len(p.devices))
+				for i, d := range p.devices {
+					names[i] = d.Name
+				}
+				return names
+			},
+			Run: func(args string) (tea.Cmd, error) {
+				return tea.Batch(p.syncAfter(func() error { return p.transferTo(args) }), p.fetchDevices()), nil
+			}},
+	)
+}
+
+// transferTo moves playback to the device whose name best matches name.
+func (p *Playbar) transferTo(name string) error {
+	devices, err := p.playbackService.Devices()
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(devices))
+	for i, d := range devices {
+		names[i] = d.Name
+	}
+	match, err := bestMatch(name, names)
+	if err != nil {
+		return err
+	}
+	for _, d := range devices {
+		if d.Name == match {
+			return p.playbackService.TransferPlayback(d.ID)
+		}
+	}
+	return nil
+}
+
+func (p *Playbar) KeyBindings() []key.Binding {
+	return p.keys.Bindings()
+}
+
 func (p *Playbar) Blur() {
 	p.focused = false
 }
@@ -277,10 +588,18 @@ func (p *Playbar) View(width, height int) string {
 	p.mu.Unlock()
 
 	if state == nil || state.Track.ID == "" {
-		return borderStyle.Copy().
+		text := "Nothing playing right now"
+		switch {
+		case p.loader.loading && state == nil:
+			text = p.loader.spinner.View() + " Connecting to Spotify…"
+		case p.loader.err != nil:
+			text = lipgloss.NewStyle().Foreground(theme.Muted).Render(
+				fmt.Sprintf("Couldn't reach Spotify (%v), retrying every %s", p.loader.err, p.pollInterval))
+		}
+		return borderStyle().
 			Width(width).
 			Height(height).PaddingLeft(1).
-			Render("Nothing playing right now")
+			Render(text)
 	}
 
 	track := state.Track
@@ -290,31 +609,47 @@ func (p *Playbar) View(width, height int) string {
 	}
 	artistStr := strings.Join(artistNames, ", ")
 
-	song := lipgloss.NewStyle().Foreground(lipgloss.Color("#FAFAFA")).Bold(true).Render(track.Name)
-	artist := lipgloss.NewStyle().Foreground(lipgloss.Color("#b3b3b3")).Render(artistStr)
+	song := lipgloss.NewStyle().Foreground(theme.Text).Bold(true).Render(track.Name)
+	artist := lipgloss.NewStyle().Foreground(theme.Subtext).Render(artistStr)
 
 	progressWidth := width / 3
 	bar := renderProgressBar(elapsed, track.DurationMs, progressWidth)
 	times := fmt.Sprintf("%s / %s", formatDuration(elapsed), formatDuration(track.DurationMs))
 
-	shuffleColor := lipgloss.Color("#535353")
-	shuffleText := "unshuffled"
+	shuffleColor := theme.Dim
 	shuffleBold := false
 	if state.ShuffleState {
-		shuffleColor = lipgloss.Color("#1db954")
-		shuffleText = "shuffled"
+		shuffleColor = theme.Accent
 		shuffleBold = true
 	}
-	shuffle := lipgloss.NewStyle().Bold(shuffleBold).PaddingLeft(1).Foreground(shuffleColor).Render(shuffleText)
+	shuffle := lipgloss.NewStyle().Bold(shuffleBold).Foreground(shuffleColor).Render(shuffleLabel(state.ShuffleState))
+
+	repeatStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	if state.RepeatState == "context" || state.RepeatState == "track" {
+		repeatStyle = lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	}
+	repeat := repeatStyle.Render(repeatLabel(state.RepeatState))
 
-	progress := fmt.Sprintf("%s %s %s", bar, times, shuffle)
+	progress := fmt.Sprintf("%s %s  %s  %s", bar, times, shuffle, repeat)
 
 	content := lipgloss.JoinVertical(lipgloss.Left, song, artist, progress)
 	paddedContent := lipgloss.NewStyle().PaddingLeft(2).Render(content)
+	contentX := 1 + 2 // border and padding
+	if p.art.Enabled() {
+		cover := p.art.View(art.PickImage(track.Album.Images, 64), height*2, height)
+		paddedContent = lipgloss.JoinHorizontal(lipgloss.Top, " ", cover, paddedContent)
+		contentX += 1 + lipgloss.Width(cover)
+	}
 
-	b := borderStyle.Copy().Width(width).Height(height)
+	// The progress line is the third line inside the border.
+	const progressY = 3
+	p.progressSpan = span{y: progressY, x: contentX, w: lipgloss.Width(bar)}
+	p.shuffleSpan = span{y: progressY, x: contentX + lipgloss.Width(bar+" "+times+"  "), w: lipgloss.Width(shuffle)}
+	p.repeatSpan = span{y: progressY, x: p.shuffleSpan.x + p.shuffleSpan.w + 2, w: lipgloss.Width(repeat)}
+
+	b := borderStyle().Width(width).Height(height)
 	if p.focused {
-		b = b.BorderForeground(lipgloss.Color("#1db954"))
+		b = b.BorderForeground(theme.Accent)
 	}
 
 	return b.Render(paddedContent)
@@ -342,12 +677,12 @@ func formatDuration(ms int) string {
 	return fmt.Sprintf("%d:%02d", minutes, seconds)
 }
 
-func startSyncPoll(svc *service.PlaybackService) tea.Cmd {
-	return tea.Tick(5*time.Second, func(time.Time) tea.Msg {
+func startSyncPoll(svc service.Playback, interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
 		state, err := svc.GetCurrentPlaybackState()
 		if err != nil {
-			return syncPollMsg{state: nil}
+			return syncPollMsg{state: nil, err: err}
 		}
 		return syncPollMsg{state: state}
 	})
-}
\ No newline at end of file
+}
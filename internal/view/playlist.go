@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"time"
 
@@ -11,6 +12,9 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/history"
 	"github.com/thomassbooth/spotify-tui/internal/service"
 )
 
@@ -19,18 +23,32 @@ import (
 type playlistItem struct {
 	name       string
 	artists    []string
+	album      string
 	id         string
 	resultType string // "track", "album", "playlist" — populated during search
+
+	// Table columns, only set for playlist and queue tracks.
+	position   int
+	durationMs int
+	addedAt    string
+	popularity int
+	liked      bool
+	contextURI string // what a history entry was played from
 }
 
 func (i playlistItem) Title() string       { return i.name }
-func (i playlistItem) Description() string { return i.id }
-func (i playlistItem) FilterValue() string { return i.name }
+func (i playlistItem) Description() string { return i.artistNames() }
+func (i playlistItem) FilterValue() string {
+	return filterValue(i.name, i.artistNames(), i.album)
+}
+
+func (i playlistItem) artistNames() string { return strings.Join(i.artists, ", ") }
 
 // --- playlistDelegate ---
 
 type playlistDelegate struct {
 	list.DefaultDelegate
+	sel *selection
 }
 
 func (d playlistDelegate) Height() int  { return 2 }
@@ -43,23 +61,29 @@ func (d playlistDelegate) Render(w io.Writer, m list.Model, index int, item list
 	}
 
 	var (
-		title       = i.name
-		artist      = strings.Join(i.artists, ", ")
+		artists     = i.artistNames()
+		matches     = fieldMatches(m, index, i.name, artists, i.album)
 		isSelected  = index == m.Index()
-		s           = lipgloss.NewStyle().Padding(0, 0, 0, 2)
+		s           = lipgloss.NewStyle().Padding(0, 0, 0, 1)
 		selectedStr = " "
+		titleStyle  = lipgloss.NewStyle().Foreground(theme.Text)
+		detailStyle = lipgloss.NewStyle().Foreground(theme.Muted)
 	)
 
 	if isSelected {
 		selectedStr = ">"
-		title = lipgloss.NewStyle().Foreground(lipgloss.Color("#1db954")).Bold(true).Render(title)
-		artist = lipgloss.NewStyle().Foreground(lipgloss.Color("#1db954")).Render(artist)
-	} else {
-		title = lipgloss.NewStyle().Foreground(lipgloss.Color("#FAFAFA")).Render(title)
-		artist = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888")).Render(artist)
+		titleStyle = lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+		detailStyle = lipgloss.NewStyle().Foreground(theme.Accent)
 	}
 
-	fmt.Fprintf(w, s.Render(selectedStr+" "+title+"\n  "+artist))
+	title := highlight(i.name, matches[0], titleStyle)
+	artist := highlight(artists, matches[1], detailStyle)
+	// The album only shows up when the filter matched it.
+	if len(matches[2]) > 0 {
+		artist += detailStyle.Render(" · ") + highlight(i.album, matches[2], detailStyle)
+	}
+
+	fmt.Fprint(w, s.Render(selectionMark(d.sel, m, index, i)+selectedStr+" "+title+"\n   "+artist))
 }
 
 // --- search filter ---
@@ -67,7 +91,7 @@ func (d playlistDelegate) Render(w io.Writer, m list.Model, index int, item list
 type searchFilter int
 
 const (
-	filterAll       searchFilter = iota
+	filterAll searchFilter = iota
 	filterPlaylists
 	filterAlbums
 	filterSongs
@@ -108,23 +132,23 @@ func (s *search) renderFilterTabs() string {
 		switch {
 		case f == s.cursor && f == s.filter:
 			tabs[i] = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#1db954")).
+				Foreground(theme.Accent).
 				Bold(true).
 				Underline(true).
 				Render("[ " + label + " ]")
 		case f == s.cursor:
 			tabs[i] = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FAFAFA")).
+				Foreground(theme.Text).
 				Bold(true).
 				Render("[ " + label + " ]")
 		case f == s.filter:
 			tabs[i] = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#1db954")).
+				Foreground(theme.Accent).
 				Underline(true).
 				Render(label)
 		default:
 			tabs[i] = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#888888")).
+				Foreground(theme.Muted).
 				Render(label)
 		}
 	}
@@ -139,21 +163,47 @@ type PlaylistTracks struct {
 	tracks          list.Model
 	focused         bool
 	bus             *MessageBus
-	playlistService *service.PlaylistService
-	playbackService *service.PlaybackService
+	playlistService service.Playlists
+	playbackService service.Playback
+	listens         *history.Store // nil when the history is turned off
 	showingQueue    bool
+	showingHistory  bool
 	lastPlaylist    PlaylistSelectedMsg
 	search          search
+	listKeys        listKeyMap
+	keys            tracksKeyMap
+	art             *coverArt
+	timeout         time.Duration // of requests the view makes itself
+	loader          loader
+	history         router
+	restore         bool // move the cursor to the saved position on the next load
+	tableView       bool
+	table           *trackTable
+	liked           map[string]bool // Liked Songs state of every track seen so far
+	sel             *selection
+	picker          *playlistPicker // open while choosing a playlist to add to
+	confirm         *confirmation   // pending yes/no question
+	status          string          // result of the last bulk action
+	statusID        int
+	rowsTop         int // line of the first row, for mouse clicks
+	clicks          clickTracker
+}
+
+// confirmation is a yes/no question shown in the title bar before a
+// destructive action runs.
+type confirmation struct {
+	prompt string
+	run    func() tea.Cmd
 }
 
-func NewPlaylistTracks(bus *MessageBus, playlistService *service.PlaylistService, playbackService *service.PlaybackService) *PlaylistTracks {
+func NewPlaylistTracks(bus *MessageBus, playlistService service.Playlists, playbackService service.Playback, listens *history.Store, listKeys listKeyMap, keys tracksKeyMap, art *coverArt, timeout time.Duration, tableView bool) *PlaylistTracks {
 	const defaultWidth = 30
 
-	delegate := playlistDelegate{}
-	l := list.New([]list.Item{}, delegate, defaultWidth, 0)
+	l := list.New([]list.Item{}, playlistDelegate{}, defaultWidth, 0)
 	l.Title = "Playlist Tracks"
 	l.SetShowHelp(false)
 	l.SetShowStatusBar(false)
+	l.KeyMap = listKeys.listModelKeyMap()
 
 	self := &PlaylistTracks{
 		tracks:          l,
@@ -161,11 +211,26 @@ func NewPlaylistTracks(bus *MessageBus, playlistService *service.PlaylistService
 		bus:             bus,
 		playlistService: playlistService,
 		playbackService: playbackService,
+		listens:         listens,
+		listKeys:        listKeys,
+		keys:            keys,
+		art:             art,
+		timeout:         timeout,
+		loader:          newLoader(),
+		history:         newRouter(),
+		tableView:       tableView,
+		table:           &trackTable{},
+		liked:           map[string]bool{},
+		sel:             newSelection(),
 	}
+	self.applyDelegate()
 
 	bus.Subscribe(MsgPlaylistSelected, self)
 	bus.Subscribe(MsgToggleQueue, self)
+	bus.Subscribe(MsgToggleHistory, self)
 	bus.Subscribe(MsgSearch, self)
+	bus.Subscribe(MsgNavigateBack, self)
+	bus.Subscribe(MsgNavigateForward, self)
 	return self
 }
 
@@ -176,74 +241,313 @@ func (s *PlaylistTracks) OnMessage(t MsgType, msg tea.Msg) tea.Cmd {
 		if !ok {
 			return nil
 		}
-		s.lastPlaylist = playlistMsg
-		s.showingQueue = false
-		s.search.active = false
-		s.tracks.Title = playlistMsg.Name
-
-		return func() tea.Msg {
-			tracks, err := s.playlistService.GetPlaylistTracks(playlistMsg.ID)
-			if err != nil {
-				return errMsg{Err: err}
-			}
-			return tracksLoadedMsg{tracks: tracks}
-		}
+		return s.navigate(contentEntry{kind: contentPlaylist, label: playlistMsg.Name, playlist: playlistMsg})
 	}
 
 	if t == MsgToggleQueue {
-		s.showingQueue = !s.showingQueue
-		s.search.active = false
+		// Closing the queue returns to whatever was open before it.
 		if s.showingQueue {
-			s.tracks.Title = "Queued Songs"
-			return func() tea.Msg {
-				ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-				defer cancel()
-				queueTracks, err := s.playbackService.GetQueue(ctx)
-				if err != nil {
-					return errMsg{Err: err}
-				}
-				return queueLoadedMsg{tracks: queueTracks}
-			}
-		} else if s.lastPlaylist.ID != "" {
-			s.tracks.Title = s.lastPlaylist.Name
-			return func() tea.Msg {
-				tracks, err := s.playlistService.GetPlaylistTracks(s.lastPlaylist.ID)
-				if err != nil {
-					return errMsg{Err: err}
-				}
-				return tracksLoadedMsg{tracks: tracks}
-			}
+			return s.back()
+		}
+		return s.navigate(contentEntry{kind: contentQueue, label: "Queue", playlist: s.lastPlaylist})
+	}
+
+	if t == MsgToggleHistory {
+		if s.showingHistory {
+			return s.back()
 		}
+		return s.navigate(contentEntry{kind: contentHistory, label: "History", playlist: s.lastPlaylist})
+	}
+
+	if t == MsgNavigateBack {
+		return s.back()
+	}
+
+	if t == MsgNavigateForward {
+		return s.forward()
 	}
 
 	if t == MsgSearch {
 		if msgSearchQuery, ok := msg.(SearchResultsMsg); ok {
-			s.search.active = true
-			s.search.filter = filterAll
-			s.search.cursor = filterAll
-			s.tracks.Title = fmt.Sprintf("Results: %q", msgSearchQuery.Query)
-
 			items := make([]list.Item, 0, len(msgSearchQuery.Tracks)+len(msgSearchQuery.Albums)+len(msgSearchQuery.Playlists))
 			for _, tr := range msgSearchQuery.Tracks {
 				names := make([]string, len(tr.Artists))
 				for j, a := range tr.Artists {
 					names[j] = a.Name
 				}
-				items = append(items, playlistItem{name: tr.Name, artists: names, id: tr.ID, resultType: "track"})
+				items = append(items, playlistItem{name: tr.Name, artists: names, album: tr.Album.Name, id: tr.ID, resultType: "track", position: len(items)})
 			}
 			for _, al := range msgSearchQuery.Albums {
-				items = append(items, playlistItem{name: al.Name, artists: []string{al.Name}, id: al.ID, resultType: "album"})
+				items = append(items, playlistItem{name: al.Name, artists: []string{al.Name}, id: al.ID, resultType: "album", position: len(items)})
 			}
 			for _, pl := range msgSearchQuery.Playlists {
-				items = append(items, playlistItem{name: pl.Name, id: pl.ID, resultType: "playlist"})
+				items = append(items, playlistItem{name: pl.Name, id: pl.ID, resultType: "playlist", position: len(items)})
 			}
 
-			s.search.allItems = items
-			s.tracks.SetItems(items)
+			return s.navigate(contentEntry{
+				kind:     contentSearch,
+				label:    fmt.Sprintf("Results: %q", msgSearchQuery.Query),
+				playlist: s.lastPlaylist,
+				search:   search{active: true, filter: filterAll, cursor: filterAll, allItems: items},
+			})
+		}
+	}
+
+	return nil
+}
+
+// navigate opens e as a new history entry, remembering where the cursor was
+// on the screen being left.
+func (s *PlaylistTracks) navigate(e contentEntry) tea.Cmd {
+	s.saveEntry()
+	s.history.Push(e)
+	return s.show(e)
+}
+
+func (s *PlaylistTracks) back() tea.Cmd {
+	s.saveEntry()
+	e, ok := s.history.Back()
+	if !ok {
+		return nil
+	}
+	return s.show(e)
+}
+
+func (s *PlaylistTracks) forward() tea.Cmd {
+	s.saveEntry()
+	e, ok := s.history.Forward()
+	if !ok {
+		return nil
+	}
+	return s.show(e)
+}
+
+// saveEntry records the cursor and filter tab of the current screen so
+// coming back to it restores them. The list drops its cursor while the pane
+// is unfocused, so that case keeps the position saved on Blur.
+func (s *PlaylistTracks) saveEntry() {
+	cur := s.history.Current()
+	if cur == nil {
+		return
+	}
+	if i := s.tracks.Index(); i >= 0 {
+		cur.cursor = i
+	}
+	if cur.kind == contentSearch {
+		cur.search = s.search
+	}
+}
+
+// show puts e on screen. Tracks are reloaded (from the cache when possible)
+// and the cursor is restored once they arrive.
+func (s *PlaylistTracks) show(e contentEntry) tea.Cmd {
+	s.lastPlaylist = e.playlist
+	s.showingQueue = e.kind == contentQueue
+	s.showingHistory = e.kind == contentHistory
+	s.table.played = s.showingHistory
+	s.search = e.search
+	s.search.active = e.kind == contentSearch
+	s.tracks.ResetFilter()
+	s.sel.Clear()
+	s.picker = nil
+	s.confirm = nil
+	s.applyDelegate()
+	s.restore = true
+
+	switch e.kind {
+	case contentQueue:
+		return s.loadQueue()
+	case contentHistory:
+		return s.loadHistory()
+	case contentSearch:
+		s.loader.Done(nil)
+		return s.setItems(s.search.filterItems())
+	}
+	return tea.Batch(s.loadPlaylist(e.playlist), s.art.Load(e.playlist.ImageURL))
+}
+
+// setItems replaces the list contents. The first load after show moves the
+// cursor back to where it was on this history entry; later refreshes leave
+// it alone.
+func (s *PlaylistTracks) setItems(items []list.Item) tea.Cmd {
+	cmd := s.tracks.SetItems(s.sortItems(items))
+	if s.restore && s.focused {
+		s.restoreCursor()
+	}
+	s.restore = false
+	return cmd
+}
+
+// trackItems converts tracks to list items, numbered in playlist order.
+func (s *PlaylistTracks) trackItems(tracks []entities.Track) []list.Item {
+	items := make([]list.Item, len(tracks))
+	for i, tr := range tracks {
+		artistNames := make([]string, len(tr.Artists))
+		for j, a := range tr.Artists {
+			artistNames[j] = a.Name
+		}
+		items[i] = playlistItem{
+			name:       tr.Name,
+			artists:    artistNames,
+			album:      tr.Album.Name,
+			id:         tr.ID,
+			position:   i,
+			durationMs: tr.DurationMs,
+			addedAt:    tr.AddedAt,
+			popularity: tr.Popularity,
+			liked:      s.liked[tr.ID],
+		}
+	}
+	return items
+}
+
+// loadLiked looks up the Liked Songs state of tracks not seen before. It is
+// best effort: the column just stays empty if the request fails.
+func (s *PlaylistTracks) loadLiked(tracks []entities.Track) tea.Cmd {
+	var ids []string
+	for _, tr := range tracks {
+		if _, ok := s.liked[tr.ID]; !ok && tr.ID != "" {
+			ids = append(ids, tr.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		liked, err := s.playlistService.SavedTracks(ids)
+		if err != nil {
 			return nil
 		}
+		return likedLoadedMsg{liked: liked}
+	}
+}
+
+// sortItems applies the table sort to playlist and queue tracks; search
+// results keep their relevance order.
+func (s *PlaylistTracks) sortItems(items []list.Item) []list.Item {
+	if s.search.active || !s.tableView {
+		return items
+	}
+	return s.table.Sort(items)
+}
+
+// resort reorders the list after the sort changed, keeping the cursor on
+// the same track.
+func (s *PlaylistTracks) resort() tea.Cmd {
+	selected, _ := s.tracks.SelectedItem().(playlistItem)
+	cmd := s.tracks.SetItems(s.sortItems(s.tracks.Items()))
+	for i, it := range s.tracks.VisibleItems() {
+		if pi, ok := it.(playlistItem); ok && pi.id == selected.id && pi.position == selected.position {
+			s.tracks.Select(i)
+			break
+		}
+	}
+	return cmd
+}
+
+// applyDelegate picks the table or the compact rendering. Search results
+// mix tracks, albums and playlists, so they always use the compact one.
+func (s *PlaylistTracks) applyDelegate() {
+	if s.tableView && !s.search.active {
+		s.tracks.SetDelegate(tableDelegate{table: s.table, sel: s.sel})
+		return
+	}
+	s.tracks.SetDelegate(playlistDelegate{sel: s.sel})
+}
+
+func (s *PlaylistTracks) restoreCursor() {
+	cur := s.history.Current()
+	if cur == nil || len(s.tracks.Items()) == 0 {
+		return
+	}
+	s.tracks.Select(min(cur.cursor, len(s.tracks.Items())-1))
+}
+
+// loadPlaylist shows cached tracks immediately and only goes to the network
+// when the cache is missing or older than the playlist's snapshot.
+func (s *PlaylistTracks) loadPlaylist(pl PlaylistSelectedMsg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	cached, fresh, ok := s.playlistService.CachedPlaylistTracks(pl.ID, pl.SnapshotID)
+	if ok {
+		cmds = append(cmds, func() tea.Msg {
+			return tracksLoadedMsg{playlistID: pl.ID, tracks: cached}
+		})
+	} else {
+		s.tracks.SetItems(nil)
+		cmds = append(cmds, s.loader.Start())
 	}
+	if !fresh {
+		cmds = append(cmds, func() tea.Msg {
+			tracks, err := s.playlistService.GetPlaylistTracks(pl.ID, pl.SnapshotID)
+			if err != nil {
+				return tracksFailedMsg{playlistID: pl.ID, err: err}
+			}
+			return tracksLoadedMsg{playlistID: pl.ID, tracks: tracks}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+func (s *PlaylistTracks) loadQueue() tea.Cmd {
+	s.tracks.SetItems(nil)
+	return tea.Batch(s.loader.Start(), func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		queueTracks, err := s.playbackService.GetQueue(ctx)
+		if err != nil {
+			return queueFailedMsg{err: err}
+		}
+		return queueLoadedMsg{tracks: queueTracks}
+	})
+}
 
+// maxHistoryItems is how many of the latest plays the history lists.
+const maxHistoryItems = 1000
+
+func (s *PlaylistTracks) loadHistory() tea.Cmd {
+	s.tracks.SetItems(nil)
+	if s.listens == nil {
+		s.loader.Done(nil)
+		return nil
+	}
+	return tea.Batch(s.loader.Start(), func() tea.Msg {
+		entries, err := s.listens.Recent(maxHistoryItems)
+		if err != nil {
+			return historyFailedMsg{err: err}
+		}
+		return historyLoadedMsg{entries: entries}
+	})
+}
+
+// historyItems lists plays newest first, with the play time in the Added
+// column.
+func (s *PlaylistTracks) historyItems(entries []history.Entry) ([]list.Item, []entities.Track) {
+	tracks := make([]entities.Track, len(entries))
+	for i, e := range entries {
+		tracks[i] = e.Track
+	}
+	items := s.trackItems(tracks)
+	for i, e := range entries {
+		item := items[i].(playlistItem)
+		item.addedAt = e.StartedAt.Format(time.RFC3339)
+		item.contextURI = e.ContextURI
+		items[i] = item
+	}
+	return items, tracks
+}
+
+// retry repeats whichever load failed last.
+func (s *PlaylistTracks) retry() tea.Cmd {
+	if s.showingQueue {
+		return s.loadQueue()
+	}
+	if s.showingHistory {
+		return s.loadHistory()
+	}
+	if s.lastPlaylist.ID != "" {
+		return s.loadPlaylist(s.lastPlaylist)
+	}
 	return nil
 }
 
@@ -256,47 +560,200 @@ func (s *PlaylistTracks) Update(msg tea.Msg) (Component, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tracksLoadedMsg:
-		items := make([]list.Item, len(msg.tracks))
-		for i, tr := range msg.tracks {
-			artistNames := make([]string, len(tr.Artists))
-			for j, a := range tr.Artists {
-				artistNames[j] = a.Name
-			}
-			items[i] = playlistItem{name: tr.Name, artists: artistNames, id: tr.ID}
+		// Ignore late results for a playlist that is no longer open.
+		if msg.playlistID != s.lastPlaylist.ID || s.showingQueue || s.showingHistory || s.search.active {
+			return s, nil
+		}
+		s.loader.Done(nil)
+		return s, tea.Batch(s.setItems(s.trackItems(msg.tracks)), s.loadLiked(msg.tracks))
+
+	case tracksFailedMsg:
+		if msg.playlistID != s.lastPlaylist.ID || s.showingQueue || s.showingHistory || s.search.active {
+			return s, nil
+		}
+		// Keep showing cached tracks if the refresh fails.
+		if len(s.tracks.Items()) > 0 {
+			msg.err = nil
+		}
+		s.loader.Done(msg.err)
+		return s, nil
+
+	case queueFailedMsg:
+		if s.showingQueue {
+			s.loader.Done(msg.err)
 		}
-		s.tracks.SetItems(items)
 		return s, nil
 
 	case queueLoadedMsg:
-		items := make([]list.Item, len(msg.tracks))
-		for i, tr := range msg.tracks {
-			artistNames := make([]string, len(tr.Artists))
-			for j, a := range tr.Artists {
-				artistNames[j] = a.Name
-			}
-			items[i] = playlistItem{name: tr.Name, artists: artistNames, id: tr.ID}
+		if !s.showingQueue {
+			return s, nil
+		}
+		s.loader.Done(nil)
+		return s, tea.Batch(s.setItems(s.trackItems(msg.tracks)), s.loadLiked(msg.tracks))
+
+	case historyFailedMsg:
+		if s.showingHistory {
+			s.loader.Done(msg.err)
+		}
+		return s, nil
+
+	case historyLoadedMsg:
+		if !s.showingHistory {
+			return s, nil
+		}
+		s.loader.Done(nil)
+		items, tracks := s.historyItems(msg.entries)
+		return s, tea.Batch(s.setItems(items), s.loadLiked(tracks))
+
+	case likedLoadedMsg:
+		return s, s.applyLiked(msg.liked)
+
+	case bulkActionMsg:
+		if msg.err != nil {
+			return s, s.flash("Failed: " + msg.err.Error())
+		}
+		return s, tea.Batch(s.applyLiked(msg.liked), s.flash(msg.status))
+
+	case tracksRemovedMsg:
+		status := fmt.Sprintf("Removed %d %s", msg.count, plural(msg.count, "track"))
+		if msg.playlistID != s.lastPlaylist.ID {
+			return s, s.flash(status)
+		}
+		// The removal changed the playlist's snapshot, so this refetches.
+		s.lastPlaylist.SnapshotID = msg.snapshotID
+		if cur := s.history.Current(); cur != nil && cur.playlist.ID == msg.playlistID {
+			cur.playlist.SnapshotID = msg.snapshotID
+		}
+		if s.showingQueue || s.showingHistory || s.search.active {
+			return s, s.flash(status)
+		}
+		return s, tea.Batch(s.loadPlaylist(s.lastPlaylist), s.flash(status))
+
+	case pickerPlaylistsMsg:
+		switch {
+		case s.picker == nil:
+			return s, nil
+		case msg.err != nil:
+			// An empty picker has nothing to offer; close it with the reason.
+			s.picker = nil
+			return s, s.flash("Failed: " + msg.err.Error())
+		}
+		return s, s.picker.SetPlaylists(msg.playlists)
+
+	case statusExpiredMsg:
+		if msg.id == s.statusID {
+			s.status = ""
 		}
-		s.tracks.SetItems(items)
 		return s, nil
 
 	case errMsg:
 		return s, nil
 	}
 
+	if cmd := s.loader.Update(msg); cmd != nil {
+		return s, cmd
+	}
+
 	if !s.focused {
 		s.tracks.Select(-1)
 		return s, nil
 	}
 
+	if s.picker != nil {
+		return s, s.updatePicker(msg)
+	}
+	if s.confirm != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			c := s.confirm
+			s.confirm = nil
+			if key.Matches(msg, s.keys.Confirm) {
+				return s, c.run()
+			}
+			return s, s.flash("Cancelled")
+		}
+	}
+
+	// While the filter is being typed every key belongs to it.
+	if s.tracks.SettingFilter() {
+		s.tracks, cmd = s.tracks.Update(msg)
+		return s, cmd
+	}
+
 	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return s, s.handleMouse(msg)
+
 	case tea.KeyMsg:
 		switch {
-		case key.Matches(msg, defaultKeyMap.Tab),
-			key.Matches(msg, defaultKeyMap.ShiftTab):
-			s.tracks.Select(-1)
+		case key.Matches(msg, s.keys.Retry):
+			if s.loader.err != nil {
+				return s, s.retry()
+			}
+
+		case s.sel.visual && key.Matches(msg, s.listKeys.ClearFilter):
+			s.sel.visual = false
+			return s, nil
+
+		case key.Matches(msg, s.keys.ToggleSelect):
+			if item, ok := s.tracks.SelectedItem().(playlistItem); ok {
+				s.sel.Toggle(item)
+				s.tracks.CursorDown()
+			}
+			return s, nil
+
+		case key.Matches(msg, s.keys.Visual):
+			if s.sel.visual {
+				s.sel.EndVisual(s.tracks)
+			} else {
+				s.sel.StartVisual(s.tracks.Index())
+			}
+			return s, nil
+
+		case key.Matches(msg, s.keys.ExtendUp), key.Matches(msg, s.keys.ExtendDown):
+			s.markCursor()
+			if key.Matches(msg, s.keys.ExtendUp) {
+				s.tracks.CursorUp()
+			} else {
+				s.tracks.CursorDown()
+			}
+			s.markCursor()
+			return s, nil
+
+		case key.Matches(msg, s.keys.SelectAll):
+			s.selectAll()
 			return s, nil
 
-		case key.Matches(msg, key.NewBinding(key.WithKeys("left", "h"))):
+		case key.Matches(msg, s.keys.Queue):
+			return s, s.queueTargets()
+
+		case key.Matches(msg, s.keys.AddToPlaylist):
+			return s, s.openPicker()
+
+		case key.Matches(msg, s.keys.Remove):
+			return s, s.confirmRemove()
+
+		case key.Matches(msg, s.keys.Like):
+			return s, s.toggleLike()
+
+		case key.Matches(msg, s.keys.CopyURIs), key.Matches(msg, s.keys.CopyLinks):
+			return s, s.copyTargets(key.Matches(msg, s.keys.CopyLinks))
+
+		case key.Matches(msg, s.keys.ToggleView):
+			s.setTableView(!s.tableView)
+			return s, nil
+
+		case key.Matches(msg, s.keys.Sort), key.Matches(msg, s.keys.SortReverse):
+			if s.search.active || !s.tableView {
+				return s, nil
+			}
+			if key.Matches(msg, s.keys.Sort) {
+				s.table.Next()
+			} else {
+				s.table.Reverse()
+			}
+			return s, s.resort()
+
+		case key.Matches(msg, s.keys.FilterPrev):
 			if s.search.active {
 				if s.search.cursor > 0 {
 					s.search.cursor--
@@ -304,7 +761,7 @@ func (s *PlaylistTracks) Update(msg tea.Msg) (Component, tea.Cmd) {
 				return s, nil
 			}
 
-		case key.Matches(msg, key.NewBinding(key.WithKeys("right", "l"))):
+		case key.Matches(msg, s.keys.FilterNext):
 			if s.search.active {
 				if int(s.search.cursor) < len(filterLabels)-1 {
 					s.search.cursor++
@@ -312,29 +769,24 @@ func (s *PlaylistTracks) Update(msg tea.Msg) (Component, tea.Cmd) {
 				return s, nil
 			}
 
-		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		case key.Matches(msg, s.keys.FilterAll),
+			key.Matches(msg, s.keys.FilterPlaylists),
+			key.Matches(msg, s.keys.FilterAlbums),
+			key.Matches(msg, s.keys.FilterSongs):
+			if s.search.active {
+				s.search.filter = s.filterForKey(msg)
+				s.search.cursor = s.search.filter
+				return s, s.tracks.SetItems(s.search.filterItems())
+			}
+
+		case key.Matches(msg, s.keys.Play):
 			// If cursor is on a different filter, apply it
 			if s.search.active && s.search.cursor != s.search.filter {
 				s.search.filter = s.search.cursor
-				s.tracks.SetItems(s.search.filterItems())
-				return s, nil
+				return s, s.tracks.SetItems(s.search.filterItems())
 			}
 			// Otherwise play the selected track
-			if len(s.tracks.Items()) > 0 {
-				selectedTrack := s.tracks.SelectedItem()
-				if selectedTrack != nil {
-					if item, ok := selectedTrack.(playlistItem); ok {
-						playlistURI := ""
-						if !s.showingQueue && !s.search.active && s.lastPlaylist.ID != "" {
-							playlistURI = s.lastPlaylist.URI
-						}
-						return s, s.bus.Publish(MsgPlayTrack, PlayTrackMsg{
-							TrackURI:    "spotify:track:" + item.id,
-							PlaylistURI: playlistURI,
-						})
-					}
-				}
-			}
+			return s, s.playSelected()
 		}
 	}
 
@@ -342,12 +794,166 @@ func (s *PlaylistTracks) Update(msg tea.Msg) (Component, tea.Cmd) {
 	return s, cmd
 }
 
+// playSelected plays the track under the cursor, within the open playlist
+// when there is one.
+func (s *PlaylistTracks) playSelected() tea.Cmd {
+	item, ok := s.tracks.SelectedItem().(playlistItem)
+	if !ok {
+		return nil
+	}
+	playlistURI := ""
+	switch {
+	case s.showingHistory:
+		// Play it again where it was played, when Spotify can start a
+		// track part way into that context.
+		if strings.HasPrefix(item.contextURI, "spotify:playlist:") || strings.HasPrefix(item.contextURI, "spotify:album:") {
+			playlistURI = item.contextURI
+		}
+	case !s.showingQueue && !s.search.active && s.lastPlaylist.ID != "":
+		playlistURI = s.lastPlaylist.URI
+	}
+	return s.bus.Publish(MsgPlayTrack, PlayTrackMsg{
+		TrackURI:    "spotify:track:" + item.id,
+		PlaylistURI: playlistURI,
+	})
+}
+
+// rowHeight returns the height and spacing of the rows being shown.
+func (s *PlaylistTracks) rowHeight() (int, int) {
+	if s.tableView && !s.search.active {
+		return 1, 0
+	}
+	return 2, 1
+}
+
+// handleMouse scrolls with the wheel, moves the cursor on click, toggles
+// the selection on ctrl+click and plays on double-click. m is relative to
+// the pane.
+func (s *PlaylistTracks) handleMouse(m tea.MouseMsg) tea.Cmd {
+	height, spacing := s.rowHeight()
+	if isWheel(m) {
+		scrollList(&s.tracks, m, height, spacing)
+		return nil
+	}
+	if m.Button != tea.MouseButtonLeft {
+		return nil
+	}
+	index, ok := listRowAt(s.tracks, m.Y-s.rowsTop, height, spacing)
+	if !ok {
+		return nil
+	}
+	s.tracks.Select(index)
+	if m.Ctrl {
+		if item, ok := s.tracks.SelectedItem().(playlistItem); ok {
+			s.sel.Toggle(item)
+		}
+		return nil
+	}
+	if s.clicks.Click(index) {
+		return s.playSelected()
+	}
+	return nil
+}
+
+func (s *PlaylistTracks) filterForKey(msg tea.KeyMsg) searchFilter {
+	switch {
+	case key.Matches(msg, s.keys.FilterPlaylists):
+		return filterPlaylists
+	case key.Matches(msg, s.keys.FilterAlbums):
+		return filterAlbums
+	case key.Matches(msg, s.keys.FilterSongs):
+		return filterSongs
+	}
+	return filterAll
+}
+
+// selectAll selects every visible track, or clears the selection when they
+// all are selected already.
+func (s *PlaylistTracks) selectAll() {
+	visible := s.tracks.VisibleItems()
+	if s.sel.Len(s.tracks) == len(visible) {
+		s.sel.Clear()
+		return
+	}
+	for _, it := range visible {
+		if pi, ok := it.(playlistItem); ok {
+			s.sel.marked[pi.position] = true
+		}
+	}
+}
+
+func (s *PlaylistTracks) setTableView(table bool) {
+	s.tableView = table
+	s.applyDelegate()
+}
+
+func (s *PlaylistTracks) RegisterCommands(r *CommandRegistry) {
+	r.Register(
+		Command{Name: "sort", Group: "tracks", Description: "sort the track table", Args: "<column>",
+			Complete: func() []string { return trackColumnNames[:] },
+			Run: func(args string) (tea.Cmd, error) {
+				name, err := oneOf(args, trackColumnNames[:]...)
+				if err != nil {
+					return nil, err
+				}
+				if !s.tableView {
+					s.setTableView(true)
+				}
+				s.table.sort = trackColumn(slices.Index(trackColumnNames[:], name))
+				s.table.desc = false
+				return s.resort(), nil
+			}},
+		Command{Name: "sort-reverse", Group: "tracks", Description: "reverse the sort order",
+			Run: noArgs(func() tea.Cmd {
+				s.table.Reverse()
+				return s.resort()
+			})},
+		Command{Name: "view", Group: "tracks", Description: "switch the track layout", Args: "<table|compact>",
+			Complete: func() []string { return []string{config.TrackViewTable, config.TrackViewCompact} },
+			Run: func(args string) (tea.Cmd, error) {
+				view, err := oneOf(args, config.TrackViewTable, config.TrackViewCompact)
+				if err != nil {
+					return nil, err
+				}
+				s.setTableView(view == config.TrackViewTable)
+				return s.resort(), nil
+			}},
+		Command{Name: "select-all", Group: "tracks", Description: "select every track",
+			Run: noArgs(func() tea.Cmd {
+				s.selectAll()
+				return nil
+			})},
+		Command{Name: "add-to-queue", Group: "tracks", Description: "queue the selected tracks",
+			Run: noArgs(s.queueTargets)},
+		Command{Name: "add-to-playlist", Group: "tracks", Description: "add the selected tracks to a playlist",
+			Run: noArgs(s.openPicker)},
+		Command{Name: "remove", Group: "tracks", Description: "remove the selected tracks from the playlist",
+			Run: noArgs(s.confirmRemove)},
+		Command{Name: "like", Group: "tracks", Description: "like or unlike the selected tracks",
+			Run: noArgs(s.toggleLike)},
+		Command{Name: "copy-uris", Group: "tracks", Description: "copy the selected tracks' URIs",
+			Run: noArgs(func() tea.Cmd { return s.copyTargets(false) })},
+		Command{Name: "copy-links", Group: "tracks", Description: "copy the selected tracks' links",
+			Run: noArgs(func() tea.Cmd { return s.copyTargets(true) })},
+	)
+}
+
+func (s *PlaylistTracks) KeyBindings() []key.Binding {
+	return append(s.keys.Bindings(), s.listKeys.Bindings()...)
+}
+
+func (s *PlaylistTracks) capturingInput() bool {
+	return s.tracks.SettingFilter() || s.picker != nil || s.confirm != nil
+}
+
 func (s *PlaylistTracks) Blur() {
+	s.saveEntry()
 	s.focused = false
 }
 
 func (s *PlaylistTracks) Focus() {
 	s.focused = true
+	s.restoreCursor()
 }
 
 func (s *PlaylistTracks) Focused() bool {
@@ -355,31 +961,106 @@ func (s *PlaylistTracks) Focused() bool {
 }
 
 func (s *PlaylistTracks) View(width, height int) string {
-	border := borderStyle.Copy().
+	s.tracks.Styles.Title = listTitleStyle()
+	styleFilterInput(&s.tracks)
+	border := borderStyle().
 		Width(width).
 		Height(height)
 
 	if s.Focused() {
-		border = border.BorderForeground(lipgloss.Color("#1db954"))
+		border = border.BorderForeground(theme.Accent)
+	}
+
+	if s.picker != nil {
+		return border.Render(s.picker.View(width, height))
 	}
 
+	s.tracks.Title = s.title()
 	if s.search.active {
 		tabBarHeight := 1
 		s.tracks.SetSize(width, height-tabBarHeight)
-		listView := s.tracks.View()
+		s.rowsTop = 1 + listRowsTop(s.tracks) + tabBarHeight
+		return border.Render(insertLines(s.tracks.View(), 1, s.search.renderFilterTabs()))
+	}
+
+	header := s.headerView(width)
+	listHeight := height - lipgloss.Height(header)
+	if header == "" {
+		listHeight = height
+	}
+	showTable := s.tableView && len(s.tracks.Items()) > 0
+	if showTable {
+		listHeight--
+		s.table.Layout(width - 1)
+	}
+	s.tracks.SetSize(width, listHeight)
+
+	s.rowsTop = 1 + listRowsTop(s.tracks)
+	if header != "" {
+		s.rowsTop += lipgloss.Height(header)
+	}
+	if showTable {
+		s.rowsTop++
+	}
 
-		firstNL := strings.Index(listView, "\n")
-		var inner string
-		if firstNL == -1 {
-			inner = lipgloss.JoinVertical(lipgloss.Left, listView, s.search.renderFilterTabs())
-		} else {
-			titleLine := listView[:firstNL]
-			rest := listView[firstNL+1:]
-			inner = lipgloss.JoinVertical(lipgloss.Left, titleLine, s.search.renderFilterTabs(), rest)
+	body := s.tracks.View()
+	if showTable {
+		// The column header sits between the title bar and the first row.
+		body = insertLines(body, lipgloss.Height(s.tracks.Styles.TitleBar.Render(s.tracks.Title)), s.table.Header())
+	}
+	if len(s.tracks.Items()) == 0 {
+		title := s.tracks.Styles.TitleBar.Render(s.tracks.Styles.Title.Render(s.tracks.Title))
+		state := emptyState(s.emptyText(), width)
+		if s.loader.Active() {
+			state = s.loader.View("tracks", s.keys.Retry, width, listHeight-2, 2)
 		}
-		return border.Render(inner)
+		body = lipgloss.JoinVertical(lipgloss.Left, title, state)
+	}
+
+	if header != "" {
+		return border.Render(lipgloss.JoinVertical(lipgloss.Left, header, body))
+	}
+	return border.Render(body)
+}
+
+// insertLines puts lines into view after its first n lines.
+func insertLines(view string, n int, lines ...string) string {
+	rows := strings.Split(view, "\n")
+	n = min(n, len(rows))
+	return strings.Join(slices.Concat(rows[:n], lines, rows[n:]), "\n")
+}
+
+func (s *PlaylistTracks) emptyText() string {
+	switch {
+	case s.showingQueue:
+		return "The queue is empty."
+	case s.showingHistory && s.listens == nil:
+		return "Listening history is turned off in the config."
+	case s.showingHistory:
+		return "Nothing recorded yet. Tracks show up here as you listen."
+	case s.lastPlaylist.ID == "":
+		return "Pick a playlist from the sidebar, or press / to search."
 	}
+	return "This playlist has no tracks yet."
+}
 
-	s.tracks.SetSize(width, height)
-	return border.Render(s.tracks.View())
-}
\ No newline at end of file
+const headerArtRows = 4
+
+// headerView renders the cover and details of the open playlist above its
+// tracks. It is empty for the queue, the history and search results.
+func (s *PlaylistTracks) headerView(width int) string {
+	pl := s.lastPlaylist
+	if !s.art.Enabled() || s.showingQueue || s.showingHistory || s.search.active || pl.ImageURL == "" {
+		return ""
+	}
+
+	cover := s.art.View(pl.ImageURL, headerArtRows*2, headerArtRows)
+	name := lipgloss.NewStyle().Foreground(theme.Text).Bold(true).Render(pl.Name)
+	details := lipgloss.NewStyle().Foreground(theme.Muted).Render(fmt.Sprintf("%s · %d tracks", pl.Owner, pl.TrackCount))
+	info := lipgloss.NewStyle().
+		PaddingLeft(2).
+		Width(max(width-headerArtRows*2-4, 0)).
+		Render(lipgloss.JoinVertical(lipgloss.Left, "", name, details))
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, "  ", cover, info) + "\n"
+}
@@ -0,0 +1,86 @@
+package view
+
+import "strings"
+
+type contentKind int
+
+const (
+	contentPlaylist contentKind = iota
+	contentQueue
+	contentSearch
+	contentHistory
+)
+
+// contentEntry is one screen of the content pane together with the state
+// needed to bring it back exactly as it was left.
+type contentEntry struct {
+	kind     contentKind
+	label    string
+	playlist PlaylistSelectedMsg
+	search   search
+	cursor   int
+}
+
+// router is the back/forward history of the content pane. Pushing a new
+// entry drops anything ahead of the current one, like a browser.
+type router struct {
+	entries []contentEntry
+	index   int
+}
+
+const maxHistory = 50
+
+func newRouter() router {
+	return router{index: -1}
+}
+
+func (r *router) Push(e contentEntry) {
+	r.entries = append(r.entries[:r.index+1], e)
+	if len(r.entries) > maxHistory {
+		r.entries = r.entries[len(r.entries)-maxHistory:]
+	}
+	r.index = len(r.entries) - 1
+}
+
+// Current returns the entry on screen, or nil before anything was opened.
+func (r *router) Current() *contentEntry {
+	if r.index < 0 {
+		return nil
+	}
+	return &r.entries[r.index]
+}
+
+func (r *router) Back() (contentEntry, bool) {
+	if r.index <= 0 {
+		return contentEntry{}, false
+	}
+	r.index--
+	return r.entries[r.index], true
+}
+
+func (r *router) Forward() (contentEntry, bool) {
+	if r.index >= len(r.entries)-1 {
+		return contentEntry{}, false
+	}
+	r.index++
+	return r.entries[r.index], true
+}
+
+// Breadcrumb joins the labels leading up to the current entry, keeping only
+// the last few so it fits in the pane title.
+func (r *router) Breadcrumb() string {
+	const maxCrumbs = 3
+	if r.index < 0 {
+		return ""
+	}
+
+	start := max(r.index-maxCrumbs+1, 0)
+	labels := make([]string, 0, maxCrumbs+1)
+	if start > 0 {
+		labels = append(labels, "…")
+	}
+	for _, e := range r.entries[start : r.index+1] {
+		labels = append(labels, e.label)
+	}
+	return strings.Join(labels, " › ")
+}
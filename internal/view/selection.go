@@ -0,0 +1,76 @@
+package view
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// selection is the set of marked rows in a track list. Rows are keyed by
+// their position in the playlist so duplicate tracks stay distinct, and in
+// visual mode everything between the anchor and the cursor is selected too.
+type selection struct {
+	marked map[int]bool
+	visual bool
+	anchor int // list index where visual mode started
+}
+
+func newSelection() *selection {
+	return &selection{marked: map[int]bool{}}
+}
+
+func (s *selection) Toggle(i playlistItem) {
+	if s.marked[i.position] {
+		delete(s.marked, i.position)
+		return
+	}
+	s.marked[i.position] = true
+}
+
+// StartVisual begins a range at the cursor.
+func (s *selection) StartVisual(cursor int) {
+	s.visual = true
+	s.anchor = cursor
+}
+
+// EndVisual leaves visual mode, keeping the range selected.
+func (s *selection) EndVisual(m list.Model) {
+	for _, it := range s.Items(m) {
+		s.marked[it.position] = true
+	}
+	s.visual = false
+}
+
+// Clear drops every mark and leaves visual mode.
+func (s *selection) Clear() {
+	s.marked = map[int]bool{}
+	s.visual = false
+}
+
+// Has reports whether the row at index of m is selected.
+func (s *selection) Has(m list.Model, index int, i playlistItem) bool {
+	if s.marked[i.position] {
+		return true
+	}
+	if !s.visual {
+		return false
+	}
+	lo, hi := min(s.anchor, m.Index()), max(s.anchor, m.Index())
+	return index >= lo && index <= hi
+}
+
+// Items returns the selected rows in list order.
+func (s *selection) Items(m list.Model) []playlistItem {
+	var out []playlistItem
+	for i, it := range m.VisibleItems() {
+		if pi, ok := it.(playlistItem); ok && s.Has(m, i, pi) {
+			out = append(out, pi)
+		}
+	}
+	return out
+}
+
+func (s *selection) Len(m list.Model) int {
+	if !s.visual {
+		return len(s.marked)
+	}
+	return len(s.Items(m))
+}
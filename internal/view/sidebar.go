@@ -2,38 +2,40 @@
 package view
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/thomassbooth/spotify-tui/internal/config"
+	"github.com/thomassbooth/spotify-tui/internal/entities"
+	"github.com/thomassbooth/spotify-tui/internal/playlistfile"
 	"github.com/thomassbooth/spotify-tui/internal/service"
 )
 
-var defaultKeyMap = struct {
-	Tab      key.Binding
-	ShiftTab key.Binding
-}{
-	Tab:      key.NewBinding(key.WithKeys("tab")),
-	ShiftTab: key.NewBinding(key.WithKeys("shift+tab")),
-}
-
 // ---------------------------------------------------------------------
 // 1. Item type with Name and OwnerName
 // ---------------------------------------------------------------------
 type sidebarItem struct {
-	name      string
-	ownerName string
-	plType    string
-	id        string
-	uri       string
+	name        string
+	description string
+	ownerName   string
+	plType      string
+	id          string
+	uri         string
+	imageURL    string
+	trackCount  int
+	snapshotID  string
 }
 
 func (i sidebarItem) Title() string       { return i.name }
 func (i sidebarItem) Description() string { return i.ownerName }
-func (i sidebarItem) FilterValue() string { return i.name }
+func (i sidebarItem) FilterValue() string { return filterValue(i.name, i.ownerName) }
 
 // ---------------------------------------------------------------------
 // 2. Custom Delegate to render name and owner
@@ -53,39 +55,24 @@ func (d sidebarDelegate) Render(w io.Writer, m list.Model, index int, item list.
 	}
 
 	var (
-		title       = i.name
-		owner       = i.ownerName
-		plType      = i.plType
+		matches     = fieldMatches(m, index, i.name, i.ownerName)
 		isSelected  = index == m.Index()
 		s           = lipgloss.NewStyle().Padding(0, 0, 0, 2)
 		selectedStr = " "
+		titleStyle  = lipgloss.NewStyle().Foreground(theme.Text)
+		detailStyle = lipgloss.NewStyle().Foreground(theme.Border)
 	)
 
 	if isSelected {
 		selectedStr = ">"
-		title = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#1db954")).
-			Bold(true).
-			Render(title)
-		owner = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#1db954")).
-			Render(owner)
-		plType = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#1db954")).
-			Render(plType)
-	} else {
-		title = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Render(title)
-		owner = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262")).
-			Render(owner)
-		plType = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262")).
-			Render(plType)
-
-	}
-	fmt.Fprintf(w, s.Render(selectedStr+" "+title+"\n  "+plType+" - "+owner))
+		titleStyle = lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+		detailStyle = lipgloss.NewStyle().Foreground(theme.Accent)
+	}
+
+	title := highlight(i.name, matches[0], titleStyle)
+	owner := highlight(i.ownerName, matches[1], detailStyle)
+	plType := detailStyle.Render(i.plType)
+	fmt.Fprint(w, s.Render(selectedStr+" "+title+"\n  "+plType+" - "+owner))
 }
 
 // ---------------------------------------------------------------------
@@ -95,31 +82,79 @@ type Sidebar struct {
 	list            list.Model
 	focused         bool
 	bus             *MessageBus
-	playlistService *service.PlaylistService
+	playlistService service.Playlists
+	listKeys        listKeyMap
+	keys            sidebarKeyMap
+	loader          loader
+	rowsTop         int // line of the first row, for mouse clicks
+	clicks          clickTracker
+	export          config.ExportConfig
 }
 
-// NewSidebar creates a ready-to-use sidebar
-func NewSidebar(bus *MessageBus, playlistService *service.PlaylistService) *Sidebar {
+// NewSidebar creates a ready-to-use sidebar. It never touches the network;
+// playlists are fetched by the command returned from Init.
+func NewSidebar(bus *MessageBus, playlistService service.Playlists, listKeys listKeyMap, keys sidebarKeyMap, export config.ExportConfig) *Sidebar {
 	const width = 22
-	playlists, _ := playlistService.GetPlaylists()
-	lists := make([]list.Item, len(playlists))
-	for i, p := range playlists {
-		lists[i] = sidebarItem{
-			name:      p.Name,
-			ownerName: p.OwnerName,
-			plType:    p.Type,
-			id:        p.ID,
-			uri: 	 p.URI,
-		}
-	}
+
+	// Serve the cached playlists straight away; Init revalidates them.
+	playlists, _ := playlistService.CachedPlaylists()
 
 	delegate := sidebarDelegate{list.NewDefaultDelegate()}
-	l := list.New(lists, delegate, width, 0)
+	l := list.New(sidebarItems(playlists), delegate, width, 0)
 	l.Title = "Playlists"
 	l.SetShowHelp(false)
 	l.SetShowStatusBar(false)
+	l.KeyMap = listKeys.listModelKeyMap()
+
+	return &Sidebar{
+		list:            l,
+		focused:         false,
+		bus:             bus,
+		playlistService: playlistService,
+		listKeys:        listKeys,
+		keys:            keys,
+		loader:          newLoader(),
+		export:          export,
+	}
+}
+
+func sidebarItems(playlists []entities.Playlist) []list.Item {
+	items := make([]list.Item, len(playlists))
+	for i, p := range playlists {
+		items[i] = sidebarItem{
+			name:        p.Name,
+			description: p.Description,
+			ownerName:   p.OwnerName,
+			plType:      p.Type,
+			id:          p.ID,
+			uri:         p.URI,
+			imageURL:    p.ImageURL,
+			trackCount:  p.TrackCount,
+			snapshotID:  p.SnapshotID,
+		}
+	}
+	return items
+}
 
-	return &Sidebar{list: l, focused: false, bus: bus, playlistService: playlistService}
+// Init fetches the playlists in the background.
+func (s *Sidebar) Init() tea.Cmd {
+	return s.fetch()
+}
+
+func (s *Sidebar) fetch() tea.Cmd {
+	fetch := func() tea.Msg {
+		playlists, err := s.playlistService.GetPlaylists()
+		if err != nil {
+			return playlistsFailedMsg{err: err}
+		}
+		return playlistsLoadedMsg{playlists: playlists}
+	}
+
+	// With cached playlists on screen the refresh happens silently.
+	if len(s.list.Items()) > 0 {
+		return fetch
+	}
+	return tea.Batch(s.loader.Start(), fetch)
 }
 
 func (s *Sidebar) Deselect() {
@@ -129,30 +164,63 @@ func (s *Sidebar) Deselect() {
 func (s *Sidebar) Update(msg tea.Msg) (Component, tea.Cmd) {
 	var cmd tea.Cmd
 
+	switch m := msg.(type) {
+	case playlistsLoadedMsg:
+		s.loader.Done(nil)
+		return s, s.list.SetItems(sidebarItems(m.playlists))
+
+	case importAddedMsg:
+		// An import may have created a playlist or grown one.
+		if m.added > 0 {
+			return s, s.fetch()
+		}
+		return s, nil
+
+	case playlistsFailedMsg:
+		// A failed refresh behind cached data is not worth interrupting for.
+		if len(s.list.Items()) > 0 {
+			s.loader.Done(nil)
+			return s, nil
+		}
+		s.loader.Done(m.err)
+		return s, nil
+	}
+
+	if cmd := s.loader.Update(msg); cmd != nil {
+		return s, cmd
+	}
+
 	if !s.focused {
 		s.list.Select(-1)
 		return s, cmd
 	}
 
+	// While the filter is being typed every key belongs to it.
+	if s.capturingInput() {
+		s.list, cmd = s.list.Update(msg)
+		return s, cmd
+	}
+
 	switch m := msg.(type) {
+	case tea.MouseMsg:
+		return s, s.handleMouse(m)
+
 	case tea.KeyMsg:
 		switch {
-		case key.Matches(m, defaultKeyMap.Tab),
-			key.Matches(m, defaultKeyMap.ShiftTab):
-			s.list.Select(-1)
+		case key.Matches(m, s.keys.Retry):
+			if s.loader.err != nil {
+				return s, s.fetch()
+			}
+
+		case key.Matches(m, s.keys.Select):
+			if item, ok := s.list.SelectedItem().(sidebarItem); ok && item.id != "" {
+				return s, s.open(item)
+			}
 			return s, nil
 
-		case key.Matches(m, key.NewBinding(key.WithKeys("enter"))):
-			if sel := s.list.SelectedItem(); sel != nil {
-				if item, ok := sel.(sidebarItem); ok && item.id != "" {
-					// Publish using your MessageBus API: (type, payload)
-					cmd := s.bus.Publish(MsgPlaylistSelected, PlaylistSelectedMsg{
-						ID:   item.id,
-						Name: item.name,
-						URI:  item.uri,
-					})
-					return s, cmd
-				}
+		case key.Matches(m, s.keys.Export):
+			if item, ok := s.list.SelectedItem().(sidebarItem); ok && item.id != "" {
+				return s, s.exportPlaylist(item)
 			}
 			return s, nil
 		}
@@ -163,9 +231,133 @@ func (s *Sidebar) Update(msg tea.Msg) (Component, tea.Cmd) {
 	return s, cmd
 }
 
-var borderStyle = lipgloss.NewStyle().
-	BorderStyle(lipgloss.NormalBorder()).
-	BorderForeground(lipgloss.Color("#626262"))
+// handleMouse scrolls with the wheel, selects a playlist on click and
+// opens it on double-click. m is relative to the sidebar.
+func (s *Sidebar) handleMouse(m tea.MouseMsg) tea.Cmd {
+	if isWheel(m) {
+		scrollList(&s.list, m, 2, 1)
+		return nil
+	}
+	if m.Button != tea.MouseButtonLeft {
+		return nil
+	}
+	index, ok := listRowAt(s.list, m.Y-s.rowsTop, 2, 1)
+	if !ok {
+		return nil
+	}
+	s.list.Select(index)
+	if item, ok := s.list.SelectedItem().(sidebarItem); ok && s.clicks.Click(index) && item.id != "" {
+		return s.open(item)
+	}
+	return nil
+}
+
+func (s *Sidebar) open(item sidebarItem) tea.Cmd {
+	return s.bus.Publish(MsgPlaylistSelected, PlaylistSelectedMsg{
+		ID:         item.id,
+		Name:       item.name,
+		URI:        item.uri,
+		ImageURL:   item.imageURL,
+		Owner:      item.ownerName,
+		TrackCount: item.trackCount,
+		SnapshotID: item.snapshotID,
+	})
+}
+
+// exportPlaylist writes every track of item to a file named after it in
+// the export directory, and reports where in the track list's title bar.
+func (s *Sidebar) exportPlaylist(item sidebarItem) tea.Cmd {
+	format, _ := playlistfile.ParseFormat(s.export.Format)
+	path := filepath.Join(s.export.Dir, playlistfile.Filename(item.name, format))
+	playlist := entities.Playlist{
+		ID:          item.id,
+		Name:        item.name,
+		Description: item.description,
+		OwnerName:   item.ownerName,
+		TrackCount:  item.trackCount,
+		URI:         item.uri,
+		SnapshotID:  item.snapshotID,
+	}
+	return func() tea.Msg {
+		if err := exportPlaylist(s.playlistService, playlist, format, path); err != nil {
+			return bulkActionMsg{err: err}
+		}
+		return bulkActionMsg{status: "Exported " + item.name + " to " + path}
+	}
+}
+
+func exportPlaylist(playlists service.Playlists, p entities.Playlist, format playlistfile.Format, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = playlists.ExportPlaylist(p, format, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Leave no half-written file behind.
+		os.Remove(path)
+	}
+	return err
+}
+
+func (s *Sidebar) RegisterCommands(r *CommandRegistry) {
+	names := func() []string {
+		var names []string
+		for _, it := range s.list.Items() {
+			if item, ok := it.(sidebarItem); ok {
+				names = append(names, item.name)
+			}
+		}
+		return names
+	}
+	r.Register(
+		Command{Name: "open", Group: "playlists", Description: "open a playlist", Args: "<playlist>",
+			Complete: names,
+			Run: func(args string) (tea.Cmd, error) {
+				name, err := bestMatch(args, names())
+				if err != nil {
+					return nil, err
+				}
+				for _, it := range s.list.Items() {
+					if item, ok := it.(sidebarItem); ok && item.name == name && item.id != "" {
+						return s.open(item), nil
+					}
+				}
+				return nil, nil
+			}},
+		Command{Name: "export-playlist", Group: "playlists", Description: "save a playlist to the export directory, the selected one by default", Args: "[playlist]",
+			Complete: names,
+			Run: func(args string) (tea.Cmd, error) {
+				if args == "" {
+					if item, ok := s.list.SelectedItem().(sidebarItem); ok && item.id != "" {
+						return s.exportPlaylist(item), nil
+					}
+					return nil, errors.New("select a playlist or give its name")
+				}
+				name, err := bestMatch(args, names())
+				if err != nil {
+					return nil, err
+				}
+				for _, it := range s.list.Items() {
+					if item, ok := it.(sidebarItem); ok && item.name == name && item.id != "" {
+						return s.exportPlaylist(item), nil
+					}
+				}
+				return nil, nil
+			}},
+		Command{Name: "refresh-playlists", Group: "playlists", Description: "fetch playlists again",
+			Run: noArgs(s.fetch)},
+	)
+}
+
+func (s *Sidebar) capturingInput() bool {
+	return s.list.SettingFilter()
+}
 
 func (s *Sidebar) Blur() {
 	s.focused = false
@@ -179,15 +371,31 @@ func (s *Sidebar) Focused() bool {
 	return s.focused
 }
 
+func (s *Sidebar) KeyBindings() []key.Binding {
+	return append(s.keys.Bindings(), s.listKeys.Bindings()...)
+}
+
 func (s *Sidebar) View(width, height int) string {
 	s.list.SetSize(width, height)
-	border := borderStyle.Copy().
+	s.list.Styles.Title = listTitleStyle()
+	styleFilterInput(&s.list)
+	border := borderStyle().
 		Width(width).
 		Height(height)
 
 	if s.Focused() {
-		border = border.BorderForeground(lipgloss.Color("#1db954"))
+		border = border.BorderForeground(theme.Accent)
+	}
+
+	if len(s.list.Items()) == 0 {
+		title := s.list.Styles.TitleBar.Render(s.list.Styles.Title.Render(s.list.Title))
+		body := emptyState("No playlists yet. Create one in Spotify and it will show up here.", width)
+		if s.loader.Active() {
+			body = s.loader.View("playlists", s.keys.Retry, width, height-2, 2)
+		}
+		return border.Render(lipgloss.JoinVertical(lipgloss.Left, title, body))
 	}
 
+	s.rowsTop = 1 + listRowsTop(s.list)
 	return border.Render(s.list.View())
 }
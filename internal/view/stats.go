@@ -0,0 +1,328 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/thomassbooth/spotify-tui/internal/history"
+	"github.com/thomassbooth/spotify-tui/internal/service"
+	"github.com/thomassbooth/spotify-tui/internal/stats"
+)
+
+const (
+	// statsColumnWidth is the width each column of sections is laid out
+	// in; the heatmap needs 52 cells.
+	statsColumnWidth = 56
+	// statsTop is how many entries each ranking lists.
+	statsTop = 10
+	// statsChartHeight is the rows of the per-day chart, its axis aside.
+	statsChartHeight = 6
+)
+
+type statsLoadedMsg struct {
+	rng    stats.Range
+	report *stats.Report
+	err    error // from Spotify; the report still holds the local history
+}
+
+// StatsView is the full-screen listening dashboard: the local history over
+// the selected range next to Spotify's top artists and tracks for it. Each
+// range is loaded the first time it is shown.
+type StatsView struct {
+	playlists service.Playlists
+	listens   *history.Store
+	list      listKeyMap
+	tracks    tracksKeyMap
+	open      bool
+
+	rng     stats.Range
+	reports map[stats.Range]*stats.Report
+	errs    map[stats.Range]error
+	loading map[stats.Range]bool
+	offset  int    // rows scrolled down
+	notice  string // outcome of the last export
+}
+
+func NewStatsView(playlists service.Playlists, listens *history.Store, list listKeyMap, tracks tracksKeyMap) *StatsView {
+	return &StatsView{
+		playlists: playlists,
+		listens:   listens,
+		list:      list,
+		tracks:    tracks,
+		rng:       stats.ShortTerm,
+		reports:   map[stats.Range]*stats.Report{},
+		errs:      map[stats.Range]error{},
+		loading:   map[stats.Range]bool{},
+	}
+}
+
+// Open switches to the dashboard, loading the selected range if it has
+// not been yet.
+func (s *StatsView) Open() tea.Cmd {
+	s.open, s.notice = true, ""
+	return s.load(false)
+}
+
+func (s *StatsView) Close() {
+	s.open = false
+}
+
+func (s *StatsView) Opened() bool {
+	return s.open
+}
+
+// load sums up the history over the selected range and asks Spotify for
+// its side, unless that is already there or on its way.
+func (s *StatsView) load(force bool) tea.Cmd {
+	rng := s.rng
+	if s.loading[rng] || (s.reports[rng] != nil && !force) {
+		return nil
+	}
+	s.loading[rng] = true
+	return func() tea.Msg {
+		var entries []history.Entry
+		if s.listens != nil {
+			var err error
+			if entries, err = s.listens.Load(); err != nil {
+				return statsLoadedMsg{rng: rng, report: stats.Compute(nil, rng.Days(), time.Now()), err: err}
+			}
+		}
+		report := stats.Compute(entries, rng.Days(), time.Now())
+		err := report.FetchSpotify(s.playlists, rng)
+		return statsLoadedMsg{rng: rng, report: report, err: err}
+	}
+}
+
+// Update stores loaded reports.
+func (s *StatsView) Update(msg tea.Msg) tea.Cmd {
+	if m, ok := msg.(statsLoadedMsg); ok {
+		s.reports[m.rng], s.errs[m.rng], s.loading[m.rng] = m.report, m.err, false
+	}
+	return nil
+}
+
+// HandleKey switches range, scrolls and reloads.
+func (s *StatsView) HandleKey(m tea.KeyMsg) tea.Cmd {
+	i := slices.Index(stats.Ranges, s.rng)
+	switch {
+	case key.Matches(m, s.tracks.FilterPrev):
+		s.rng, s.offset = stats.Ranges[(i+len(stats.Ranges)-1)%len(stats.Ranges)], 0
+		return s.load(false)
+	case key.Matches(m, s.tracks.FilterNext):
+		s.rng, s.offset = stats.Ranges[(i+1)%len(stats.Ranges)], 0
+		return s.load(false)
+	case key.Matches(m, s.tracks.Retry):
+		return s.load(true)
+	case key.Matches(m, s.list.Up):
+		s.offset = max(s.offset-1, 0)
+	case key.Matches(m, s.list.Down):
+		s.offset++
+	case key.Matches(m, s.list.PageUp):
+		s.offset = max(s.offset-10, 0)
+	case key.Matches(m, s.list.PageDown):
+		s.offset += 10
+	case key.Matches(m, s.list.Top):
+		s.offset = 0
+	}
+	return nil
+}
+
+// Export writes the report on screen to path, as CSV when it ends in .csv
+// and JSON otherwise.
+func (s *StatsView) Export(path string) error {
+	report := s.reports[s.rng]
+	if report == nil {
+		return errors.New("open the stats first")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = report.WriteCSV(f)
+	} else {
+		err = report.WriteJSON(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	s.notice = "Saved to " + path
+	return nil
+}
+
+// FullView renders the dashboard: a header with the range tabs and totals,
+// then the sections in as many columns as fit, scrolled by offset.
+func (s *StatsView) FullView(width, height int, closeHint string) string {
+	const margin = 2
+	inner := max(width-2*margin, 0)
+	accent := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	muted := lipgloss.NewStyle().Foreground(theme.Muted)
+
+	var tabs []string
+	for _, rng := range stats.Ranges {
+		label := " " + rng.Label() + " "
+		if rng == s.rng {
+			tabs = append(tabs, listTitleStyle().Render(label))
+		} else {
+			tabs = append(tabs, muted.Render(label))
+		}
+	}
+	header := []string{
+		accent.Render("Listening stats") + "  " + strings.Join(tabs, " "),
+	}
+
+	hint := fmt.Sprintf("%s/%s range · %s reload · %s or esc to close",
+		s.tracks.FilterPrev.Help().Key, s.tracks.FilterNext.Help().Key, s.tracks.Retry.Help().Key, closeHint)
+	if s.notice != "" {
+		hint = s.notice + " · " + hint
+	}
+	footer := muted.Render(ansi.Truncate(hint, inner, "…"))
+
+	report := s.reports[s.rng]
+	var body []string
+	switch {
+	case report == nil && s.loading[s.rng]:
+		body = []string{muted.Render("Loading…")}
+	case report == nil:
+		body = []string{muted.Render("Nothing loaded")}
+	default:
+		header = append(header, s.summary(report))
+		if err := s.errs[s.rng]; err != nil {
+			header = append(header, lipgloss.NewStyle().Foreground(theme.Subtext).Render(
+				ansi.Truncate("Spotify: "+err.Error(), inner, "…")))
+		}
+		body = s.sections(report, inner)
+	}
+
+	bodyHeight := max(height-len(header)-3, 0)
+	s.offset = clamp(s.offset, 0, max(len(body)-bodyHeight, 0))
+	body = body[s.offset:min(s.offset+bodyHeight, len(body))]
+
+	view := lipgloss.JoinVertical(lipgloss.Left,
+		strings.Join(header, "\n"),
+		"",
+		lipgloss.NewStyle().Height(bodyHeight).Render(strings.Join(body, "\n")),
+		"",
+		footer,
+	)
+	return lipgloss.NewStyle().Padding(0, margin).MaxHeight(height).Render(view)
+}
+
+func (s *StatsView) summary(r *stats.Report) string {
+	text := lipgloss.NewStyle().Foreground(theme.Text)
+	if s.listens == nil {
+		return text.Render("The listening history is turned off; only Spotify's top items are shown")
+	}
+	return text.Render(fmt.Sprintf("%s over %d plays since %s · %.0f%% skipped",
+		stats.Duration(r.ListenedMs), r.Plays, r.From.Format("2 Jan 2006"), r.SkipRate*100))
+}
+
+// sections renders every section and lays them out in columns, each going
+// into the shortest column so far.
+func (s *StatsView) sections(r *stats.Report, width int) []string {
+	colWidth := min(statsColumnWidth, width)
+	var blocks [][]string
+	if s.listens != nil && r.Plays > 0 {
+		blocks = append(blocks,
+			s.block("Per day", stats.Columns(r.Days, colWidth, statsChartHeight, func(t time.Time) string { return t.Format("2 Jan") }), true),
+			s.block("By hour", stats.Heatmap(r.Hours), true),
+			s.block("Per week", s.weeks(r.Weeks, colWidth), false),
+			s.block("Top artists", s.counts(r.TopArtists, colWidth), false),
+			s.block("Top albums", s.counts(r.TopAlbums, colWidth), false),
+		)
+		if len(r.TopGenres) > 0 {
+			blocks = append(blocks, s.block("Top genres", s.counts(r.TopGenres, colWidth), false))
+		}
+	}
+	if top := r.Spotify[s.rng]; top != nil {
+		blocks = append(blocks,
+			s.block("Spotify's top artists", s.ranks(top.Artists, colWidth), false),
+			s.block("Spotify's top tracks", s.ranks(top.Tracks, colWidth), false),
+		)
+	}
+
+	columns := max(width/(colWidth+2), 1)
+	cols := make([][]string, columns)
+	for _, b := range blocks {
+		shortest := 0
+		for i := range cols {
+			if len(cols[i]) < len(cols[shortest]) {
+				shortest = i
+			}
+		}
+		cols[shortest] = append(cols[shortest], b...)
+	}
+	rendered := make([]string, 0, columns)
+	for _, col := range cols {
+		if len(col) > 0 {
+			rendered = append(rendered, lipgloss.NewStyle().Width(colWidth).MarginRight(2).Render(strings.Join(col, "\n")))
+		}
+	}
+	return strings.Split(lipgloss.JoinHorizontal(lipgloss.Top, rendered...), "\n")
+}
+
+// block is a titled section followed by a blank line. Charts are drawn in
+// the accent colour.
+func (s *StatsView) block(title string, lines []string, chart bool) []string {
+	out := []string{lipgloss.NewStyle().Foreground(theme.Accent).Bold(true).Render(title)}
+	style := lipgloss.NewStyle().Foreground(theme.Text)
+	if chart {
+		style = lipgloss.NewStyle().Foreground(theme.Accent)
+	}
+	for _, line := range lines {
+		out = append(out, style.Render(line))
+	}
+	return append(out, "")
+}
+
+func (s *StatsView) weeks(weeks []stats.Period, width int) []string {
+	var out []string
+	for _, w := range weeks[max(len(weeks)-8, 0):] {
+		out = append(out, ansi.Truncate(fmt.Sprintf("%-8s %8s  %d plays", w.Start.Format("2 Jan"), stats.Duration(w.ListenedMs), w.Plays), width, "…"))
+	}
+	return out
+}
+
+func (s *StatsView) counts(counts []stats.Count, width int) []string {
+	var out []string
+	for i, c := range counts[:min(len(counts), statsTop)] {
+		name := c.Name
+		if c.By != "" {
+			name += " · " + c.By
+		}
+		right := fmt.Sprintf(" %7s", stats.Duration(c.ListenedMs))
+		left := ansi.Truncate(fmt.Sprintf("%2d. %s", i+1, name), max(width-len(right), 0), "…")
+		out = append(out, left+strings.Repeat(" ", max(width-ansi.StringWidth(left)-len(right), 0))+right)
+	}
+	return out
+}
+
+func (s *StatsView) ranks(items []stats.TopItem, width int) []string {
+	var out []string
+	for _, it := range items[:min(len(items), statsTop)] {
+		name := it.Name
+		switch {
+		case it.By != "":
+			name += " · " + it.By
+		case len(it.Genres) > 0:
+			name += " · " + it.Genres[0]
+		}
+		out = append(out, ansi.Truncate(fmt.Sprintf("%2d. %s", it.Rank, name), width, "…"))
+	}
+	if len(out) == 0 {
+		out = append(out, "Nothing yet")
+	}
+	return out
+}
@@ -1,20 +1,26 @@
 // view/styles.go
 package view
 
-import "github.com/charmbracelet/lipgloss"
-
-var (
-	BorderStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderForeground(lipgloss.Color("#626262")).
-			Padding(0, 1)
+import (
+	"github.com/charmbracelet/lipgloss"
+	themes "github.com/thomassbooth/spotify-tui/internal/theme"
+)
 
-	NavBarStyle = lipgloss.NewStyle().
-			Padding(0, 20)
+// theme is the palette every component renders with. Components read it at
+// render time, so assigning a new theme restyles the whole UI on the next
+// frame.
+var theme = themes.Builtin()[0]
 
-	SelectedNavStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#1db954"))
+func borderStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(theme.Border)
+}
 
-	ItemNavStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#b3b3b3"))
-)
+func listTitleStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Background(theme.Accent).
+		Foreground(theme.Text).
+		Bold(true).
+		Padding(0, 1)
+}
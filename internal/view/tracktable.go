@@ -0,0 +1,303 @@
+package view
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+type trackColumn int
+
+const (
+	colIndex trackColumn = iota
+	colTitle
+	colArtist
+	colAlbum
+	colAdded
+	colDuration
+	colPopularity
+	colLiked
+	numTrackColumns
+)
+
+var trackColumnLabels = [numTrackColumns]string{"#", "Title", "Artist", "Album", "Added", "Time", "Pop", "♥"}
+
+// trackColumnNames name the columns in the sort command.
+var trackColumnNames = [numTrackColumns]string{"position", "title", "artist", "album", "added", "time", "popularity", "liked"}
+
+// Fixed widths of the narrow columns; title, artist and album share what is
+// left in the ratio of flexShares.
+var (
+	fixedColumnWidths = map[trackColumn]int{colIndex: 4, colAdded: 10, colDuration: 5, colPopularity: 3, colLiked: 1}
+	flexShares        = map[trackColumn]int{colTitle: 4, colArtist: 3, colAlbum: 3}
+)
+
+// dropOrder lists the columns given up, in turn, when the pane is too narrow
+// to fit them all.
+var dropOrder = []trackColumn{colPopularity, colAdded, colAlbum, colIndex, colLiked}
+
+const (
+	minFlexWidth = 10
+	columnGap    = 2
+	rowPadding   = 4 // left padding plus the selection marker
+)
+
+// trackTable lays out and sorts the table view of the track list. The pane
+// and its delegate share one by pointer, so a resize or sort change shows up
+// on the next render.
+type trackTable struct {
+	widths [numTrackColumns]int // 0 hides a column
+	sort   trackColumn
+	desc   bool
+	played bool // the Added column holds play times, for the history
+}
+
+// Layout fits the columns into width, dropping the least useful ones first.
+func (t *trackTable) Layout(width int) {
+	visible := map[trackColumn]bool{}
+	for c := range numTrackColumns {
+		visible[c] = true
+	}
+
+	available := func() int {
+		n, fixed := 0, 0
+		for c := range numTrackColumns {
+			if visible[c] {
+				n++
+				fixed += fixedColumnWidths[c]
+			}
+		}
+		return width - rowPadding - fixed - columnGap*(n-1)
+	}
+	flexMin := func() int {
+		n := 0
+		for c := range flexShares {
+			if visible[c] {
+				n++
+			}
+		}
+		return n * minFlexWidth
+	}
+
+	for _, c := range dropOrder {
+		if available() >= flexMin() {
+			break
+		}
+		visible[c] = false
+	}
+
+	space := max(available(), 0)
+	shares := 0
+	for c, share := range flexShares {
+		if visible[c] {
+			shares += share
+		}
+	}
+
+	t.widths = [numTrackColumns]int{}
+	used := 0
+	for c := range numTrackColumns {
+		switch {
+		case !visible[c]:
+		case flexShares[c] > 0:
+			t.widths[c] = space * flexShares[c] / shares
+			used += t.widths[c]
+		default:
+			t.widths[c] = fixedColumnWidths[c]
+		}
+	}
+	// Rounding leftovers go to the title.
+	t.widths[colTitle] += space - used
+}
+
+// Header renders the column labels, marking the sort column.
+func (t *trackTable) Header() string {
+	cells := make([]string, 0, numTrackColumns)
+	for c := range numTrackColumns {
+		if t.widths[c] == 0 {
+			continue
+		}
+		label := trackColumnLabels[c]
+		if c == colAdded && t.played {
+			label = "Played"
+		}
+		style := lipgloss.NewStyle().Foreground(theme.Subtext).Bold(true)
+		if c == t.sort && c != colIndex {
+			label += map[bool]string{false: " ▲", true: " ▼"}[t.desc]
+			style = style.Foreground(theme.Accent)
+		}
+		cells = append(cells, t.cell(c, label, nil, style))
+	}
+	return strings.Repeat(" ", rowPadding) + strings.Join(cells, strings.Repeat(" ", columnGap))
+}
+
+// Row renders one track. matches are the filter matches of the title,
+// artist and album fields, and mark is the selection column.
+func (t *trackTable) Row(i playlistItem, matches [][]int, selected bool, mark string) string {
+	text := lipgloss.NewStyle().Foreground(theme.Text)
+	detail := lipgloss.NewStyle().Foreground(theme.Muted)
+	cursor := "  "
+	if selected {
+		text = lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+		detail = lipgloss.NewStyle().Foreground(theme.Accent)
+		cursor = "> "
+	}
+
+	values := [numTrackColumns]string{
+		colIndex:      fmt.Sprint(i.position + 1),
+		colTitle:      i.name,
+		colArtist:     i.artistNames(),
+		colAlbum:      i.album,
+		colAdded:      addedDate(i.addedAt),
+		colDuration:   formatDuration(i.durationMs),
+		colPopularity: fmt.Sprint(i.popularity),
+	}
+	if i.liked {
+		values[colLiked] = "♥"
+	}
+	if t.played {
+		values[colAdded] = playedTime(i.addedAt)
+	}
+
+	cells := make([]string, 0, numTrackColumns)
+	for c := range numTrackColumns {
+		if t.widths[c] == 0 {
+			continue
+		}
+		style, m := detail, []int(nil)
+		switch c {
+		case colTitle:
+			style, m = text, matches[0]
+		case colArtist:
+			m = matches[1]
+		case colAlbum:
+			m = matches[2]
+		case colLiked:
+			style = lipgloss.NewStyle().Foreground(theme.Accent)
+		}
+		cells = append(cells, t.cell(c, values[c], m, style))
+	}
+	return " " + mark + cursor + strings.Join(cells, strings.Repeat(" ", columnGap))
+}
+
+// cell truncates value to the column width and pads it, right-aligning the
+// numeric columns.
+func (t *trackTable) cell(c trackColumn, value string, matches []int, style lipgloss.Style) string {
+	w := t.widths[c]
+	value = ansi.Truncate(value, w, "…")
+	pad := strings.Repeat(" ", max(w-ansi.StringWidth(value), 0))
+
+	rendered := highlight(value, matches, style)
+	switch c {
+	case colIndex, colDuration, colPopularity:
+		return pad + rendered
+	}
+	return rendered + pad
+}
+
+// Next moves the sort to the following column, wrapping back to playlist
+// order.
+func (t *trackTable) Next() {
+	t.sort = (t.sort + 1) % numTrackColumns
+	t.desc = false
+}
+
+func (t *trackTable) Reverse() {
+	t.desc = !t.desc
+}
+
+// Sort orders track items by the current column. Ties keep playlist order,
+// so sorting by # restores it.
+func (t *trackTable) Sort(items []list.Item) []list.Item {
+	out := slices.Clone(items)
+	slices.SortStableFunc(out, func(a, b list.Item) int {
+		x, _ := a.(playlistItem)
+		y, _ := b.(playlistItem)
+		c := compareItems(t.sort, x, y)
+		if t.desc {
+			c = -c
+		}
+		return cmp.Or(c, cmp.Compare(x.position, y.position))
+	})
+	return out
+}
+
+func compareItems(c trackColumn, x, y playlistItem) int {
+	switch c {
+	case colTitle:
+		return strings.Compare(strings.ToLower(x.name), strings.ToLower(y.name))
+	case colArtist:
+		return strings.Compare(strings.ToLower(x.artistNames()), strings.ToLower(y.artistNames()))
+	case colAlbum:
+		return strings.Compare(strings.ToLower(x.album), strings.ToLower(y.album))
+	case colAdded:
+		return strings.Compare(x.addedAt, y.addedAt)
+	case colDuration:
+		return cmp.Compare(x.durationMs, y.durationMs)
+	case colPopularity:
+		return cmp.Compare(x.popularity, y.popularity)
+	case colLiked:
+		return compareBool(x.liked, y.liked)
+	}
+	return cmp.Compare(x.position, y.position)
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+func addedDate(addedAt string) string {
+	t, err := time.Parse(time.RFC3339, addedAt)
+	if err != nil {
+		return ""
+	}
+	return t.Local().Format(time.DateOnly)
+}
+
+// playedTime shows the time of a play today, and the date of older ones.
+func playedTime(playedAt string) string {
+	t, err := time.Parse(time.RFC3339, playedAt)
+	if err != nil {
+		return ""
+	}
+	t = t.Local()
+	if t.Format(time.DateOnly) == time.Now().Format(time.DateOnly) {
+		return t.Format("15:04")
+	}
+	return t.Format(time.DateOnly)
+}
+
+// --- tableDelegate ---
+
+// tableDelegate renders track items as single table rows.
+type tableDelegate struct {
+	table *trackTable
+	sel   *selection
+}
+
+func (d tableDelegate) Height() int                         { return 1 }
+func (d tableDelegate) Spacing() int                        { return 0 }
+func (d tableDelegate) Update(tea.Msg, *list.Model) tea.Cmd { return nil }
+
+func (d tableDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(playlistItem)
+	if !ok {
+		return
+	}
+	matches := fieldMatches(m, index, i.name, i.artistNames(), i.album)
+	fmt.Fprint(w, d.table.Row(i, matches, index == m.Index(), selectionMark(d.sel, m, index, i)))
+}